@@ -0,0 +1,167 @@
+package errgen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sources holds source buffers that were handed to the compiler from memory, keyed by file path.
+// sourcesMutex guards it, as in-memory sources can be compiled from several goroutines at once.
+var sources = make(map[string][]byte)
+var sourcesMutex sync.RWMutex
+
+// collectors holds the collector of every goroutine that is running Collect, by goroutine id. Problems reported
+// on any other goroutine are printed as usual, even while a Collect is running elsewhere.
+var collectors = make(map[uint64]*collector)
+var collectorsMutex sync.Mutex
+
+// collector gathers the problems reported during one call of Collect
+type collector struct {
+	problems []*Problem
+}
+
+// halt is the panic value used to stop the current phase when a critical or syntax error is reported while collecting
+type halt struct {
+	level PROBLEM_TYPE
+}
+
+// Diagnostic is a reported problem as seen by callers of Collect.
+type Diagnostic struct {
+	FilePath  string
+	LineStart int
+	LineEnd   int
+	ColStart  int
+	ColEnd    int
+	Message   string
+	Hints     []string
+	Level     PROBLEM_TYPE
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.FilePath, d.LineStart, d.ColStart, d.Level, d.Message)
+}
+
+// Render formats a diagnostic the way the compiler prints a problem, without colors: the line it starts on with
+// its columns marked, the message, where it is and its hints. The line is read from the source registered for
+// the diagnostic's file path, or from disk when there is none, so a buffer must stay registered until it is rendered.
+func Render(d Diagnostic) (string, error) {
+	lineNumber, line, underLine, err := snippet(d.FilePath, d.LineStart, d.LineEnd, d.ColStart, d.ColEnd)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(lineNumber + line + "\n")
+	b.WriteString(underLine)
+	fmt.Fprintf(&b, "%s: %s\n", d.Level, d.Message)
+	fmt.Fprintf(&b, "at: %s:%d:%d\n", d.FilePath, d.LineStart, d.ColStart)
+	if len(d.Hints) > 0 {
+		b.WriteString("Hint:\n")
+		for _, hint := range d.Hints {
+			fmt.Fprintf(&b, "- %s\n", hint)
+		}
+	}
+	return b.String(), nil
+}
+
+// RegisterSource makes the source of filePath available from memory.
+// Problems reported against filePath are then rendered from this buffer instead of reading the file from disk.
+func RegisterSource(filePath string, src []byte) {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+	sources[filePath] = src
+}
+
+// UnregisterSource forgets the buffer registered for filePath, once its problems no longer need to be rendered
+func UnregisterSource(filePath string) {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+	delete(sources, filePath)
+}
+
+func readSource(filePath string) ([]byte, error) {
+	sourcesMutex.RLock()
+	src, ok := sources[filePath]
+	sourcesMutex.RUnlock()
+	if ok {
+		return src, nil
+	}
+	return os.ReadFile(filePath)
+}
+
+// Collect runs fn and returns every problem it reported, without printing them or exiting the process.
+// A critical or syntax error stops fn at the point it is reported, the same way it stops the compiler.
+// Problems reported before Collect was called, or by other goroutines, are left untouched.
+func Collect(fn func()) []Diagnostic {
+
+	id := goroutineID()
+	c := &collector{}
+
+	collectorsMutex.Lock()
+	outer := collectors[id]
+	collectors[id] = c
+	collectorsMutex.Unlock()
+
+	defer func() {
+		collectorsMutex.Lock()
+		defer collectorsMutex.Unlock()
+		if outer != nil {
+			collectors[id] = outer
+		} else {
+			delete(collectors, id)
+		}
+	}()
+
+	run(fn)
+
+	diagnostics := make([]Diagnostic, 0, len(c.problems))
+	for _, problem := range c.problems {
+		diagnostics = append(diagnostics, Diagnostic{
+			FilePath:  problem.filePath,
+			LineStart: problem.lineStart,
+			LineEnd:   problem.lineEnd,
+			ColStart:  problem.colStart,
+			ColEnd:    problem.colEnd,
+			Message:   problem.err.Error(),
+			Hints:     problem.hints,
+			Level:     problem.level,
+		})
+	}
+
+	return diagnostics
+}
+
+// currentCollector returns the collector of the Collect running on this goroutine, or nil outside of Collect
+func currentCollector() *collector {
+	id := goroutineID()
+	collectorsMutex.Lock()
+	defer collectorsMutex.Unlock()
+	return collectors[id]
+}
+
+// goroutineID returns the id of the calling goroutine, read from the first line of its stack: "goroutine 12 [running]:"
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	buf = buf[:bytes.IndexByte(buf, ' ')]
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// run calls fn and recovers from a halt. Any other panic is passed on.
+func run(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(halt); !ok {
+				panic(r)
+			}
+		}
+	}()
+	fn()
+}
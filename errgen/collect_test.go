@@ -0,0 +1,129 @@
+package errgen
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	reached := false
+
+	diagnostics := Collect(func() {
+		Add("memory.wal", 1, 1, 2, 4, "a warning").Hint("a hint").Level(WARNING)
+		Add("memory.wal", 2, 2, 1, 3, "a syntax error").Level(SYNTAX_ERROR)
+		reached = true
+	})
+
+	if reached {
+		t.Errorf("expected a syntax error to stop the collected function")
+	}
+
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(diagnostics))
+	}
+
+	if diagnostics[0].Level != WARNING || diagnostics[0].Message != "a warning" || len(diagnostics[0].Hints) != 1 {
+		t.Errorf("unexpected first diagnostic %+v", diagnostics[0])
+	}
+
+	if diagnostics[1].Level != SYNTAX_ERROR || diagnostics[1].LineStart != 2 || diagnostics[1].ColEnd != 3 {
+		t.Errorf("unexpected second diagnostic %+v", diagnostics[1])
+	}
+
+	if len(collectors) != 0 || len(globalProblems) != 0 {
+		t.Errorf("expected Collect to leave the global problems untouched")
+	}
+}
+
+func TestNestedCollect(t *testing.T) {
+	var inner []Diagnostic
+
+	outer := Collect(func() {
+		Add("memory.wal", 1, 1, 1, 2, "outer").Level(WARNING)
+		inner = Collect(func() {
+			Add("memory.wal", 2, 2, 1, 2, "inner").Level(CRITICAL_ERROR)
+		})
+		Add("memory.wal", 3, 3, 1, 2, "after").Level(WARNING)
+	})
+
+	if len(inner) != 1 || inner[0].Message != "inner" {
+		t.Errorf("expected the inner problem only, got %v", inner)
+	}
+	if len(outer) != 2 || outer[1].Message != "after" {
+		t.Errorf("expected the outer problems to go on after the inner Collect, got %v", outer)
+	}
+}
+
+func TestCollectIsPerGoroutine(t *testing.T) {
+	started, reported := make(chan bool), make(chan bool)
+
+	go Collect(func() {
+		started <- true
+		<-reported
+	})
+	<-started
+
+	// a warning reported outside of Collect is kept with the global problems, even while another goroutine collects
+	Add("memory.wal", 1, 1, 1, 2, "global").Level(WARNING)
+	reported <- true
+
+	if len(globalProblems) != 1 {
+		t.Errorf("expected the warning to be a global problem, got %d", len(globalProblems))
+	}
+	globalProblems, problems = nil, make(map[PROBLEM_TYPE]int)
+}
+
+func TestRenderRegisteredSource(t *testing.T) {
+	RegisterSource("render.wal", []byte("let a := 1;\nlet b = 2;"))
+	defer UnregisterSource("render.wal")
+
+	rendered, err := Render(Diagnostic{FilePath: "render.wal", LineStart: 2, LineEnd: 2, ColStart: 7, ColEnd: 8, Message: "expected ':='", Hints: []string{"use :="}, Level: SYNTAX_ERROR})
+	if err != nil {
+		t.Fatalf("expected the registered buffer to be rendered, got %v", err)
+	}
+
+	expected := "2 | let b = 2;\n          ^\nsyntax error: expected ':='\nat: render.wal:2:7\nHint:\n- use :=\n"
+	if rendered != expected {
+		t.Errorf("expected %q, got %q", expected, rendered)
+	}
+}
+
+func TestReadRegisteredSource(t *testing.T) {
+	RegisterSource("buffer.wal", []byte("let a := 1;"))
+
+	src, err := readSource("buffer.wal")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if string(src) != "let a := 1;" {
+		t.Errorf("expected the registered buffer, got %q", src)
+	}
+
+	UnregisterSource("buffer.wal")
+	if _, err := readSource("buffer.wal"); err == nil {
+		t.Errorf("expected the buffer to be forgotten")
+	}
+}
+
+func TestConcurrentSources(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("buffer%d.wal", i)
+			RegisterSource(name, []byte("let a := 1;"))
+			if _, err := readSource(name); err != nil {
+				t.Errorf("expected the registered buffer of %s, got %v", name, err)
+			}
+			UnregisterSource(name)
+		}(i)
+	}
+	wg.Wait()
+
+	if len(sources) != 0 {
+		t.Errorf("expected every buffer to be forgotten, got %d", len(sources))
+	}
+}
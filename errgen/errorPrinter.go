@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"walrus/utils"
 )
 
@@ -19,9 +20,10 @@ const (
 	WARNING PROBLEM_TYPE = "warning" // Indicates potential issues
 )
 
-// global errors are arrays of error pointers
+// global errors are arrays of error pointers. Problems reported inside Collect are kept by its collector instead.
 var globalProblems []*Problem
 var problems = make(map[PROBLEM_TYPE]int)
+var problemsMutex sync.Mutex
 
 type Problem struct {
 	filePath  string
@@ -44,7 +46,7 @@ type Problem struct {
 //   - showFileName: Boolean flag to control whether the file name is displayed
 //
 // The function:
-//   - Reads the source file
+//   - Reads the source file, or the registered source buffer if there is one
 //   - Displays file location (if showFileName is true)
 //   - Shows the problematic line of code
 //   - Highlights the error position with ^ and ~ characters
@@ -54,28 +56,13 @@ type Problem struct {
 //
 // If file reading fails, the function will panic.
 func printProblem(e *Problem) {
-	fileData, err := os.ReadFile(e.filePath)
+	lineNumber, line, underLine, err := snippet(e.filePath, e.lineStart, e.lineEnd, e.colStart, e.colEnd)
 	if err != nil {
 		panic(err)
 	}
 
-	lines := strings.Split(string(fileData), "\n")
-	line := lines[e.lineStart-1]
-	hLen := 0
-	if e.lineStart == e.lineEnd {
-		hLen = (e.colEnd - e.colStart) - 1
-	} else {
-		//full line
-		hLen = len(line) - 2
-	}
-	if hLen < 0 {
-		hLen = 0
-	}
-
-	lineNumber := fmt.Sprintf("%d | ", e.lineStart)
 	utils.GREY.Print(lineNumber)
 	fmt.Println(line)
-	underLine := fmt.Sprintf("%s^%s\n", strings.Repeat(" ", (e.colStart-1)+len(lineNumber)), strings.Repeat("~", hLen))
 
 	markUnderline(e, underLine)
 
@@ -95,6 +82,36 @@ func printProblem(e *Problem) {
 	}
 }
 
+// snippet reads the line a problem starts on, from the registered source of the file or from disk.
+// It returns the line number prefix, the line, and the underline that marks the problem's columns below it.
+func snippet(filePath string, lineStart, lineEnd, colStart, colEnd int) (string, string, string, error) {
+	fileData, err := readSource(filePath)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	lines := strings.Split(string(fileData), "\n")
+	line := ""
+	if lineStart <= len(lines) {
+		line = lines[lineStart-1]
+	}
+	hLen := 0
+	if lineStart == lineEnd {
+		hLen = (colEnd - colStart) - 1
+	} else {
+		//full line
+		hLen = len(line) - 2
+	}
+	if hLen < 0 {
+		hLen = 0
+	}
+
+	lineNumber := fmt.Sprintf("%d | ", lineStart)
+	underLine := fmt.Sprintf("%s^%s\n", strings.Repeat(" ", (colStart-1)+len(lineNumber)), strings.Repeat("~", hLen))
+
+	return lineNumber, line, underLine, nil
+}
+
 func markUnderline(e *Problem, underLine string) {
 	if e.level == WARNING {
 		utils.YELLOW.Print(underLine)
@@ -157,7 +174,14 @@ func Add(filePath string, lineStart, lineEnd int, colStart, colEnd int, errMsg s
 		level:     NULL,
 	}
 
+	if c := currentCollector(); c != nil {
+		c.problems = append(c.problems, err)
+		return err
+	}
+
+	problemsMutex.Lock()
 	globalProblems = append(globalProblems, err)
+	problemsMutex.Unlock()
 
 	return err
 }
@@ -167,7 +191,16 @@ func (e *Problem) Level(level PROBLEM_TYPE) {
 		panic("call ErrorLevel() method with valid Error level")
 	}
 	e.level = level
+	if currentCollector() != nil {
+		if level == CRITICAL_ERROR || level == SYNTAX_ERROR {
+			// unwind to Collect instead of printing and exiting
+			panic(halt{level: level})
+		}
+		return
+	}
+	problemsMutex.Lock()
 	problems[level]++
+	problemsMutex.Unlock()
 	if level == CRITICAL_ERROR || level == SYNTAX_ERROR {
 		DisplayAll()
 	}
@@ -23,6 +23,8 @@ type IntegerLiteralExpr struct {
 	Value    string
 	BitSize  uint8
 	IsSigned bool
+	// IsUntyped is true when the literal has no type suffix. BitSize and IsSigned then describe i32, its default type
+	IsUntyped bool
 	Location
 }
 
@@ -39,6 +41,8 @@ func (a IntegerLiteralExpr) EndPos() lexer.Position {
 type FloatLiteralExpr struct {
 	Value   string
 	BitSize uint8
+	// IsUntyped is true when the literal has no type suffix. BitSize then describes f32, its default type
+	IsUntyped bool
 	Location
 }
 
@@ -67,6 +71,24 @@ func (a StringLiteralExpr) EndPos() lexer.Position {
 	return a.Location.End
 }
 
+// InterpolatedStringExpr is a string literal with embedded expressions like "Hello {name}".
+// Parts holds the text between the embedded expressions as StringLiteralExpr nodes, in source order
+// with the embedded expressions. Empty text is left out.
+type InterpolatedStringExpr struct {
+	Parts []Node
+	Location
+}
+
+func (a InterpolatedStringExpr) INode() {
+	//empty method implements Node interface
+}
+func (a InterpolatedStringExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a InterpolatedStringExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
 type ByteLiteralExpr struct {
 	Value string
 	Location
@@ -173,6 +195,61 @@ type BinaryExpr struct {
 	Location
 }
 
+// LogicalExpr is a && b or a || b. Unlike a BinaryExpr, the right side is only evaluated
+// when the left side does not already decide the result.
+type LogicalExpr struct {
+	Operator lexer.Token
+	Left     Node
+	Right    Node
+	Location
+}
+
+func (a LogicalExpr) INode() {
+	//empty method implements Node interface
+}
+func (a LogicalExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a LogicalExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// RangeExpr is start..end or start..=end, optionally followed by step <expr>.
+// Step is nil when no step is written.
+type RangeExpr struct {
+	Start     Node
+	End       Node
+	Inclusive bool
+	Step      Node
+	Location
+}
+
+func (a RangeExpr) INode() {
+	//empty method implements Node interface
+}
+func (a RangeExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a RangeExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// TupleLiteral is a fixed list of values of any types, like (7, "seven"). It is also the value of ret a, b;
+type TupleLiteral struct {
+	Values []Node
+	Location
+}
+
+func (a TupleLiteral) INode() {
+	//empty method implements Node interface
+}
+func (a TupleLiteral) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a TupleLiteral) EndPos() lexer.Position {
+	return a.Location.End
+}
+
 type IncrementalInterface interface {
 	Arg() IdentifierExpr
 	Op() lexer.Token
@@ -287,7 +364,9 @@ type StructProp struct {
 }
 
 type StructLiteral struct {
+	Package    *IdentifierExpr // the imported package the struct is declared in, like ds in @ds.Stack{...}, or nil
 	Identifier IdentifierExpr
+	TypeArgs   []DataType // explicit type arguments of a generic struct, like @Pair<i32, str>{...}
 	Properties []StructProp
 	Location
 }
@@ -335,6 +414,7 @@ func (a FunctionCallExpr) EndPos() lexer.Position {
 }
 
 type FunctionLiteral struct {
+	TypeParams []TypeParam
 	Params     []FunctionParam
 	Body       BlockStmt
 	ReturnType DataType
@@ -351,4 +431,96 @@ func (a FunctionLiteral) StartPos() lexer.Position {
 
 func (a FunctionLiteral) EndPos() lexer.Position {
 	return a.Location.End
-}
\ No newline at end of file
+}
+
+// MatchCase is one 'case <pattern> if <guard> => <value>' arm of a match expression.
+// Patterns are written as expressions and read by the typechecker. Guard is nil when the arm has none.
+type MatchCase struct {
+	Pattern Node
+	Guard   Node
+	Value   Node
+	Location
+}
+
+// MatchExpr is a match expression. Its value is the value of the first arm whose pattern and guard match the subject.
+type MatchExpr struct {
+	Subject Node
+	Cases   []MatchCase
+	Location
+}
+
+func (a MatchExpr) INode() {
+	//empty method implements Node interface
+}
+func (a MatchExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a MatchExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// TryExpr is value? on a result. It gives the success value, or returns the error from the enclosing function.
+type TryExpr struct {
+	Argument Node
+	Location
+}
+
+func (a TryExpr) INode() {
+	//empty method implements Node interface
+}
+func (a TryExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a TryExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// NullCoalescingExpr is a ?? b. Its value is the value of the maybe a, or b when a is null.
+type NullCoalescingExpr struct {
+	Left  Node
+	Right Node
+	Location
+}
+
+func (a NullCoalescingExpr) INode() {
+	//empty method implements Node interface
+}
+func (a NullCoalescingExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a NullCoalescingExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// OptionalPropertyAccessExpr is obj?.property. It reads the property of a maybe obj, and is null when obj is null.
+type OptionalPropertyAccessExpr struct {
+	Object   Node
+	Property IdentifierExpr
+	Location
+}
+
+func (a OptionalPropertyAccessExpr) INode() {
+	//empty method implements Node interface
+}
+func (a OptionalPropertyAccessExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a OptionalPropertyAccessExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// UnwrapExpr is a! on a maybe value. It gives the value, and is checked at runtime to not be null.
+type UnwrapExpr struct {
+	Argument Node
+	Location
+}
+
+func (a UnwrapExpr) INode() {
+	//empty method implements Node interface
+}
+func (a UnwrapExpr) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a UnwrapExpr) EndPos() lexer.Position {
+	return a.Location.End
+}
@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"strings"
+	"walrus/frontend/builtins"
 	"walrus/frontend/lexer"
 )
 
@@ -21,14 +23,24 @@ func (a ProgramStmt) EndPos() lexer.Position {
 
 type VarDeclStmtVar struct {
 	Identifier   IdentifierExpr
+	Pattern      *DestructuringPattern // set instead of Identifier when the value is taken apart, like let (q, r) := ...
 	Value        Node
 	ExplicitType DataType
 	Location
 }
 
+// DestructuringPattern lists the variables a declaration takes out of a tuple (a, b), a struct {name, age} or an array [first, second].
+// Kind is the type of value the pattern takes apart, and a name '_' skips its element.
+type DestructuringPattern struct {
+	Kind  builtins.PARSER_TYPE
+	Names []IdentifierExpr
+	Location
+}
+
 type VarDeclStmt struct {
 	Variables []VarDeclStmtVar
 	IsConst   bool
+	IsPublic  bool // declared with pub, so other packages can use the variables
 	Location
 }
 
@@ -45,6 +57,8 @@ func (a VarDeclStmt) EndPos() lexer.Position {
 type TypeDeclStmt struct {
 	UDTypeValue DataType
 	UDTypeName  IdentifierExpr
+	TypeParams  []TypeParam
+	IsPublic    bool // declared with pub, so other packages can use the type
 	Location
 }
 
@@ -91,6 +105,7 @@ func (a IfStmt) EndPos() lexer.Position {
 }
 
 type ForStmt struct {
+	Label     string // empty when the loop has no label
 	Init      Node
 	Condition Node
 	Increment Node
@@ -109,8 +124,9 @@ func (a ForStmt) EndPos() lexer.Position {
 }
 
 type ForEachStmt struct {
-	Key      Node
-	Value    Node
+	Label    string          // empty when the loop has no label
+	Key      *IdentifierExpr // nil when only the value is bound, like foreach v in arr
+	Value    IdentifierExpr
 	Iterable Node
 	Block    BlockStmt
 	Location
@@ -150,6 +166,7 @@ func (a FunctionParam) EndPos() lexer.Position {
 
 type FunctionDeclStmt struct {
 	Identifier IdentifierExpr
+	IsPublic   bool // declared with pub, so other packages can call the function or method
 	FunctionLiteral
 }
 
@@ -205,10 +222,18 @@ func (a ImplStmt) EndPos() lexer.Position {
 	return a.Location.End
 }
 
+// SafeBinding is one maybe value a safe statement checks: a declared variable, safe a, or a new name bound to the
+// value of an expression, safe v := lookup(key)
+type SafeBinding struct {
+	Identifier IdentifierExpr
+	Value      Node // nil when the binding is a declared variable
+	Location
+}
+
 type SafeStmt struct {
-	Value       IdentifierExpr
+	Bindings    []SafeBinding
 	SafeBlock   BlockStmt
-	UnsafeBlock BlockStmt
+	UnsafeBlock *BlockStmt // nil when there is no otherwise block
 	Location
 }
 
@@ -223,3 +248,118 @@ func (a SafeStmt) StartPos() lexer.Position {
 func (a SafeStmt) EndPos() lexer.Position {
 	return a.Location.End
 }
+
+// SwitchCase is one 'case a, b: { ... }' arm of a switch statement
+type SwitchCase struct {
+	Values []Node
+	Block  BlockStmt
+	Location
+}
+
+type SwitchStmt struct {
+	Discriminant Node
+	Cases        []SwitchCase
+	Default      *BlockStmt // nil when the switch has no default arm
+	Location
+}
+
+func (a SwitchStmt) INode() {
+	//empty method implements Node interface
+}
+
+func (a SwitchStmt) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a SwitchStmt) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// BreakStmt leaves the innermost loop, or the enclosing loop named by Label
+type BreakStmt struct {
+	Label *IdentifierExpr // nil when no label is given
+	Location
+}
+
+func (a BreakStmt) INode() {
+	//empty method implements Node interface
+}
+
+func (a BreakStmt) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a BreakStmt) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// ContinueStmt skips to the next iteration of the innermost loop, or of the enclosing loop named by Label
+type ContinueStmt struct {
+	Label *IdentifierExpr // nil when no label is given
+	Location
+}
+
+func (a ContinueStmt) INode() {
+	//empty method implements Node interface
+}
+
+func (a ContinueStmt) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a ContinueStmt) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// PackageStmt names the package a file belongs to, like package main;
+type PackageStmt struct {
+	Name IdentifierExpr
+	Location
+}
+
+func (a PackageStmt) INode() {
+	//empty method implements Node interface
+}
+
+func (a PackageStmt) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a PackageStmt) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// ImportSpec is one imported package. Path is the dotted path of the package's folder, like "lib.helpers.ds".
+// Alias is the name the package is used by in the file, or nil when it is the last segment of the path.
+type ImportSpec struct {
+	Path  StringLiteralExpr
+	Alias *IdentifierExpr
+	Location
+}
+
+// Name returns the name the imported package is used by, like ds for "lib.helpers.ds"
+func (a ImportSpec) Name() string {
+	if a.Alias != nil {
+		return a.Alias.Name
+	}
+	segments := strings.Split(a.Path.Value, ".")
+	return segments[len(segments)-1]
+}
+
+// ImportStmt imports one package, import "sys";, or a list of them, import { "sys", "lib.helpers.ds" as ds };
+type ImportStmt struct {
+	Imports []ImportSpec
+	Location
+}
+
+func (a ImportStmt) INode() {
+	//empty method implements Node interface
+}
+
+func (a ImportStmt) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a ImportStmt) EndPos() lexer.Position {
+	return a.Location.End
+}
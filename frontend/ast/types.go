@@ -123,7 +123,8 @@ func (a ArrayType) EndPos() lexer.Position {
 type StructPropType struct {
 	Prop      IdentifierExpr
 	PropType  DataType
-	IsPrivate bool
+	IsPrivate bool // only the methods of the struct can use the property
+	IsPublic  bool // other packages can use the property
 }
 
 type StructType struct {
@@ -142,6 +143,35 @@ func (a StructType) EndPos() lexer.Position {
 	return a.Location.End
 }
 
+// EnumVariantField is a named field of an enum variant's payload, like radius in Circle(radius: f32)
+type EnumVariantField struct {
+	Field     IdentifierExpr
+	FieldType DataType
+}
+
+// EnumVariant is one variant of an enum. Fields is empty for a variant without a payload.
+type EnumVariant struct {
+	Identifier IdentifierExpr
+	Fields     []EnumVariantField
+	Location
+}
+
+type EnumType struct {
+	TypeName builtins.PARSER_TYPE
+	Variants []EnumVariant
+	Location
+}
+
+func (a EnumType) Type() builtins.PARSER_TYPE {
+	return a.TypeName
+}
+func (a EnumType) StartPos() lexer.Position {
+	return a.Location.Start
+}
+func (a EnumType) EndPos() lexer.Position {
+	return a.Location.End
+}
+
 type InterfaceMethod struct {
 	Identifier IdentifierExpr
 	FunctionType
@@ -207,6 +237,62 @@ func (a MaybeType) EndPos() lexer.Position {
 	return a.Location.End
 }
 
+type RangeType struct {
+	TypeName    builtins.PARSER_TYPE
+	ElementType DataType
+	Location
+}
+
+func (a RangeType) Type() builtins.PARSER_TYPE {
+	return a.TypeName
+}
+
+func (a RangeType) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a RangeType) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+// ResultType is result{T, E}, written T!E for short: either a value of OkType or an error of ErrType
+type ResultType struct {
+	TypeName builtins.PARSER_TYPE
+	OkType   DataType
+	ErrType  DataType
+	Location
+}
+
+func (a ResultType) Type() builtins.PARSER_TYPE {
+	return a.TypeName
+}
+
+func (a ResultType) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a ResultType) EndPos() lexer.Position {
+	return a.Location.End
+}
+
+type TupleType struct {
+	TypeName     builtins.PARSER_TYPE
+	ElementTypes []DataType
+	Location
+}
+
+func (a TupleType) Type() builtins.PARSER_TYPE {
+	return a.TypeName
+}
+
+func (a TupleType) StartPos() lexer.Position {
+	return a.Location.Start
+}
+
+func (a TupleType) EndPos() lexer.Position {
+	return a.Location.End
+}
+
 type MapType struct {
 	TypeName  builtins.PARSER_TYPE
 	Map       IdentifierExpr
@@ -229,7 +315,9 @@ func (a MapType) EndPos() lexer.Position {
 
 type UserDefinedType struct {
 	TypeName  builtins.PARSER_TYPE
+	Package   string // the imported package the type is declared in, like ds in ds.Stack, or empty
 	AliasName string
+	TypeArgs  []DataType // type arguments of a generic type, like Pair<i32, str>
 	Location
 }
 
@@ -242,3 +330,11 @@ func (a UserDefinedType) StartPos() lexer.Position {
 func (a UserDefinedType) EndPos() lexer.Position {
 	return a.Location.End
 }
+
+// TypeParam is a type parameter of a generic function or type, like T in fn first<T>(xs: []T).
+// Constraint is the interface a type argument must satisfy, or nil.
+type TypeParam struct {
+	Identifier IdentifierExpr
+	Constraint DataType
+	Location
+}
@@ -24,10 +24,14 @@ const (
 	FUNCTION  = "fn"
 	STRUCT    = "struct"
 	INTERFACE = "interface"
+	ENUM      = "enum"
 	OPTIONAL  = "optional"
 	MAYBE	  = "maybe"
 	ARRAY     = "array"
 	MAP       = "map"
+	RANGE     = "range"
+	RESULT    = "result"
+	TUPLE     = "tuple"
 	VOID      = "void"
 	USER_DEFINED = "user_defined"
 )
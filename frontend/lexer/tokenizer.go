@@ -3,32 +3,45 @@ package lexer
 import (
 	"fmt"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"walrus/errgen"
 	"walrus/frontend/builtins"
 	"walrus/utils"
 )
 
-type regexHandler func(lex *Lexer, regex *regexp.Regexp)
-
-type regexPattern struct {
-	regex   *regexp.Regexp
-	handler regexHandler
-}
-
 type Lexer struct {
 	Errors     []error
 	Tokens     []Token
 	Position   Position
 	sourceCode []byte
-	patterns   []regexPattern
 	FilePath   string
+	// interpolations holds the string interpolations the lexer is currently inside, innermost last
+	interpolations []interpolation
+}
+
+// interpolation tracks an embedded expression of an interpolated string.
+// depth counts the curly braces opened inside the expression, so the '}' that closes the expression can be told apart.
+type interpolation struct {
+	start Position
+	depth int
 }
 
-func (lex *Lexer) advance(match string) {
-	lex.Position.Advance(match)
+// advance moves the lexer forward by n bytes, keeping the line and column of the position in sync.
+// Columns are counted per character, so continuation bytes of a multi-byte UTF-8 sequence do not move the column.
+func (lex *Lexer) advance(n int) {
+	for i := 0; i < n && !lex.atEOF(); i++ {
+		char := lex.at()
+		if char == '\n' {
+			lex.Position.Line++
+			lex.Position.Column = 1
+		} else if char&0xC0 != 0x80 {
+			lex.Position.Column++
+		}
+		lex.Position.Index++
+	}
 }
 
 func (lex *Lexer) push(token Token) {
@@ -39,14 +52,23 @@ func (lex *Lexer) at() byte {
 	return lex.sourceCode[lex.Position.Index]
 }
 
-func (lex *Lexer) remainder() string {
-	return string(lex.sourceCode)[lex.Position.Index:]
+// peek returns the byte n positions ahead of the current one, or 0 if that is past the end of the source.
+func (lex *Lexer) peek(n int) byte {
+	if lex.Position.Index+n >= len(lex.sourceCode) {
+		return 0
+	}
+	return lex.sourceCode[lex.Position.Index+n]
 }
 
 func (lex *Lexer) atEOF() bool {
 	return lex.Position.Index >= len(lex.sourceCode)
 }
 
+// slice returns the source text between the start position and the current position.
+func (lex *Lexer) slice(start Position) string {
+	return string(lex.sourceCode[start.Index:lex.Position.Index])
+}
+
 func createLexer(filePath *string) *Lexer {
 
 	fileText, err := os.ReadFile(*filePath)
@@ -56,212 +78,645 @@ func createLexer(filePath *string) *Lexer {
 		os.Exit(-1)
 	}
 
+	return newLexer(*filePath, fileText)
+}
+
+func newLexer(filePath string, src []byte) *Lexer {
 	lex := &Lexer{
-		sourceCode: fileText,
-		Tokens:     make([]Token, 0),
+		sourceCode: src,
+		FilePath:   filePath,
+		// a token is a few bytes long on average, so this avoids most reallocations while lexing
+		Tokens: make([]Token, 0, len(src)/4),
 		Position: Position{
 			Line:   1,
 			Column: 1,
 			Index:  0,
 		},
-
-		patterns: []regexPattern{
-			//{regexp.MustCompile(`\n`), skipHandler}, // newlines
-			{regexp.MustCompile(`\s+`), skipHandler},                          // whitespace
-			{regexp.MustCompile(`\/\/.*`), skipHandler},                       // single line comments
-			{regexp.MustCompile(`\/\*[\s\S]*?\*\/`), skipHandler},             // multi line comments
-			{regexp.MustCompile(`"[^"]*"`), stringHandler},                    // string literals
-			{regexp.MustCompile(`'[^']'`), byteHandler},                       // byte literals
-			{regexp.MustCompile(`[0-9]+(?:\.[0-9]+)?`), numberHandler},        // decimal numbers
-			{regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*`), identifierHandler}, // identifiers
-			{regexp.MustCompile(`\+\+`), defaultHandler(PLUS_PLUS_TOKEN, "++")},
-			{regexp.MustCompile(`\-\-`), defaultHandler(MINUS_MINUS_TOKEN, "--")},
-			{regexp.MustCompile(`\->`), defaultHandler(ARROW_TOKEN, "->")},
-			{regexp.MustCompile(`\=>`), defaultHandler(FAT_ARROW_TOKEN, "=>")},
-			{regexp.MustCompile(`@`), defaultHandler(AT_TOKEN, "@")},
-			{regexp.MustCompile(`\$`), defaultHandler(DOLLAR_TOKEN, "$")},
-			{regexp.MustCompile(`!=`), defaultHandler(NOT_EQUAL_TOKEN, "!=")},
-			{regexp.MustCompile(`\+=`), defaultHandler(PLUS_EQUALS_TOKEN, "+=")},
-			{regexp.MustCompile(`-=`), defaultHandler(MINUS_EQUALS_TOKEN, "-=")},
-			{regexp.MustCompile(`\*=`), defaultHandler(MUL_EQUALS_TOKEN, "*=")},
-			{regexp.MustCompile(`/=`), defaultHandler(DIV_EQUALS_TOKEN, "/=")},
-			{regexp.MustCompile(`%=`), defaultHandler(MOD_EQUALS_TOKEN, "%=")},
-			{regexp.MustCompile(`\^=`), defaultHandler(EXP_EQUALS_TOKEN, "^=")},
-			{regexp.MustCompile(`!`), defaultHandler(NOT_TOKEN, "!")},
-			{regexp.MustCompile(`\-`), defaultHandler(MINUS_TOKEN, "-")},
-			{regexp.MustCompile(`\+`), defaultHandler(PLUS_TOKEN, "+")},
-			{regexp.MustCompile(`\*`), defaultHandler(MUL_TOKEN, "*")},
-			{regexp.MustCompile(`/`), defaultHandler(DIV_TOKEN, "/")},
-			{regexp.MustCompile(`%`), defaultHandler(MOD_TOKEN, "%")},
-			{regexp.MustCompile(`\^`), defaultHandler(EXP_TOKEN, "^")},
-			{regexp.MustCompile(`:=`), defaultHandler(WALRUS_TOKEN, ":=")},
-			{regexp.MustCompile(`<`), defaultHandler(LESS_EQUAL_TOKEN, "<=")},
-			{regexp.MustCompile(`<`), defaultHandler(LESS_TOKEN, "<")},
-			{regexp.MustCompile(`>=`), defaultHandler(GREATER_EQUAL_TOKEN, ">=")},
-			{regexp.MustCompile(`>`), defaultHandler(GREATER_TOKEN, ">")},
-			{regexp.MustCompile(`==`), defaultHandler(DOUBLE_EQUAL_TOKEN, "==")},
-			{regexp.MustCompile(`=`), defaultHandler(EQUALS_TOKEN, "=")},
-			{regexp.MustCompile(`\?:`), defaultHandler(OPTIONAL_TOKEN, "?:")},
-			{regexp.MustCompile(`:`), defaultHandler(COLON_TOKEN, ":")},
-			{regexp.MustCompile(`;`), defaultHandler(SEMI_COLON_TOKEN, ";")},
-			{regexp.MustCompile(`\(`), defaultHandler(OPEN_PAREN, "(")},
-			{regexp.MustCompile(`\)`), defaultHandler(CLOSE_PAREN, ")")},
-			{regexp.MustCompile(`\[`), defaultHandler(OPEN_BRACKET, "[")},
-			{regexp.MustCompile(`\]`), defaultHandler(CLOSE_BRACKET, "]")},
-			{regexp.MustCompile(`\{`), defaultHandler(OPEN_CURLY, "{")},
-			{regexp.MustCompile(`\}`), defaultHandler(CLOSE_CURLY, "}")},
-			{regexp.MustCompile(","), defaultHandler(COMMA_TOKEN, ",")},
-			{regexp.MustCompile(`\.`), defaultHandler(DOT_TOKEN, ".")},
-		},
 	}
 	return lex
 }
 
-// defaultHandler returns a regexHandler function that processes a token of the specified kind and value.
-// The returned function advances the lexer's position by the length of the value and pushes a new token
-// with the given kind and value to the lexer.
-//
-// Parameters:
-// - kind: The type of the token to be processed.
-// - value: The string value of the token.
-//
-// Returns:
-// - A regexHandler function that processes the token and updates the lexer state.
-func defaultHandler(kind builtins.TOKEN_KIND, value string) regexHandler {
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
 
-	return func(lex *Lexer, _ *regexp.Regexp) {
+func isHexDigit(char byte) bool {
+	return isDigit(char) || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+func isOctalDigit(char byte) bool {
+	return char >= '0' && char <= '7'
+}
 
+func isBinaryDigit(char byte) bool {
+	return char == '0' || char == '1'
+}
+
+func isIdentifierStart(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+}
+
+func isIdentifierPart(char byte) bool {
+	return isIdentifierStart(char) || isDigit(char)
+}
+
+func isWhitespace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\v' || char == '\f'
+}
+
+// reportError adds a critical lexer error spanning from start to the current position.
+func (lex *Lexer) reportError(start Position, msg string) {
+	errgen.Add(lex.FilePath, start.Line, lex.Position.Line, start.Column, lex.Position.Column, msg).Level(errgen.CRITICAL_ERROR)
+}
+
+// scanToken reads a single token (or skips a run of whitespace or a comment) starting at the current position.
+// It returns false if the current character cannot start any token.
+func (lex *Lexer) scanToken() bool {
+
+	char := lex.at()
+
+	switch {
+	case isWhitespace(char):
+		lex.skipWhitespace()
+	case char == '/' && lex.peek(1) == '/':
+		lex.skipLineComment()
+	case char == '/' && lex.peek(1) == '*':
+		lex.skipBlockComment()
+	case char == '"':
+		lex.scanString()
+	case char == '`':
+		lex.scanRawString()
+	case char == '\'':
+		lex.scanByte()
+	case isDigit(char):
+		lex.scanNumber()
+	case isIdentifierStart(char):
+		lex.scanIdentifier()
+	default:
+		kind := lex.punctuation()
+		if kind == "" {
+			return false
+		}
+		if len(lex.interpolations) > 0 {
+			current := &lex.interpolations[len(lex.interpolations)-1]
+			if kind == CLOSE_CURLY && current.depth == 0 {
+				// the embedded expression is closed, so the string continues
+				lex.interpolations = lex.interpolations[:len(lex.interpolations)-1]
+				lex.scanStringPart(lex.Position, true)
+				break
+			} else if kind == OPEN_CURLY {
+				current.depth++
+			} else if kind == CLOSE_CURLY {
+				current.depth--
+			}
+		}
+		// every operator and delimiter is spelled exactly like its token kind
 		start := lex.Position
-		lex.advance(value)
-		end := lex.Position
+		lex.advance(len(kind))
+		lex.push(NewToken(kind, string(kind), start, lex.Position))
+	}
 
-		lex.push(NewToken(kind, value, start, end))
+	return true
+}
+
+func (lex *Lexer) skipWhitespace() {
+	for !lex.atEOF() && isWhitespace(lex.at()) {
+		lex.advance(1)
 	}
 }
 
-// identifierHandler processes an identifier token in the lexer.
-// It uses a regular expression to find the identifier in the lexer's remainder,
-// advances the lexer's position, and then determines if the identifier is a keyword.
-// If it is a keyword, it pushes a keyword token onto the lexer's token stack;
-// otherwise, it pushes an identifier token.
-//
-// Parameters:
-// - lex: A pointer to the Lexer instance.
-// - regex: A regular expression used to find the identifier in the lexer's remainder.
-func identifierHandler(lex *Lexer, regex *regexp.Regexp) {
-	identifier := regex.FindString(lex.remainder())
+// skipLineComment skips a '//' comment up to, but not including, the end of the line.
+func (lex *Lexer) skipLineComment() {
+	for !lex.atEOF() && lex.at() != '\n' {
+		lex.advance(1)
+	}
+}
+
+// skipBlockComment skips a '/* */' comment. Block comments do not nest.
+func (lex *Lexer) skipBlockComment() {
+	start := lex.Position
+	lex.advance(2) // eat /*
+	for !lex.atEOF() {
+		if lex.at() == '*' && lex.peek(1) == '/' {
+			lex.advance(2)
+			return
+		}
+		lex.advance(1)
+	}
+	lex.reportError(start, "lexer:unterminated block comment")
+}
+
+// scanIdentifier reads an identifier and pushes it either as a keyword token or as an identifier token.
+func (lex *Lexer) scanIdentifier() {
 	start := lex.Position
-	lex.advance(identifier)
-	end := lex.Position
+	for !lex.atEOF() && isIdentifierPart(lex.at()) {
+		lex.advance(1)
+	}
+	identifier := lex.slice(start)
 	if IsKeyword(identifier) {
-		lex.push((NewToken(builtins.TOKEN_KIND(identifier), identifier, start, end)))
+		lex.push(NewToken(builtins.TOKEN_KIND(identifier), identifier, start, lex.Position))
 	} else {
-		lex.push(NewToken(IDENTIFIER_TOKEN, identifier, start, end))
+		lex.push(NewToken(IDENTIFIER_TOKEN, identifier, start, lex.Position))
 	}
 }
 
-// numberHandler processes numeric tokens from the lexer input.
-// It uses a regular expression to find a numeric match in the lexer's remainder,
-// advances the lexer's position, and determines whether the number is a float or an integer.
-// Depending on the type, it pushes the appropriate token (FLOAT or INT) to the lexer's token stack.
-//
-// Parameters:
+// numberSuffixes maps the type suffixes of number literals, as in 255u8 or 3.0f64, to their token kinds
+var numberSuffixes = map[string]builtins.TOKEN_KIND{
+	"i8":  INT8_TOKEN,
+	"i16": INT16_TOKEN,
+	"i32": INT32_TOKEN,
+	"i64": INT64_TOKEN,
+	"u8":  UINT8_TOKEN,
+	"u16": UINT16_TOKEN,
+	"u32": UINT32_TOKEN,
+	"u64": UINT64_TOKEN,
+	"f32": FLOAT32_TOKEN,
+	"f64": FLOAT64_TOKEN,
+}
+
+// numberBases maps the base prefix letters of integer literals to the name of the base and its digits
+var numberBases = map[byte]struct {
+	name    string
+	isDigit func(byte) bool
+}{
+	'x': {"hexadecimal", isHexDigit},
+	'o': {"octal", isOctalDigit},
+	'b': {"binary", isBinaryDigit},
+}
+
+// scanNumber reads a number literal: a decimal integer or float like 1_000 or 1.5e-3,
+// or an integer with a base prefix like 0xFF, 0o17 or 0b1010. A type suffix like u8 or f64 selects the token kind;
+// without one, the number is an untyped int or untyped float. A '.' is only part of the number when a digit follows it.
 //
-//	lex - A pointer to the Lexer instance.
-//	regex - A compiled regular expression used to find numeric matches in the lexer's input.
-func numberHandler(lex *Lexer, regex *regexp.Regexp) {
-	match := regex.FindString(lex.remainder())
+// The token value is the number without underscores and suffix. Prefixed integers keep their prefix,
+// and leading zeros are dropped from decimal integers, so 010 is ten.
+func (lex *Lexer) scanNumber() {
 	start := lex.Position
-	lex.advance(match)
-	end := lex.Position
-	//find the number is a float or an integer
-	if strings.Contains(match, ".") {
-		lex.push(NewToken(FLOAT32_TOKEN, match, start, end))
+	kind := UNTYPED_INT_TOKEN
+	isFloat := false
+
+	var value []byte
+
+	base, hasBase := numberBases[lex.peek(1)|0x20] // |0x20 lowercases the prefix letter
+	hasBase = hasBase && lex.at() == '0'
+
+	if hasBase {
+		value = append(value, '0', lex.peek(1)|0x20)
+		lex.advance(2)
+		hasDigits := lex.scanDigits(base.isDigit, &value)
+		if digit := lex.peek(0); isDigit(digit) {
+			lex.advance(1)
+			lex.reportError(start, fmt.Sprintf("lexer:invalid digit '%c' in %s literal", digit, base.name))
+			return
+		}
+		if !hasDigits {
+			lex.reportError(start, fmt.Sprintf("lexer:%s literal has no digits", base.name))
+			return
+		}
 	} else {
-		lex.push(NewToken(INT32_TOKEN, match, start, end))
+		lex.scanDigits(isDigit, &value)
+		// drop leading zeros, keeping a single zero
+		for len(value) > 1 && value[0] == '0' {
+			value = value[1:]
+		}
+		if !lex.atEOF() && lex.at() == '.' && isDigit(lex.peek(1)) {
+			isFloat = true
+			value = append(value, '.')
+			lex.advance(1)
+			lex.scanDigits(isDigit, &value)
+		}
+		if exponent := lex.peek(0); exponent == 'e' || exponent == 'E' {
+			sign := lex.peek(1)
+			if isDigit(sign) || ((sign == '+' || sign == '-') && isDigit(lex.peek(2))) {
+				isFloat = true
+				value = append(value, 'e')
+				lex.advance(1)
+				if !isDigit(sign) {
+					value = append(value, sign)
+					lex.advance(1)
+				}
+				lex.scanDigits(isDigit, &value)
+			}
+		}
+		if isFloat {
+			kind = UNTYPED_FLOAT_TOKEN
+		}
+	}
+
+	if !lex.atEOF() && isIdentifierStart(lex.at()) {
+		suffixStart := lex.Position
+		for !lex.atEOF() && isIdentifierPart(lex.at()) {
+			lex.advance(1)
+		}
+		suffix := lex.slice(suffixStart)
+		suffixKind, ok := numberSuffixes[suffix]
+		switch {
+		case !ok:
+			lex.reportError(start, fmt.Sprintf("lexer:invalid suffix '%s' on number literal", suffix))
+			return
+		case isFloat && suffixKind != FLOAT32_TOKEN && suffixKind != FLOAT64_TOKEN:
+			lex.reportError(start, fmt.Sprintf("lexer:integer suffix '%s' on float literal", suffix))
+			return
+		case hasBase && (suffixKind == FLOAT32_TOKEN || suffixKind == FLOAT64_TOKEN):
+			lex.reportError(start, fmt.Sprintf("lexer:float suffix '%s' on %s literal", suffix, base.name))
+			return
+		}
+		kind = suffixKind
 	}
+
+	lex.push(NewToken(kind, string(value), start, lex.Position))
 }
 
-// stringHandler processes a string literal in the lexer, using the provided regular expression to match the string.
-// It excludes the quotes from the matched string, updates the lexer's position, and pushes a new token.
-//
-// Parameters:
-//
-//	lex   - A pointer to the Lexer instance.
-//	regex - A regular expression used to find the string literal in the lexer's remainder.
-func stringHandler(lex *Lexer, regex *regexp.Regexp) {
-	match := regex.FindString(lex.remainder())
-	//exclude the quotes
-	stringLiteral := match[1 : len(match)-1]
+// scanDigits reads a run of digits, which may be separated by single underscores, and appends the digits to value.
+// It returns false if there were no digits.
+func (lex *Lexer) scanDigits(isDigitOf func(byte) bool, value *[]byte) bool {
+	found := false
+	for !lex.atEOF() {
+		char := lex.at()
+		if char == '_' {
+			if !found || !isDigitOf(lex.peek(1)) {
+				start := lex.Position
+				lex.advance(1)
+				lex.reportError(start, "lexer:'_' must separate successive digits")
+				return found
+			}
+			lex.advance(1)
+			continue
+		}
+		if !isDigitOf(char) {
+			break
+		}
+		*value = append(*value, char)
+		found = true
+		lex.advance(1)
+	}
+	return found
+}
+
+// scanString reads a double quoted string literal, which may span several lines.
+// The token value is the decoded string, without the quotes.
+func (lex *Lexer) scanString() {
 	start := lex.Position
-	lex.advance(match)
-	end := lex.Position
-	lex.push(NewToken(STR_TOKEN, stringLiteral, start, end))
+	lex.advance(1) // eat opening quote
+	lex.scanStringPart(start, false)
 }
 
-// byteHandler processes a byte literal in the lexer.
-// It uses a regular expression to find the byte literal in the remaining input,
-// excludes the quotes from the matched string, and then creates a new BYTE token
-// with the byte literal's value and its position in the input.
-//
-// Parameters:
-//
-//	lex - The Lexer instance that contains the input and current position.
-//	regex - The regular expression used to match the byte literal.
-func byteHandler(lex *Lexer, regex *regexp.Regexp) {
-	match := regex.FindString(lex.remainder())
-	//exclude the quotes
-	byteLiteral := match[1 : len(match)-1]
+// scanStringPart reads string text up to the closing quote or up to the '{' that starts an embedded expression.
+// start is the position of the opening quote, or of the '}' that closed the previous embedded expression.
+// A plain string is pushed as a STR_TOKEN; the parts of an interpolated string are pushed as STR_START, STR_MID and STR_END.
+func (lex *Lexer) scanStringPart(start Position, interpolated bool) {
+	if interpolated {
+		lex.advance(1) // eat }
+	}
+	var value []byte
+	for !lex.atEOF() && lex.at() != '"' && lex.at() != '{' {
+		if lex.at() == '\\' {
+			value = append(value, lex.scanEscape()...)
+			continue
+		}
+		value = append(value, lex.at())
+		lex.advance(1)
+	}
+	if lex.atEOF() {
+		lex.reportError(start, "lexer:unterminated string literal")
+		return
+	}
+
+	kind := STR_TOKEN
+	if lex.at() == '{' {
+		kind = STR_START_TOKEN
+		if interpolated {
+			kind = STR_MID_TOKEN
+		}
+		lex.interpolations = append(lex.interpolations, interpolation{start: lex.Position})
+	} else if interpolated {
+		kind = STR_END_TOKEN
+	}
+
+	lex.advance(1) // eat closing quote or {
+	lex.push(NewToken(kind, string(value), start, lex.Position))
+}
+
+// scanRawString reads a backtick quoted string literal. Raw strings may span several lines
+// and have no escape sequences, so the token value is exactly the text between the backticks.
+func (lex *Lexer) scanRawString() {
 	start := lex.Position
-	lex.advance(match)
-	end := lex.Position
-	lex.push(NewToken(UINT8_TOKEN, byteLiteral, start, end))
+	lex.advance(1) // eat opening backtick
+	for !lex.atEOF() && lex.at() != '`' {
+		lex.advance(1)
+	}
+	if lex.atEOF() {
+		lex.reportError(start, "lexer:unterminated raw string literal")
+		return
+	}
+	value := string(lex.sourceCode[start.Index+1 : lex.Position.Index])
+	lex.advance(1) // eat closing backtick
+	lex.push(NewToken(STR_TOKEN, value, start, lex.Position))
 }
 
-// skipHandler processes a token that should be skipped by the lexer.
-func skipHandler(lex *Lexer, regex *regexp.Regexp) {
-	match := regex.FindString(lex.remainder())
-	lex.advance(match)
+// scanByte reads a single quoted byte literal such as 'a' or '\n'. The token value is the decoded byte.
+func (lex *Lexer) scanByte() {
+	start := lex.Position
+	lex.advance(1) // eat opening quote
+
+	var value []byte
+	switch {
+	case lex.atEOF() || lex.at() == '\n':
+		lex.reportError(start, "lexer:unterminated byte literal")
+		return
+	case lex.at() == '\'':
+		lex.advance(1)
+		lex.reportError(start, "lexer:empty byte literal")
+		return
+	case lex.at() == '\\':
+		value = lex.scanEscape()
+	default:
+		value = []byte{lex.at()}
+		lex.advance(1)
+		// a non-ASCII character takes more than one byte
+		for !lex.atEOF() && lex.at()&0xC0 == 0x80 {
+			value = append(value, lex.at())
+			lex.advance(1)
+		}
+	}
+
+	if lex.atEOF() || lex.at() != '\'' {
+		lex.reportError(start, "lexer:unterminated byte literal")
+		return
+	}
+	lex.advance(1) // eat closing quote
+
+	if len(value) != 1 {
+		lex.reportError(start, "lexer:byte literal must be a single byte")
+		return
+	}
+
+	lex.push(NewToken(BYTE_TOKEN, string(value), start, lex.Position))
 }
 
-// Tokenize reads the source code from the specified file and tokenizes it.
-func Tokenize(filename string, debug bool) []Token {
-	utils.GREEN.Printf("Tokenizing %s\n", filename)
-	lex := createLexer(&filename)
-	lex.FilePath = filename
+// scanEscape decodes the escape sequence starting at the backslash at the current position
+// and returns the bytes it stands for. \u{...} escapes are returned UTF-8 encoded.
+func (lex *Lexer) scanEscape() []byte {
+	start := lex.Position
+	lex.advance(1) // eat backslash
 
-	for !lex.atEOF() {
+	if lex.atEOF() {
+		lex.reportError(start, "lexer:unterminated escape sequence")
+		return nil
+	}
 
-		matched := false
+	char := lex.at()
+	lex.advance(1)
+
+	switch char {
+	case 'n':
+		return []byte{'\n'}
+	case 't':
+		return []byte{'\t'}
+	case 'r':
+		return []byte{'\r'}
+	case '0':
+		return []byte{0}
+	case '\\', '"', '\'', '{', '}':
+		return []byte{char}
+	case 'x':
+		digits := lex.scanHexDigits(2)
+		if len(digits) != 2 {
+			lex.reportError(start, "lexer:\\x escape must be followed by exactly two hex digits")
+			return nil
+		}
+		value, _ := strconv.ParseUint(digits, 16, 8)
+		return []byte{byte(value)}
+	case 'u':
+		if lex.atEOF() || lex.at() != '{' {
+			lex.reportError(start, "lexer:\\u escape must be written as \\u{...}")
+			return nil
+		}
+		lex.advance(1) // eat {
+		digits := lex.scanHexDigits(6)
+		if lex.atEOF() || lex.at() != '}' || len(digits) == 0 {
+			lex.reportError(start, "lexer:\\u escape must be written as \\u{...} with one to six hex digits")
+			return nil
+		}
+		lex.advance(1) // eat }
+		value, _ := strconv.ParseUint(digits, 16, 32)
+		if !utf8.ValidRune(rune(value)) {
+			lex.reportError(start, fmt.Sprintf("lexer:invalid unicode code point U+%s", strings.ToUpper(digits)))
+			return nil
+		}
+		return utf8.AppendRune(nil, rune(value))
+	}
 
-		for _, pattern := range lex.patterns {
+	lex.reportError(start, fmt.Sprintf("lexer:invalid escape sequence '\\%c'", char))
+	return nil
+}
 
-			loc := pattern.regex.FindStringIndex(lex.remainder())
+// scanHexDigits reads up to max hex digits and returns them.
+func (lex *Lexer) scanHexDigits(max int) string {
+	start := lex.Position
+	for i := 0; i < max && !lex.atEOF() && isHexDigit(lex.at()); i++ {
+		lex.advance(1)
+	}
+	return lex.slice(start)
+}
 
-			if loc != nil && loc[0] == 0 {
-				pattern.handler(lex, pattern.regex)
-				matched = true
-				break
+// punctuation returns the kind of the operator or delimiter at the current position, preferring the longest match.
+// It returns an empty kind if the current character is not an operator or delimiter.
+func (lex *Lexer) punctuation() builtins.TOKEN_KIND {
+	next := lex.peek(1)
+	switch lex.at() {
+	case '+':
+		switch next {
+		case '+':
+			return PLUS_PLUS_TOKEN
+		case '=':
+			return PLUS_EQUALS_TOKEN
+		}
+		return PLUS_TOKEN
+	case '-':
+		switch next {
+		case '-':
+			return MINUS_MINUS_TOKEN
+		case '>':
+			return ARROW_TOKEN
+		case '=':
+			return MINUS_EQUALS_TOKEN
+		}
+		return MINUS_TOKEN
+	case '*':
+		if next == '=' {
+			return MUL_EQUALS_TOKEN
+		}
+		return MUL_TOKEN
+	case '/':
+		if next == '=' {
+			return DIV_EQUALS_TOKEN
+		}
+		return DIV_TOKEN
+	case '%':
+		if next == '=' {
+			return MOD_EQUALS_TOKEN
+		}
+		return MOD_TOKEN
+	case '^':
+		if next == '=' {
+			return EXP_EQUALS_TOKEN
+		}
+		return EXP_TOKEN
+	case '!':
+		if next == '=' {
+			return NOT_EQUAL_TOKEN
+		}
+		return NOT_TOKEN
+	case '&':
+		switch next {
+		case '&':
+			return AND_TOKEN
+		case '=':
+			return BIT_AND_EQUALS_TOKEN
+		}
+		return BIT_AND_TOKEN
+	case '|':
+		switch next {
+		case '|':
+			return OR_TOKEN
+		case '=':
+			return BIT_OR_EQUALS_TOKEN
+		}
+		return BIT_OR_TOKEN
+	case '~':
+		if next == '=' {
+			return BIT_XOR_EQUALS_TOKEN
+		}
+		return BIT_XOR_TOKEN
+	case '=':
+		switch next {
+		case '>':
+			return FAT_ARROW_TOKEN
+		case '=':
+			return DOUBLE_EQUAL_TOKEN
+		}
+		return EQUALS_TOKEN
+	case '<':
+		switch next {
+		case '<':
+			if lex.peek(2) == '=' {
+				return SHIFT_LEFT_EQUALS_TOKEN
+			}
+			return SHIFT_LEFT_TOKEN
+		case '=':
+			return LESS_EQUAL_TOKEN
+		}
+		return LESS_TOKEN
+	case '>':
+		switch next {
+		case '>':
+			if lex.peek(2) == '=' {
+				return SHIFT_RIGHT_EQUALS_TOKEN
+			}
+			return SHIFT_RIGHT_TOKEN
+		case '=':
+			return GREATER_EQUAL_TOKEN
+		}
+		return GREATER_TOKEN
+	case ':':
+		if next == '=' {
+			return WALRUS_TOKEN
+		}
+		return COLON_TOKEN
+	case '?':
+		switch next {
+		case ':':
+			return OPTIONAL_TOKEN
+		case '?':
+			return NULL_COALESCING_TOKEN
+		case '.':
+			return OPTIONAL_CHAIN_TOKEN
+		}
+		return QUESTION_TOKEN
+	case '@':
+		return AT_TOKEN
+	case '$':
+		return DOLLAR_TOKEN
+	case ';':
+		return SEMI_COLON_TOKEN
+	case ',':
+		return COMMA_TOKEN
+	case '.':
+		if next == '.' {
+			if lex.peek(2) == '=' {
+				return DOT_DOT_EQUAL_TOKEN
 			}
+			return DOT_DOT_TOKEN
 		}
+		return DOT_TOKEN
+	case '(':
+		return OPEN_PAREN
+	case ')':
+		return CLOSE_PAREN
+	case '[':
+		return OPEN_BRACKET
+	case ']':
+		return CLOSE_BRACKET
+	case '{':
+		return OPEN_CURLY
+	case '}':
+		return CLOSE_CURLY
+	}
+	return ""
+}
 
-		if !matched {
+// tokenize scans the whole source and returns the tokens, terminated by an EOF token.
+// It returns nil if an unexpected character is found.
+func (lex *Lexer) tokenize() []Token {
+	for !lex.atEOF() {
+		if !lex.scanToken() {
 			errStr := fmt.Sprintf("lexer:unexpected charecter: '%c'", lex.at())
-			errgen.Add(filename, lex.Position.Line, lex.Position.Line, lex.Position.Column, lex.Position.Column, errStr).Level(errgen.CRITICAL_ERROR)
+			errgen.Add(lex.FilePath, lex.Position.Line, lex.Position.Line, lex.Position.Column, lex.Position.Column, errStr).Level(errgen.CRITICAL_ERROR)
 			return nil
 		}
 	}
 
+	if len(lex.interpolations) > 0 {
+		lex.reportError(lex.interpolations[len(lex.interpolations)-1].start, "lexer:unterminated string interpolation")
+		return nil
+	}
+
 	lex.push(NewToken(EOF_TOKEN, "eof", lex.Position, lex.Position))
 
+	return lex.Tokens
+}
+
+// Tokenize reads the source code from the specified file and tokenizes it.
+func Tokenize(filename string, debug bool) []Token {
+	utils.GREEN.Printf("Tokenizing %s\n", filename)
+	lex := createLexer(&filename)
+
+	tokens := lex.tokenize()
+
 	//litter.Dump(lex.Tokens)
 	if debug {
-		for _, token := range lex.Tokens {
+		for _, token := range tokens {
 			token.Debug(filename)
 		}
 	}
 
 	utils.GREEN.Println("Tokenization complete")
 
-	return lex.Tokens
+	return tokens
+}
+
+// TokenizeSource tokenizes source code that is already in memory, such as an editor buffer.
+// The name is used as the file path of the tokens' diagnostics. Nothing is read from disk or printed,
+// and lexer errors are returned as diagnostics instead of stopping the program.
+// The returned tokens are nil if lexing failed. To render the diagnostics with errgen.Render, register the buffer
+// with errgen.RegisterSource first, and unregister it once they are rendered.
+func TokenizeSource(name string, src []byte) ([]Token, []errgen.Diagnostic) {
+	var tokens []Token
+
+	diagnostics := errgen.Collect(func() {
+		tokens = newLexer(name, src).tokenize()
+	})
+
+	return tokens, diagnostics
 }
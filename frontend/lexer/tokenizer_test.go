@@ -1,7 +1,9 @@
 package lexer
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -45,10 +47,124 @@ func TestTokenize(t *testing.T) {
 			name:  "Number literal",
 			input: "123",
 			expected: []Token{
-				NewToken(INT32_TOKEN, "123", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(UNTYPED_INT_TOKEN, "123", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 4, Index: 3}),
 				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 4, Index: 3}, Position{Line: 1, Column: 4, Index: 3}),
 			},
 		},
+		{
+			name:  "Float literal followed by member access",
+			input: "1.5 a.b 2.",
+			expected: []Token{
+				NewToken(UNTYPED_FLOAT_TOKEN, "1.5", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(IDENTIFIER_TOKEN, "a", Position{Line: 1, Column: 5, Index: 4}, Position{Line: 1, Column: 6, Index: 5}),
+				NewToken(DOT_TOKEN, ".", Position{Line: 1, Column: 6, Index: 5}, Position{Line: 1, Column: 7, Index: 6}),
+				NewToken(IDENTIFIER_TOKEN, "b", Position{Line: 1, Column: 7, Index: 6}, Position{Line: 1, Column: 8, Index: 7}),
+				NewToken(UNTYPED_INT_TOKEN, "2", Position{Line: 1, Column: 9, Index: 8}, Position{Line: 1, Column: 10, Index: 9}),
+				NewToken(DOT_TOKEN, ".", Position{Line: 1, Column: 10, Index: 9}, Position{Line: 1, Column: 11, Index: 10}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 11, Index: 10}, Position{Line: 1, Column: 11, Index: 10}),
+			},
+		},
+		{
+			name:  "Operators use the longest match",
+			input: "<= < >= ++ += -> => == := ?: !=",
+			expected: []Token{
+				NewToken(LESS_EQUAL_TOKEN, "<=", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 3, Index: 2}),
+				NewToken(LESS_TOKEN, "<", Position{Line: 1, Column: 4, Index: 3}, Position{Line: 1, Column: 5, Index: 4}),
+				NewToken(GREATER_EQUAL_TOKEN, ">=", Position{Line: 1, Column: 6, Index: 5}, Position{Line: 1, Column: 8, Index: 7}),
+				NewToken(PLUS_PLUS_TOKEN, "++", Position{Line: 1, Column: 9, Index: 8}, Position{Line: 1, Column: 11, Index: 10}),
+				NewToken(PLUS_EQUALS_TOKEN, "+=", Position{Line: 1, Column: 12, Index: 11}, Position{Line: 1, Column: 14, Index: 13}),
+				NewToken(ARROW_TOKEN, "->", Position{Line: 1, Column: 15, Index: 14}, Position{Line: 1, Column: 17, Index: 16}),
+				NewToken(FAT_ARROW_TOKEN, "=>", Position{Line: 1, Column: 18, Index: 17}, Position{Line: 1, Column: 20, Index: 19}),
+				NewToken(DOUBLE_EQUAL_TOKEN, "==", Position{Line: 1, Column: 21, Index: 20}, Position{Line: 1, Column: 23, Index: 22}),
+				NewToken(WALRUS_TOKEN, ":=", Position{Line: 1, Column: 24, Index: 23}, Position{Line: 1, Column: 26, Index: 25}),
+				NewToken(OPTIONAL_TOKEN, "?:", Position{Line: 1, Column: 27, Index: 26}, Position{Line: 1, Column: 29, Index: 28}),
+				NewToken(NOT_EQUAL_TOKEN, "!=", Position{Line: 1, Column: 30, Index: 29}, Position{Line: 1, Column: 32, Index: 31}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 32, Index: 31}, Position{Line: 1, Column: 32, Index: 31}),
+			},
+		},
+		{
+			name:  "Logical operators",
+			input: "a&&b || !c",
+			expected: []Token{
+				NewToken(IDENTIFIER_TOKEN, "a", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 2, Index: 1}),
+				NewToken(AND_TOKEN, "&&", Position{Line: 1, Column: 2, Index: 1}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(IDENTIFIER_TOKEN, "b", Position{Line: 1, Column: 4, Index: 3}, Position{Line: 1, Column: 5, Index: 4}),
+				NewToken(OR_TOKEN, "||", Position{Line: 1, Column: 6, Index: 5}, Position{Line: 1, Column: 8, Index: 7}),
+				NewToken(NOT_TOKEN, "!", Position{Line: 1, Column: 9, Index: 8}, Position{Line: 1, Column: 10, Index: 9}),
+				NewToken(IDENTIFIER_TOKEN, "c", Position{Line: 1, Column: 10, Index: 9}, Position{Line: 1, Column: 11, Index: 10}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 11, Index: 10}, Position{Line: 1, Column: 11, Index: 10}),
+			},
+		},
+		{
+			name:  "Bitwise operators",
+			input: "& | ~ << >> &= |= ~= <<= >>=",
+			expected: []Token{
+				NewToken(BIT_AND_TOKEN, "&", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 2, Index: 1}),
+				NewToken(BIT_OR_TOKEN, "|", Position{Line: 1, Column: 3, Index: 2}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(BIT_XOR_TOKEN, "~", Position{Line: 1, Column: 5, Index: 4}, Position{Line: 1, Column: 6, Index: 5}),
+				NewToken(SHIFT_LEFT_TOKEN, "<<", Position{Line: 1, Column: 7, Index: 6}, Position{Line: 1, Column: 9, Index: 8}),
+				NewToken(SHIFT_RIGHT_TOKEN, ">>", Position{Line: 1, Column: 10, Index: 9}, Position{Line: 1, Column: 12, Index: 11}),
+				NewToken(BIT_AND_EQUALS_TOKEN, "&=", Position{Line: 1, Column: 13, Index: 12}, Position{Line: 1, Column: 15, Index: 14}),
+				NewToken(BIT_OR_EQUALS_TOKEN, "|=", Position{Line: 1, Column: 16, Index: 15}, Position{Line: 1, Column: 18, Index: 17}),
+				NewToken(BIT_XOR_EQUALS_TOKEN, "~=", Position{Line: 1, Column: 19, Index: 18}, Position{Line: 1, Column: 21, Index: 20}),
+				NewToken(SHIFT_LEFT_EQUALS_TOKEN, "<<=", Position{Line: 1, Column: 22, Index: 21}, Position{Line: 1, Column: 25, Index: 24}),
+				NewToken(SHIFT_RIGHT_EQUALS_TOKEN, ">>=", Position{Line: 1, Column: 26, Index: 25}, Position{Line: 1, Column: 29, Index: 28}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 29, Index: 28}, Position{Line: 1, Column: 29, Index: 28}),
+			},
+		},
+		{
+			name:  "Ranges",
+			input: "0..n 1..=10 step 2",
+			expected: []Token{
+				NewToken(UNTYPED_INT_TOKEN, "0", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 2, Index: 1}),
+				NewToken(DOT_DOT_TOKEN, "..", Position{Line: 1, Column: 2, Index: 1}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(IDENTIFIER_TOKEN, "n", Position{Line: 1, Column: 4, Index: 3}, Position{Line: 1, Column: 5, Index: 4}),
+				NewToken(UNTYPED_INT_TOKEN, "1", Position{Line: 1, Column: 6, Index: 5}, Position{Line: 1, Column: 7, Index: 6}),
+				NewToken(DOT_DOT_EQUAL_TOKEN, "..=", Position{Line: 1, Column: 7, Index: 6}, Position{Line: 1, Column: 10, Index: 9}),
+				NewToken(UNTYPED_INT_TOKEN, "10", Position{Line: 1, Column: 10, Index: 9}, Position{Line: 1, Column: 12, Index: 11}),
+				NewToken(IDENTIFIER_TOKEN, "step", Position{Line: 1, Column: 13, Index: 12}, Position{Line: 1, Column: 17, Index: 16}),
+				NewToken(UNTYPED_INT_TOKEN, "2", Position{Line: 1, Column: 18, Index: 17}, Position{Line: 1, Column: 19, Index: 18}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 19, Index: 18}, Position{Line: 1, Column: 19, Index: 18}),
+			},
+		},
+		{
+			name:  "Try operator",
+			input: "f()? ?:",
+			expected: []Token{
+				NewToken(IDENTIFIER_TOKEN, "f", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 2, Index: 1}),
+				NewToken(OPEN_PAREN, "(", Position{Line: 1, Column: 2, Index: 1}, Position{Line: 1, Column: 3, Index: 2}),
+				NewToken(CLOSE_PAREN, ")", Position{Line: 1, Column: 3, Index: 2}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(QUESTION_TOKEN, "?", Position{Line: 1, Column: 4, Index: 3}, Position{Line: 1, Column: 5, Index: 4}),
+				NewToken(OPTIONAL_TOKEN, "?:", Position{Line: 1, Column: 6, Index: 5}, Position{Line: 1, Column: 8, Index: 7}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 8, Index: 7}, Position{Line: 1, Column: 8, Index: 7}),
+			},
+		},
+		{
+			name:  "Maybe operators",
+			input: "a?.b ?? c!",
+			expected: []Token{
+				NewToken(IDENTIFIER_TOKEN, "a", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 2, Index: 1}),
+				NewToken(OPTIONAL_CHAIN_TOKEN, "?.", Position{Line: 1, Column: 2, Index: 1}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(IDENTIFIER_TOKEN, "b", Position{Line: 1, Column: 4, Index: 3}, Position{Line: 1, Column: 5, Index: 4}),
+				NewToken(NULL_COALESCING_TOKEN, "??", Position{Line: 1, Column: 6, Index: 5}, Position{Line: 1, Column: 8, Index: 7}),
+				NewToken(IDENTIFIER_TOKEN, "c", Position{Line: 1, Column: 9, Index: 8}, Position{Line: 1, Column: 10, Index: 9}),
+				NewToken(NOT_TOKEN, "!", Position{Line: 1, Column: 10, Index: 9}, Position{Line: 1, Column: 11, Index: 10}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 11, Index: 10}, Position{Line: 1, Column: 11, Index: 10}),
+			},
+		},
+		{
+			name:  "Keywords, comments and new lines",
+			input: "let x /* a\nb */ := 'c';\n// done\nret",
+			expected: []Token{
+				NewToken(LET_TOKEN, "let", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 4, Index: 3}),
+				NewToken(IDENTIFIER_TOKEN, "x", Position{Line: 1, Column: 5, Index: 4}, Position{Line: 1, Column: 6, Index: 5}),
+				NewToken(WALRUS_TOKEN, ":=", Position{Line: 2, Column: 6, Index: 16}, Position{Line: 2, Column: 8, Index: 18}),
+				NewToken(BYTE_TOKEN, "c", Position{Line: 2, Column: 9, Index: 19}, Position{Line: 2, Column: 12, Index: 22}),
+				NewToken(SEMI_COLON_TOKEN, ";", Position{Line: 2, Column: 12, Index: 22}, Position{Line: 2, Column: 13, Index: 23}),
+				NewToken(RETURN_TOKEN, "ret", Position{Line: 4, Column: 1, Index: 32}, Position{Line: 4, Column: 4, Index: 35}),
+				NewToken(EOF_TOKEN, "eof", Position{Line: 4, Column: 4, Index: 35}, Position{Line: 4, Column: 4, Index: 35}),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -84,8 +200,240 @@ func compareTokens(t *testing.T, tokens, expected []Token) {
 	}
 
 	for i, token := range tokens {
-		if token.Kind != expected[i].Kind || token.Value != expected[i].Value {
+		if token != expected[i] {
 			t.Errorf("expected token %v, got %v", expected[i], token)
 		}
 	}
 }
+
+// BenchmarkTokenize checks that tokenizing stays linear in the size of the source.
+func BenchmarkTokenize(b *testing.B) {
+	chunk := "let a : i32 = 10; // a comment\nfn add(a: i32, b: i32) -> i32 {\n    ret a + b * 2.5 <= \"str\";\n}\n"
+	for _, lines := range []int{1000, 5000, 20000} {
+		b.Run(fmt.Sprintf("%d lines", lines), func(b *testing.B) {
+			file, err := os.CreateTemp("", "bench")
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer os.Remove(file.Name())
+			file.WriteString(strings.Repeat(chunk, lines/4))
+			file.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Tokenize(file.Name(), false)
+			}
+		})
+	}
+}
+
+func TestTokenizeSource(t *testing.T) {
+	tokens, diagnostics := TokenizeSource("buffer.wal", []byte("let a := 10;"))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	compareTokens(t, tokens, []Token{
+		NewToken(LET_TOKEN, "let", Position{Line: 1, Column: 1, Index: 0}, Position{Line: 1, Column: 4, Index: 3}),
+		NewToken(IDENTIFIER_TOKEN, "a", Position{Line: 1, Column: 5, Index: 4}, Position{Line: 1, Column: 6, Index: 5}),
+		NewToken(WALRUS_TOKEN, ":=", Position{Line: 1, Column: 7, Index: 6}, Position{Line: 1, Column: 9, Index: 8}),
+		NewToken(UNTYPED_INT_TOKEN, "10", Position{Line: 1, Column: 10, Index: 9}, Position{Line: 1, Column: 12, Index: 11}),
+		NewToken(SEMI_COLON_TOKEN, ";", Position{Line: 1, Column: 12, Index: 11}, Position{Line: 1, Column: 13, Index: 12}),
+		NewToken(EOF_TOKEN, "eof", Position{Line: 1, Column: 13, Index: 12}, Position{Line: 1, Column: 13, Index: 12}),
+	})
+}
+
+func TestTokenizeSourceError(t *testing.T) {
+	tokens, diagnostics := TokenizeSource("buffer.wal", []byte("let a := #;"))
+
+	if tokens != nil {
+		t.Errorf("expected no tokens, got %v", tokens)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diagnostics))
+	}
+
+	if diagnostics[0].FilePath != "buffer.wal" || diagnostics[0].LineStart != 1 || diagnostics[0].ColStart != 10 {
+		t.Errorf("unexpected diagnostic %v", diagnostics[0])
+	}
+}
+
+func TestTokenizeEscapes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		kind  string
+		value string
+	}{
+		{"Escaped quotes", `"say \"hi\""`, string(STR_TOKEN), `say "hi"`},
+		{"Control characters", `"a\n\tb\r\0"`, string(STR_TOKEN), "a\n\tb\r\x00"},
+		{"Hex and unicode escapes", `"\x41\u{e9}\u{1F600}"`, string(STR_TOKEN), "Aé😀"},
+		{"Multi-line string", "\"a\nb\"", string(STR_TOKEN), "a\nb"},
+		{"Raw string", "`C:\\path\\n\n{x}`", string(STR_TOKEN), "C:\\path\\n\n{x}"},
+		{"Escaped single quote", `'\''`, string(BYTE_TOKEN), "'"},
+		{"Escaped tab", `'\t'`, string(BYTE_TOKEN), "\t"},
+		{"Hex byte", `'\xff'`, string(BYTE_TOKEN), "\xff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, diagnostics := TokenizeSource("buffer.wal", []byte(tt.input))
+			if len(diagnostics) != 0 {
+				t.Fatalf("expected no diagnostics, got %v", diagnostics)
+			}
+			if len(tokens) != 2 {
+				t.Fatalf("expected 2 tokens, got %d", len(tokens))
+			}
+			if string(tokens[0].Kind) != tt.kind || tokens[0].Value != tt.value {
+				t.Errorf("expected %s %q, got %s %q", tt.kind, tt.value, tokens[0].Kind, tokens[0].Value)
+			}
+			if tokens[0].End.Index != len(tt.input) {
+				t.Errorf("expected the token to end at %d, got %d", len(tt.input), tokens[0].End.Index)
+			}
+		})
+	}
+}
+
+func TestTokenizeInvalidEscapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		colStart int
+		colEnd   int
+	}{
+		{"Unknown escape", `let s := "ab\q";`, 13, 15},
+		{"Short hex escape", `"\x4"`, 2, 5},
+		{"Unclosed unicode escape", `"\u{41"`, 2, 7},
+		{"Surrogate code point", `"\u{D800}"`, 2, 10},
+		{"Empty byte", `''`, 1, 3},
+		{"Byte with two characters", `'ab'`, 1, 3},
+		{"Multi-byte character in a byte", `'é'`, 1, 4},
+		{"Unterminated raw string", "`abc", 1, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, diagnostics := TokenizeSource("buffer.wal", []byte(tt.input))
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %v", diagnostics)
+			}
+			if diagnostics[0].ColStart != tt.colStart || diagnostics[0].ColEnd != tt.colEnd {
+				t.Errorf("expected columns %d-%d, got %v", tt.colStart, tt.colEnd, diagnostics[0])
+			}
+		})
+	}
+}
+
+func TestTokenizeInterpolation(t *testing.T) {
+	tokens, diagnostics := TokenizeSource("buffer.wal", []byte(`"a{x}b{ $m{"k" => "{y}"} }\{c}"`))
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	expected := []struct {
+		kind  string
+		value string
+	}{
+		{string(STR_START_TOKEN), "a"},
+		{string(IDENTIFIER_TOKEN), "x"},
+		{string(STR_MID_TOKEN), "b"},
+		{string(DOLLAR_TOKEN), "$"},
+		{string(IDENTIFIER_TOKEN), "m"},
+		{string(OPEN_CURLY), "{"},
+		{string(STR_TOKEN), "k"},
+		{string(FAT_ARROW_TOKEN), "=>"},
+		{string(STR_START_TOKEN), ""},
+		{string(IDENTIFIER_TOKEN), "y"},
+		{string(STR_END_TOKEN), ""},
+		{string(CLOSE_CURLY), "}"},
+		{string(STR_END_TOKEN), "{c}"},
+		{string(EOF_TOKEN), "eof"},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+
+	for i, token := range tokens {
+		if string(token.Kind) != expected[i].kind || token.Value != expected[i].value {
+			t.Errorf("token %d: expected %s %q, got %s %q", i, expected[i].kind, expected[i].value, token.Kind, token.Value)
+		}
+	}
+}
+
+func TestTokenizeUnterminatedInterpolation(t *testing.T) {
+	_, diagnostics := TokenizeSource("buffer.wal", []byte(`let s := "a{x + 1`))
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diagnostics)
+	}
+	if diagnostics[0].ColStart != 12 {
+		t.Errorf("expected the error at the opening brace, got %v", diagnostics[0])
+	}
+}
+
+func TestTokenizeNumbers(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  string
+		value string
+	}{
+		{"1_000_000", string(UNTYPED_INT_TOKEN), "1000000"},
+		{"007", string(UNTYPED_INT_TOKEN), "7"},
+		{"0", string(UNTYPED_INT_TOKEN), "0"},
+		{"0xFF", string(UNTYPED_INT_TOKEN), "0xFF"},
+		{"0Xdead_beef", string(UNTYPED_INT_TOKEN), "0xdeadbeef"},
+		{"0o17", string(UNTYPED_INT_TOKEN), "0o17"},
+		{"0b1010_1010", string(UNTYPED_INT_TOKEN), "0b10101010"},
+		{"1.5e-3", string(UNTYPED_FLOAT_TOKEN), "1.5e-3"},
+		{"2E+10", string(UNTYPED_FLOAT_TOKEN), "2e+10"},
+		{"1e3", string(UNTYPED_FLOAT_TOKEN), "1e3"},
+		{"3.141_592", string(UNTYPED_FLOAT_TOKEN), "3.141592"},
+		{"255u8", string(UINT8_TOKEN), "255"},
+		{"0xFFu16", string(UINT16_TOKEN), "0xFF"},
+		{"10i64", string(INT64_TOKEN), "10"},
+		{"3.0f64", string(FLOAT64_TOKEN), "3.0"},
+		{"3f32", string(FLOAT32_TOKEN), "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, diagnostics := TokenizeSource("buffer.wal", []byte(tt.input))
+			if len(diagnostics) != 0 {
+				t.Fatalf("expected no diagnostics, got %v", diagnostics)
+			}
+			if len(tokens) != 2 {
+				t.Fatalf("expected 2 tokens, got %v", tokens)
+			}
+			if string(tokens[0].Kind) != tt.kind || tokens[0].Value != tt.value {
+				t.Errorf("expected %s %q, got %s %q", tt.kind, tt.value, tokens[0].Kind, tokens[0].Value)
+			}
+		})
+	}
+}
+
+func TestTokenizeInvalidNumbers(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{"0x", "lexer:hexadecimal literal has no digits"},
+		{"0b102", "lexer:invalid digit '2' in binary literal"},
+		{"0o8", "lexer:invalid digit '8' in octal literal"},
+		{"1__000", "lexer:'_' must separate successive digits"},
+		{"1_", "lexer:'_' must separate successive digits"},
+		{"10abc", "lexer:invalid suffix 'abc' on number literal"},
+		{"1.5u8", "lexer:integer suffix 'u8' on float literal"},
+		{"0b1f64", "lexer:float suffix 'f64' on binary literal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, diagnostics := TokenizeSource("buffer.wal", []byte(tt.input))
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
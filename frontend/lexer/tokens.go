@@ -18,12 +18,21 @@ const (
 	FOREACH_TOKEN    builtins.TOKEN_KIND = "foreach"
 	IDENTIFIER_TOKEN builtins.TOKEN_KIND = "identifier"
 	PRIVATE_TOKEN    builtins.TOKEN_KIND = "priv"
+	PUBLIC_TOKEN     builtins.TOKEN_KIND = "pub"
 	IMPL_TOKEN       builtins.TOKEN_KIND = "impl"
 	RETURN_TOKEN     builtins.TOKEN_KIND = "ret"
 	IN_TOKEN         builtins.TOKEN_KIND = "in"
 	AS_TOKEN         builtins.TOKEN_KIND = "as"
 	SAFE_TOKEN       builtins.TOKEN_KIND = "safe"
 	OTHERWISE_TOKEN  builtins.TOKEN_KIND = "otherwise"
+	SWITCH_TOKEN     builtins.TOKEN_KIND = "switch"
+	CASE_TOKEN       builtins.TOKEN_KIND = "case"
+	DEFAULT_TOKEN    builtins.TOKEN_KIND = "default"
+	BREAK_TOKEN      builtins.TOKEN_KIND = "break"
+	CONTINUE_TOKEN   builtins.TOKEN_KIND = "continue"
+	MATCH_TOKEN      builtins.TOKEN_KIND = "match"
+	PACKAGE_TOKEN    builtins.TOKEN_KIND = "package"
+	IMPORT_TOKEN     builtins.TOKEN_KIND = "import"
 	//data types
 	INT8_TOKEN      builtins.TOKEN_KIND = builtins.INT8
 	INT16_TOKEN     builtins.TOKEN_KIND = builtins.INT16
@@ -42,8 +51,14 @@ const (
 	STRUCT_TOKEN    builtins.TOKEN_KIND = builtins.STRUCT
 	FUNCTION_TOKEN  builtins.TOKEN_KIND = builtins.FUNCTION
 	INTERFACE_TOKEN builtins.TOKEN_KIND = builtins.INTERFACE
+	ENUM_TOKEN      builtins.TOKEN_KIND = builtins.ENUM
 	MAYBE_TOKEN     builtins.TOKEN_KIND = builtins.MAYBE
 	MAP_TOKEN       builtins.TOKEN_KIND = builtins.MAP
+	RANGE_TOKEN     builtins.TOKEN_KIND = builtins.RANGE
+	RESULT_TOKEN    builtins.TOKEN_KIND = builtins.RESULT
+	//number literals without a type suffix. They take the type their context expects
+	UNTYPED_INT_TOKEN   builtins.TOKEN_KIND = "untyped_int"
+	UNTYPED_FLOAT_TOKEN builtins.TOKEN_KIND = "untyped_float"
 
 	//increment and decrement
 	PLUS_PLUS_TOKEN   builtins.TOKEN_KIND = "++"
@@ -62,6 +77,14 @@ const (
 	GREATER_EQUAL_TOKEN builtins.TOKEN_KIND = ">="
 	NOT_EQUAL_TOKEN     builtins.TOKEN_KIND = "!="
 	DOUBLE_EQUAL_TOKEN  builtins.TOKEN_KIND = "=="
+	AND_TOKEN           builtins.TOKEN_KIND = "&&"
+	OR_TOKEN            builtins.TOKEN_KIND = "||"
+	//bitwise operators. ~ is xor between two operands and bitwise not before one
+	BIT_AND_TOKEN     builtins.TOKEN_KIND = "&"
+	BIT_OR_TOKEN      builtins.TOKEN_KIND = "|"
+	BIT_XOR_TOKEN     builtins.TOKEN_KIND = "~"
+	SHIFT_LEFT_TOKEN  builtins.TOKEN_KIND = "<<"
+	SHIFT_RIGHT_TOKEN builtins.TOKEN_KIND = ">>"
 	//assignment
 	WALRUS_TOKEN       builtins.TOKEN_KIND = ":="
 	COLON_TOKEN        builtins.TOKEN_KIND = ":"
@@ -72,6 +95,12 @@ const (
 	DIV_EQUALS_TOKEN   builtins.TOKEN_KIND = "/="
 	MOD_EQUALS_TOKEN   builtins.TOKEN_KIND = "%="
 	EXP_EQUALS_TOKEN   builtins.TOKEN_KIND = "^="
+
+	BIT_AND_EQUALS_TOKEN     builtins.TOKEN_KIND = "&="
+	BIT_OR_EQUALS_TOKEN      builtins.TOKEN_KIND = "|="
+	BIT_XOR_EQUALS_TOKEN     builtins.TOKEN_KIND = "~="
+	SHIFT_LEFT_EQUALS_TOKEN  builtins.TOKEN_KIND = "<<="
+	SHIFT_RIGHT_EQUALS_TOKEN builtins.TOKEN_KIND = ">>="
 	//delimiters
 	OPEN_PAREN       builtins.TOKEN_KIND = "("
 	CLOSE_PAREN      builtins.TOKEN_KIND = ")"
@@ -85,9 +114,20 @@ const (
 	ARROW_TOKEN      builtins.TOKEN_KIND = "->"
 	FAT_ARROW_TOKEN  builtins.TOKEN_KIND = "=>"
 	OPTIONAL_TOKEN   builtins.TOKEN_KIND = "?:"
+	QUESTION_TOKEN   builtins.TOKEN_KIND = "?"
+	//maybe values: a ?? b gives b when a is null, a?.b reads b only when a is not null
+	NULL_COALESCING_TOKEN builtins.TOKEN_KIND = "??"
+	OPTIONAL_CHAIN_TOKEN  builtins.TOKEN_KIND = "?."
 	AT_TOKEN         builtins.TOKEN_KIND = "@"
 	DOLLAR_TOKEN     builtins.TOKEN_KIND = "$"
+	//ranges: a..b excludes b, a..=b includes it
+	DOT_DOT_TOKEN       builtins.TOKEN_KIND = ".."
+	DOT_DOT_EQUAL_TOKEN builtins.TOKEN_KIND = "..="
 	EOF_TOKEN        builtins.TOKEN_KIND = "eof"
+	//string interpolation: "a{x}b{y}c" is lexed as STR_START("a") x STR_MID("b") y STR_END("c")
+	STR_START_TOKEN builtins.TOKEN_KIND = "str_start"
+	STR_MID_TOKEN   builtins.TOKEN_KIND = "str_mid"
+	STR_END_TOKEN   builtins.TOKEN_KIND = "str_end"
 )
 
 var keyWordsMap map[string]builtins.TOKEN_KIND = map[string]builtins.TOKEN_KIND{
@@ -99,7 +139,9 @@ var keyWordsMap map[string]builtins.TOKEN_KIND = map[string]builtins.TOKEN_KIND{
 	"foreach":   FOREACH_TOKEN,
 	"type":      TYPE_TOKEN,
 	"priv":      PRIVATE_TOKEN,
+	"pub":       PUBLIC_TOKEN,
 	"interface": INTERFACE_TOKEN,
+	"enum":      ENUM_TOKEN,
 	"impl":      IMPL_TOKEN,
 	"struct":    STRUCT_TOKEN,
 	"fn":        FUNCTION_TOKEN,
@@ -110,8 +152,25 @@ var keyWordsMap map[string]builtins.TOKEN_KIND = map[string]builtins.TOKEN_KIND{
 	"ret":       RETURN_TOKEN,
 	"in":        IN_TOKEN,
 	"as":        AS_TOKEN,
+	"switch":    SWITCH_TOKEN,
+	"case":      CASE_TOKEN,
+	"default":   DEFAULT_TOKEN,
+	"break":     BREAK_TOKEN,
+	"continue":  CONTINUE_TOKEN,
+	"range":     RANGE_TOKEN,
+	"match":     MATCH_TOKEN,
+	"package":   PACKAGE_TOKEN,
+	"import":    IMPORT_TOKEN,
 }
 
+// STEP_KEYWORD is only a keyword right after a range, like 0..10 step 2. Anywhere else step is an identifier,
+// so it can still be used as a name.
+const STEP_KEYWORD = "step"
+
+// RESULT_KEYWORD is only a keyword where a type is expected, like result{i32, error}. Anywhere else result is an
+// identifier, so it can still be used as a name.
+const RESULT_KEYWORD = "result"
+
 func IsKeyword(token string) bool {
 	if _, ok := keyWordsMap[token]; ok {
 		return true
@@ -22,6 +22,7 @@ func TestIsKeyword(t *testing.T) {
 		{"fn", true},
 		{"ret", true},
 		{"in", true},
+		{"result", false},
 		{"unknown", false},
 	}
 
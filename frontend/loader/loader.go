@@ -0,0 +1,223 @@
+// Package loader finds the packages a program is made of. It parses the files of the entry package,
+// follows their imports to the folders of the imported packages, and orders the packages so that
+// each one comes after the packages it imports. Import cycles are reported here, before type checking.
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+	"walrus/frontend/parser"
+)
+
+// MAIN_PACKAGE is the package name of entry files that do not declare one
+const MAIN_PACKAGE = "main"
+
+// FILE_EXTENSION is the extension of the source files of a package
+const FILE_EXTENSION = ".wal"
+
+// File is a parsed source file of a package
+type File struct {
+	Path string
+	Tree ast.ProgramStmt
+}
+
+// Package is a folder of source files that are checked together and share their top-level declarations.
+// Path is the import path of the package, like "lib.helpers.ds", and is empty for the entry package.
+type Package struct {
+	Name    string
+	Path    string
+	Dir     string
+	Files   []File
+	Imports []*Package // the packages the files import, in the order they are first imported
+}
+
+// Program is every package reachable from the entry package. Packages are ordered so that each one
+// comes after the packages it imports, which puts the entry package last.
+type Program struct {
+	Entry    *Package
+	Packages []*Package
+}
+
+type loader struct {
+	root     string              // folder the import paths are relative to
+	packages map[string]*Package // loaded packages, by folder
+	loading  []*Package          // packages whose imports are being loaded, the importer first
+	order    []*Package
+}
+
+// Load loads the program that starts at entry. Entry is a source file, which is then the only file of the
+// entry package, or a folder whose source files all belong to the entry package. Import paths are resolved
+// to folders relative to the folder of the entry package, with a dot between folder names.
+// Problems in the source files are reported to errgen. The returned error is only about reading entry.
+func Load(entry string) (*Program, error) {
+
+	info, err := os.Stat(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{
+		packages: make(map[string]*Package),
+	}
+
+	var files []string
+	if info.IsDir() {
+		l.root = entry
+		files = sourceFiles(entry)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no %s files found in '%s'", FILE_EXTENSION, entry)
+		}
+	} else {
+		l.root = filepath.Dir(entry)
+		files = []string{entry}
+	}
+
+	pkg := l.load(l.root, "", MAIN_PACKAGE, files)
+
+	return &Program{
+		Entry:    pkg,
+		Packages: l.order,
+	}, nil
+}
+
+// load parses the files of a package, then loads the packages they import
+func (l *loader) load(dir string, importPath string, defaultName string, files []string) *Package {
+
+	pkg := &Package{
+		Name:  defaultName,
+		Path:  importPath,
+		Dir:   dir,
+		Files: make([]File, 0, len(files)),
+	}
+
+	l.packages[dir] = pkg
+
+	for _, filePath := range files {
+		tokens := lexer.Tokenize(filePath, false)
+		tree := parser.NewParser(filePath, tokens).Parse(false).(ast.ProgramStmt)
+		pkg.Files = append(pkg.Files, File{Path: filePath, Tree: tree})
+	}
+
+	checkPackageName(pkg)
+
+	l.loading = append(l.loading, pkg)
+
+	for _, file := range pkg.Files {
+		for _, node := range file.Tree.Contents {
+			importStmt, ok := node.(ast.ImportStmt)
+			if !ok {
+				continue
+			}
+			for _, spec := range importStmt.Imports {
+				l.resolve(pkg, file.Path, spec)
+			}
+		}
+	}
+
+	l.loading = l.loading[:len(l.loading)-1]
+	l.order = append(l.order, pkg)
+
+	return pkg
+}
+
+// resolve finds the package an import refers to, loading it the first time it is imported
+func (l *loader) resolve(importer *Package, filePath string, spec ast.ImportSpec) {
+
+	path := spec.Path.Value
+	start, end := spec.Path.Start, spec.Path.End
+
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" || strings.ContainsAny(segment, `/\`) {
+			errgen.Add(filePath, start.Line, end.Line, start.Column, end.Column, fmt.Sprintf("invalid import path '%s'", path)).Hint("write the folder names with a dot between them, like \"lib.helpers.ds\"").Level(errgen.CRITICAL_ERROR)
+		}
+	}
+
+	dir := filepath.Join(append([]string{l.root}, segments...)...)
+
+	for i, loading := range l.loading {
+		if loading.Dir == dir {
+			errgen.Add(filePath, start.Line, end.Line, start.Column, end.Column, "import cycle not allowed\n"+errgen.TreeFormatString(cycle(l.loading[i:], path)...)).Level(errgen.CRITICAL_ERROR)
+		}
+	}
+
+	pkg, ok := l.packages[dir]
+	if !ok {
+		files := sourceFiles(dir)
+		if len(files) == 0 {
+			errgen.Add(filePath, start.Line, end.Line, start.Column, end.Column, fmt.Sprintf("cannot find package '%s'", path)).Hint(fmt.Sprintf("a package is a folder of %s files, looked up from '%s'", FILE_EXTENSION, l.root)).Level(errgen.CRITICAL_ERROR)
+		}
+		pkg = l.load(dir, path, segments[len(segments)-1], files)
+	}
+
+	for _, imported := range importer.Imports {
+		if imported == pkg {
+			return
+		}
+	}
+	importer.Imports = append(importer.Imports, pkg)
+}
+
+// checkPackageName names the package after the package declaration of its files, which must all agree
+func checkPackageName(pkg *Package) {
+
+	declared := ""
+
+	for _, file := range pkg.Files {
+		if len(file.Tree.Contents) == 0 {
+			continue
+		}
+		packageStmt, ok := file.Tree.Contents[0].(ast.PackageStmt)
+		if !ok {
+			continue
+		}
+		name := packageStmt.Name
+		if declared == "" {
+			declared = name.Name
+			continue
+		}
+		if name.Name != declared {
+			errgen.Add(file.Path, name.Start.Line, name.End.Line, name.Start.Column, name.End.Column, fmt.Sprintf("file is in package '%s', but the other files in '%s' are in package '%s'", name.Name, pkg.Dir, declared)).Level(errgen.NORMAL_ERROR)
+		}
+	}
+
+	if declared != "" {
+		pkg.Name = declared
+	}
+}
+
+// cycle describes an import cycle, from the package that is imported again back to itself
+func cycle(packages []*Package, path string) []string {
+	steps := make([]string, len(packages))
+	for i, pkg := range packages {
+		next := path
+		if i+1 < len(packages) {
+			next = packages[i+1].Path
+		}
+		steps[i] = fmt.Sprintf("'%s' imports '%s'", pkg.Path, next)
+	}
+	return steps
+}
+
+// sourceFiles returns the source files of a folder in the order of their names, or nil when the folder cannot be read
+func sourceFiles(dir string) []string {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	files := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == FILE_EXTENSION {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"walrus/errgen"
+)
+
+// writeFiles creates a folder tree of source files from paths relative to the returned root
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for path, src := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoad(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"main.wal":                `package main; import { "sys", "lib.helpers.ds" as stacks };`,
+		"lib/helpers/ds/a.wal":    `package ds; import "sys";`,
+		"lib/helpers/ds/b.wal":    `fn push() {}`,
+		"sys/print.wal":           `package sys; fn print(s: str) {}`,
+		"unrelated/unrelated.wal": `package unrelated;`,
+	})
+
+	var program *Program
+	var err error
+	diagnostics := errgen.Collect(func() {
+		program, err = Load(filepath.Join(root, "main.wal"))
+	})
+	if err != nil || len(diagnostics) != 0 {
+		t.Fatalf("expected no problems, got %v %v", err, diagnostics)
+	}
+
+	names := make([]string, len(program.Packages))
+	for i, pkg := range program.Packages {
+		names[i] = pkg.Name
+	}
+	if strings.Join(names, " ") != "sys ds main" {
+		t.Errorf("expected the packages in the order 'sys ds main', got %v", names)
+	}
+
+	if program.Entry.Name != MAIN_PACKAGE || len(program.Entry.Imports) != 2 {
+		t.Errorf("expected the entry package to import 2 packages, got %#v", program.Entry)
+	}
+
+	if ds := program.Packages[1]; ds.Path != "lib.helpers.ds" || len(ds.Files) != 2 {
+		t.Errorf("expected both files of lib.helpers.ds, got %#v", ds)
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		message string
+	}{
+		{"missing package", map[string]string{"main.wal": `import "nowhere";`}, "cannot find package 'nowhere'"},
+		{"invalid path", map[string]string{"main.wal": `import "lib..ds";`}, "invalid import path 'lib..ds'"},
+		{"cycle", map[string]string{
+			"main.wal": `import "a";`,
+			"a/a.wal":  `import "b";`,
+			"b/b.wal":  `import "c";`,
+			"c/c.wal":  `import "a";`,
+		}, "import cycle not allowed"},
+		{"package names", map[string]string{
+			"main.wal": `import "a";`,
+			"a/a.wal":  `package a;`,
+			"a/b.wal":  `package b;`,
+		}, "file is in package 'b', but the other files in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeFiles(t, tt.files)
+			diagnostics := errgen.Collect(func() {
+				Load(filepath.Join(root, "main.wal"))
+			})
+			if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Message, tt.message) {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
@@ -81,6 +81,21 @@ func parsePrimaryExpr(p *Parser) ast.Node {
 			IsSigned: builtins.IsSigned(builtins.PARSER_TYPE(primaryToken.Kind)),
 			Location: loc,
 		}
+	case lexer.UNTYPED_INT_TOKEN:
+		return ast.IntegerLiteralExpr{
+			Value:     rawValue,
+			BitSize:   32,
+			IsSigned:  true,
+			IsUntyped: true,
+			Location:  loc,
+		}
+	case lexer.UNTYPED_FLOAT_TOKEN:
+		return ast.FloatLiteralExpr{
+			Value:     rawValue,
+			BitSize:   32,
+			IsUntyped: true,
+			Location:  loc,
+		}
 	case lexer.FLOAT32_TOKEN, lexer.FLOAT64_TOKEN:
 
 		return ast.FloatLiteralExpr{
@@ -89,6 +104,11 @@ func parsePrimaryExpr(p *Parser) ast.Node {
 			Location: loc,
 		}
 
+	case lexer.BYTE_TOKEN:
+		return ast.ByteLiteralExpr{
+			Value:    rawValue,
+			Location: loc,
+		}
 	case lexer.STR_TOKEN:
 		return ast.StringLiteralExpr{
 			Value:    rawValue,
@@ -107,8 +127,60 @@ func parsePrimaryExpr(p *Parser) ast.Node {
 	return nil
 }
 
+// parseInterpolatedStringExpr parses an interpolated string like "Hello {name}!".
+// The lexer splits it into a STR_START token, then each embedded expression followed by a
+// STR_MID or STR_END token carrying the text after it.
+func parseInterpolatedStringExpr(p *Parser) ast.Node {
+
+	start := p.currentToken().Start
+
+	var parts []ast.Node
+
+	addText := func(token lexer.Token) {
+		if token.Value == "" {
+			return
+		}
+		parts = append(parts, ast.StringLiteralExpr{
+			Value: token.Value,
+			Location: ast.Location{
+				Start: token.Start,
+				End:   token.End,
+			},
+		})
+	}
+
+	addText(p.advance())
+
+	for {
+		if p.currentTokenKind() == lexer.STR_MID_TOKEN || p.currentTokenKind() == lexer.STR_END_TOKEN {
+			errgen.Add(p.FilePath, p.currentToken().Start.Line, p.currentToken().End.Line, p.currentToken().Start.Column, p.currentToken().End.Column, "empty expression in string interpolation").Hint("use \\{ to write a literal {").Level(errgen.SYNTAX_ERROR)
+		}
+
+		parts = append(parts, parseExpr(p, DEFAULT_BP))
+
+		token := p.currentToken()
+		if token.Kind != lexer.STR_MID_TOKEN && token.Kind != lexer.STR_END_TOKEN {
+			msg := fmt.Sprintf("expected } to close the embedded expression, got '%s'", token.Value)
+			errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, msg).Level(errgen.SYNTAX_ERROR)
+		}
+
+		addText(p.advance())
+
+		if token.Kind == lexer.STR_END_TOKEN {
+			return ast.InterpolatedStringExpr{
+				Parts: parts,
+				Location: ast.Location{
+					Start: start,
+					End:   token.End,
+				},
+			}
+		}
+	}
+}
+
 // parseGroupingExpr parses a grouping expression enclosed in parentheses.
 // It expects an opening parenthesis, followed by an expression, and a closing parenthesis.
+// When the expression is followed by a comma, the parentheses hold a tuple literal like (a, b) instead.
 // Returns the parsed expression node.
 //
 // Parameters:
@@ -117,10 +189,35 @@ func parsePrimaryExpr(p *Parser) ast.Node {
 // Returns:
 // - ast.Node: The parsed expression node.
 func parseGroupingExpr(p *Parser) ast.Node {
-	p.expect(lexer.OPEN_PAREN)
+	start := p.expect(lexer.OPEN_PAREN).Start
 	expr := parseExpr(p, DEFAULT_BP)
-	p.expect(lexer.CLOSE_PAREN)
-	return expr
+	if p.currentTokenKind() != lexer.COMMA_TOKEN {
+		p.expect(lexer.CLOSE_PAREN)
+		return expr
+	}
+	values := parseTupleValues(p, expr, lexer.CLOSE_PAREN)
+	end := p.expect(lexer.CLOSE_PAREN).End
+	return ast.TupleLiteral{
+		Values: values,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+// parseTupleValues parses the values of a tuple after its first one, up to the closing token.
+// A trailing comma is allowed.
+func parseTupleValues(p *Parser, first ast.Node, closing builtins.TOKEN_KIND) []ast.Node {
+	values := []ast.Node{first}
+	for p.hasToken() && p.currentTokenKind() == lexer.COMMA_TOKEN {
+		p.advance()
+		if p.currentTokenKind() == closing {
+			break
+		}
+		values = append(values, parseExpr(p, ASSIGNMENT_BP))
+	}
+	return values
 }
 
 // parsePostfixExpr parses a postfix expression, which consists of an identifier
@@ -153,6 +250,48 @@ func parsePostfixExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
 	}
 }
 
+// parseTryExpr parses the try operator after a value, like parse(s)?
+// It gives the success value of a result, or returns its error from the enclosing function.
+func parseTryExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
+	operator := p.advance() // eat ?
+	return ast.TryExpr{
+		Argument: left,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   operator.End,
+		},
+	}
+}
+
+// parseUnwrapExpr parses the unwrap operator after a maybe value, like user!
+func parseUnwrapExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
+	operator := p.advance() // eat !
+	return ast.UnwrapExpr{
+		Argument: left,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   operator.End,
+		},
+	}
+}
+
+// parseNullCoalescingExpr parses a ?? b. It groups to the right, so a ?? b ?? c is a ?? (b ?? c).
+func parseNullCoalescingExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
+
+	p.advance() // eat ??
+
+	right := parseExpr(p, bp-1)
+
+	return ast.NullCoalescingExpr{
+		Left:  left,
+		Right: right,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   right.EndPos(),
+		},
+	}
+}
+
 // parsePrefixExpr parses a prefix expression from the input tokens.
 // It expects the current token to be the start of a prefix expression,
 // advances to the operator token, and then expects an identifier token
@@ -203,7 +342,7 @@ func parseUnaryExpr(p *Parser) ast.Node {
 	operator := p.advance()
 
 	switch operator.Kind {
-	case lexer.MINUS_TOKEN, lexer.NOT_TOKEN:
+	case lexer.MINUS_TOKEN, lexer.NOT_TOKEN, lexer.BIT_XOR_TOKEN:
 		break
 	default:
 		errgen.Add(p.FilePath, operator.Start.Line, operator.End.Line, operator.Start.Column, operator.End.Column, fmt.Sprintf("invalid unary operator '%s'", operator.Value)).Level(errgen.SYNTAX_ERROR)
@@ -249,6 +388,52 @@ func parseBinaryExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
 	}
 }
 
+// parseLogicalExpr parses a && b and a || b into a LogicalExpr, keeping them apart from
+// the eagerly evaluated binary operators.
+func parseLogicalExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
+
+	op := p.advance()
+
+	right := parseExpr(p, bp)
+
+	return ast.LogicalExpr{
+		Operator: op,
+		Left:     left,
+		Right:    right,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   right.EndPos(),
+		},
+	}
+}
+
+// parseRangeExpr parses start..end and start..=end with an optional trailing step <expr>.
+func parseRangeExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
+
+	op := p.advance()
+
+	right := parseExpr(p, bp)
+	end := right.EndPos()
+
+	var step ast.Node
+	if p.currentTokenKind() == lexer.IDENTIFIER_TOKEN && p.currentToken().Value == lexer.STEP_KEYWORD {
+		p.advance()
+		step = parseExpr(p, bp)
+		end = step.EndPos()
+	}
+
+	return ast.RangeExpr{
+		Start:     left,
+		End:       right,
+		Inclusive: op.Kind == lexer.DOT_DOT_EQUAL_TOKEN,
+		Step:      step,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   end,
+		},
+	}
+}
+
 func parseTypeCastExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
 	start := left.StartPos()
 	p.expect(lexer.AS_TOKEN)
@@ -21,11 +21,14 @@ func parseLambdaFunction(p *Parser) ast.Node {
 	//annonymous function
 	start := p.advance().Start // eat fn token
 
+	typeParams := parseTypeParams(p)
+
 	params, returnType := parseFunctionSignature(p)
 
 	block := parseBlock(p)
 
 	return ast.FunctionLiteral{
+		TypeParams: typeParams,
 		Params:     params,
 		ReturnType: returnType,
 		Body:       block,
@@ -56,6 +59,8 @@ func parseFunctionDeclStmt(p *Parser) ast.Node {
 
 	nameToken := p.expect(lexer.IDENTIFIER_TOKEN)
 
+	typeParams := parseTypeParams(p)
+
 	params, returnType := parseFunctionSignature(p)
 
 	block := parseBlock(p)
@@ -69,6 +74,7 @@ func parseFunctionDeclStmt(p *Parser) ast.Node {
 			},
 		},
 		FunctionLiteral: ast.FunctionLiteral{
+			TypeParams: typeParams,
 			Params:     params,
 			ReturnType: returnType,
 			Body:       block,
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"errors"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+)
+
+// parseTypeParams parses the optional type parameters of a generic function or type, like <K, V: Comparable>.
+// A parameter may be followed by ':' and the interface its type arguments must satisfy.
+// It returns nil when the parser is not positioned at '<'.
+func parseTypeParams(p *Parser) []ast.TypeParam {
+
+	if p.currentTokenKind() != lexer.LESS_TOKEN {
+		return nil
+	}
+
+	start := p.advance().Start // eat <
+
+	params := make([]ast.TypeParam, 0)
+
+	for p.hasToken() && p.currentTokenKind() != lexer.GREATER_TOKEN {
+		nameToken := p.expectError(lexer.IDENTIFIER_TOKEN, errors.New("expected a type parameter name"))
+
+		param := ast.TypeParam{
+			Identifier: ast.IdentifierExpr{
+				Name: nameToken.Value,
+				Location: ast.Location{
+					Start: nameToken.Start,
+					End:   nameToken.End,
+				},
+			},
+			Location: ast.Location{
+				Start: nameToken.Start,
+				End:   nameToken.End,
+			},
+		}
+
+		if p.currentTokenKind() == lexer.COLON_TOKEN {
+			p.advance()
+			param.Constraint = parseType(p, DEFAULT_BP)
+			param.End = param.Constraint.EndPos()
+		}
+
+		params = append(params, param)
+
+		if p.currentTokenKind() != lexer.GREATER_TOKEN {
+			p.expect(lexer.COMMA_TOKEN)
+		}
+	}
+
+	end := p.expect(lexer.GREATER_TOKEN).End
+
+	if len(params) == 0 {
+		errgen.Add(p.FilePath, start.Line, end.Line, start.Column, end.Column, "expected at least one type parameter").Hint("remove the empty '<>'").Level(errgen.SYNTAX_ERROR)
+	}
+
+	return params
+}
+
+// parseTypeArgs parses the type arguments of a generic type, like <i32, []str>.
+// The parser must be positioned at '<'.
+func parseTypeArgs(p *Parser) ([]ast.DataType, lexer.Position) {
+
+	start := p.expect(lexer.LESS_TOKEN).Start
+
+	args := make([]ast.DataType, 0)
+
+	for p.hasToken() && p.currentTokenKind() != lexer.GREATER_TOKEN && p.currentTokenKind() != lexer.SHIFT_RIGHT_TOKEN {
+		args = append(args, parseType(p, DEFAULT_BP))
+
+		if p.currentTokenKind() != lexer.GREATER_TOKEN && p.currentTokenKind() != lexer.SHIFT_RIGHT_TOKEN {
+			p.expect(lexer.COMMA_TOKEN)
+		}
+	}
+
+	end := expectTypeArgsEnd(p)
+
+	if len(args) == 0 {
+		errgen.Add(p.FilePath, start.Line, end.Line, start.Column, end.Column, "expected at least one type argument").Hint("remove the empty '<>'").Level(errgen.SYNTAX_ERROR)
+	}
+
+	return args, end
+}
+
+// expectTypeArgsEnd expects the '>' that closes a list of type arguments. In nested arguments like Box<Box<i32>>
+// the lexer reads the two closing brackets as '>>', so the token is split and its second half is left for the outer list.
+func expectTypeArgsEnd(p *Parser) lexer.Position {
+
+	token := p.currentToken()
+
+	if token.Kind != lexer.SHIFT_RIGHT_TOKEN {
+		return p.expect(lexer.GREATER_TOKEN).End
+	}
+
+	first := token
+	first.Kind, first.Value = lexer.GREATER_TOKEN, string(lexer.GREATER_TOKEN)
+	first.End = lexer.Position{Line: token.Start.Line, Column: token.Start.Column + 1, Index: token.Start.Index + 1}
+
+	second := first
+	second.Start, second.End = first.End, token.End
+
+	p.tokens[p.index] = second
+
+	return first.End
+}
@@ -17,17 +17,14 @@ func parseImplStmt(p *Parser) ast.Node {
 
 	for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_CURLY {
 
-		IsPrivate := false
-
-		if p.currentTokenKind() == lexer.PRIVATE_TOKEN {
-			IsPrivate = true
-			p.advance()
-		}
+		IsPublic, IsPrivate := parseMemberVisibility(p)
 
 		p.expect(lexer.FUNCTION_TOKEN)
 
 		fnName := p.expect(lexer.IDENTIFIER_TOKEN)
 
+		typeParams := parseTypeParams(p)
+
 		params, ret := parseFunctionSignature(p)
 
 		body := parseBlock(p)
@@ -35,6 +32,7 @@ func parseImplStmt(p *Parser) ast.Node {
 		method := ast.MethodToImplement{
 			IsPrivate: IsPrivate,
 			FunctionDeclStmt: ast.FunctionDeclStmt{
+				IsPublic: IsPublic,
 				Identifier: ast.IdentifierExpr{
 					Name: fnName.Value,
 					Location: ast.Location{
@@ -43,6 +41,7 @@ func parseImplStmt(p *Parser) ast.Node {
 					},
 				},
 				FunctionLiteral: ast.FunctionLiteral{
+					TypeParams: typeParams,
 					Params:     params,
 					ReturnType: ret,
 					Body:       body,
@@ -14,7 +14,10 @@ const (
 	ASSIGNMENT_BP
 	CASTING_BP
 	LOGICAL_BP
+	LOGICAL_AND_BP
 	RELATIONAL_BP
+	NULL_COALESCING_BP
+	RANGE_BP
 	ADDITIVE_BP
 	MULTIPLICATIVE_BP
 	UNARY_BP
@@ -63,10 +66,16 @@ func bindLookupHandlers() {
 	led(lexer.DIV_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
 	led(lexer.MOD_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
 	led(lexer.EXP_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
+	led(lexer.BIT_AND_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
+	led(lexer.BIT_OR_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
+	led(lexer.BIT_XOR_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
+	led(lexer.SHIFT_LEFT_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
+	led(lexer.SHIFT_RIGHT_EQUALS_TOKEN, ASSIGNMENT_BP, parseVarAssignmentExpr)
 
 	led(lexer.OPEN_BRACKET, MEMBER_BP, parseIndexable)
 
 	led(lexer.DOT_TOKEN, MEMBER_BP, parsePropertyExpr)
+	led(lexer.OPTIONAL_CHAIN_TOKEN, MEMBER_BP, parseOptionalPropertyExpr) // a?.b
 	led(lexer.OPEN_PAREN, CALL_BP, parseCallExpr)
 
 	//arithmetics
@@ -77,6 +86,13 @@ func bindLookupHandlers() {
 	led(lexer.MOD_TOKEN, MULTIPLICATIVE_BP, parseBinaryExpr)
 	led(lexer.EXP_TOKEN, MULTIPLICATIVE_BP, parseBinaryExpr)
 
+	//bitwise, with the same precedence as in Go: & and shifts multiply, | and ~ add
+	led(lexer.BIT_AND_TOKEN, MULTIPLICATIVE_BP, parseBinaryExpr)
+	led(lexer.SHIFT_LEFT_TOKEN, MULTIPLICATIVE_BP, parseBinaryExpr)
+	led(lexer.SHIFT_RIGHT_TOKEN, MULTIPLICATIVE_BP, parseBinaryExpr)
+	led(lexer.BIT_OR_TOKEN, ADDITIVE_BP, parseBinaryExpr)
+	led(lexer.BIT_XOR_TOKEN, ADDITIVE_BP, parseBinaryExpr)
+
 	led(lexer.DOUBLE_EQUAL_TOKEN, RELATIONAL_BP, parseBinaryExpr)
 	led(lexer.NOT_EQUAL_TOKEN, RELATIONAL_BP, parseBinaryExpr)
 	led(lexer.LESS_EQUAL_TOKEN, RELATIONAL_BP, parseBinaryExpr)
@@ -84,11 +100,23 @@ func bindLookupHandlers() {
 	led(lexer.GREATER_EQUAL_TOKEN, RELATIONAL_BP, parseBinaryExpr)
 	led(lexer.GREATER_TOKEN, RELATIONAL_BP, parseBinaryExpr)
 
+	//ranges bind looser than arithmetic, so 0..n+1 is 0..(n+1)
+	led(lexer.DOT_DOT_TOKEN, RANGE_BP, parseRangeExpr)       // a..b
+	led(lexer.DOT_DOT_EQUAL_TOKEN, RANGE_BP, parseRangeExpr) // a..=b
+
+	led(lexer.OR_TOKEN, LOGICAL_BP, parseLogicalExpr)      // a || b
+	led(lexer.AND_TOKEN, LOGICAL_AND_BP, parseLogicalExpr) // a && b, binds tighter than ||
+
+	//a ?? b binds tighter than comparisons, so a ?? 0 == 1 is (a ?? 0) == 1
+	led(lexer.NULL_COALESCING_TOKEN, NULL_COALESCING_BP, parseNullCoalescingExpr)
+
 	led(lexer.AS_TOKEN, CASTING_BP, parseTypeCastExpr)
 
 	//Postfix
 	led(lexer.PLUS_PLUS_TOKEN, UNARY_BP, parsePostfixExpr)   // a++
 	led(lexer.MINUS_MINUS_TOKEN, UNARY_BP, parsePostfixExpr) // a--
+	led(lexer.QUESTION_TOKEN, CALL_BP, parseTryExpr)         // a?
+	led(lexer.NOT_TOKEN, CALL_BP, parseUnwrapExpr)           // a!
 
 	nud(lexer.IDENTIFIER_TOKEN, parsePrimaryExpr)  // identifier
 	nud(lexer.INT8_TOKEN, parsePrimaryExpr)        // int literal, 8 bit
@@ -109,14 +137,21 @@ func bindLookupHandlers() {
 	nud(lexer.AT_TOKEN, parseStructLiteral)
 
 	//Unary
-	nud(lexer.MINUS_TOKEN, parseUnaryExpr) // unary minus : -a
-	nud(lexer.NOT_TOKEN, parseUnaryExpr)   // unary not : !a
+	nud(lexer.MINUS_TOKEN, parseUnaryExpr)   // unary minus : -a
+	nud(lexer.NOT_TOKEN, parseUnaryExpr)     // unary not : !a
+	nud(lexer.BIT_XOR_TOKEN, parseUnaryExpr) // bitwise not : ~a
 	//Increment and Decrement
 	//Prefix
 	nud(lexer.PLUS_PLUS_TOKEN, parsePrefixExpr)   // ++a
 	nud(lexer.MINUS_MINUS_TOKEN, parsePrefixExpr) // --a
 
 	nud(lexer.DOLLAR_TOKEN, parseMapLiteral)
+	nud(lexer.MATCH_TOKEN, parseMatchExpr) // match expression
+
+	nud(lexer.STR_START_TOKEN, parseInterpolatedStringExpr) // interpolated string "a{b}c"
+	nud(lexer.BYTE_TOKEN, parsePrimaryExpr)                 // byte literal 'a'
+	nud(lexer.UNTYPED_INT_TOKEN, parsePrimaryExpr)          // int literal without a suffix
+	nud(lexer.UNTYPED_FLOAT_TOKEN, parsePrimaryExpr)        // float literal without a suffix
 
 	//Statements
 	stmt(lexer.LET_TOKEN, parseVarDeclStmt)          // variable declaration
@@ -130,4 +165,10 @@ func bindLookupHandlers() {
 	stmt(lexer.RETURN_TOKEN, parseReturnStmt)         // return statement
 	stmt(lexer.IMPL_TOKEN, parseImplStmt)
 	stmt(lexer.SAFE_TOKEN, parseSafeStmt)
+	stmt(lexer.SWITCH_TOKEN, parseSwitchStmt)
+	stmt(lexer.BREAK_TOKEN, parseBreakStmt)
+	stmt(lexer.CONTINUE_TOKEN, parseContinueStmt)
+	stmt(lexer.PACKAGE_TOKEN, parsePackageStmt)
+	stmt(lexer.IMPORT_TOKEN, parseImportStmt)
+	stmt(lexer.PUBLIC_TOKEN, parsePublicStmt)
 }
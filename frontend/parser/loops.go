@@ -12,7 +12,7 @@ import (
 // - Infinite loop: `for { }`
 // - Condition-only loop: `for i < 10 { }`
 // - Traditional for loop with initialization, condition, and increment: `for i := 0; i < 10; i++ { }`
-// - For-each loop: `for v in arr { }` or `for i, v in arr { }`
+// - For-each loop: `foreach v in arr { }` or `foreach i, v in arr { }`
 //
 // Parameters:
 // - p: A pointer to the Parser instance.
@@ -25,39 +25,32 @@ func parseForStmt(p *Parser) ast.Node {
 
 	if loopType.Kind == lexer.FOR_TOKEN {
 		// empty loop is an infinite loop for {} | detection method: no expressions are present
-		// condition-only loop for condition { } | detection method: only one expression is present
-		// traditional for loop for init; condition; increment { } | detection method: three expressions are present
+		// condition-only loop for condition { } | detection method: the expression is followed by the block
+		// traditional for loop for init; condition; increment { } | detection method: the first clause ends with ';'
 		var init, cond, incr ast.Node
 
-		// check if there is an opening brace
-		if p.currentTokenKind() == lexer.OPEN_CURLY {
+		switch {
+		case p.currentTokenKind() == lexer.OPEN_CURLY:
 			// empty loop
-			block := parseBlock(p)
-			return ast.ForStmt{
-				Init:      nil,
-				Condition: nil,
-				Increment: nil,
-				Block:     block,
-				Location: ast.Location{
-					Start: loopType.Start,
-					End:   block.EndPos(),
-				},
+		case p.currentTokenKind() == lexer.LET_TOKEN:
+			// for let i := 0; ...
+			init = parseVarDeclStmt(p)
+		case p.currentTokenKind() == lexer.IDENTIFIER_TOKEN && p.index+1 < len(p.tokens) && p.tokens[p.index+1].Kind == lexer.WALRUS_TOKEN:
+			// for i := 0; ...
+			init = parseLoopVarDecl(p)
+		default:
+			cond = parseExpr(p, DEFAULT_BP)
+			if p.currentTokenKind() == lexer.SEMI_COLON_TOKEN {
+				// the expression was the init clause, like i = 0
+				p.advance()
+				init, cond = cond, nil
 			}
 		}
 
-		// check if there is an init expression
-		init = parseNode(p)
-
-		// check if there no opening brace, then there is a condition and increment
-		if p.currentTokenKind() != lexer.OPEN_CURLY {
-			fmt.Printf("Current token: %v\n", p.currentTokenKind())
+		if init != nil {
 			cond = parseExpr(p, DEFAULT_BP)
 			p.expect(lexer.SEMI_COLON_TOKEN)
 			incr = parseExpr(p, DEFAULT_BP)
-		} else {
-			// condition-only loop
-			cond = init
-			init = nil
 		}
 
 		// parse the block
@@ -77,30 +70,27 @@ func parseForStmt(p *Parser) ast.Node {
 	} else if loopType.Kind == lexer.FOREACH_TOKEN {
 		// for-each loop foreach v in arr { } | detection method: one identifier 'v' is present
 		// for-each loop foreach i, v in arr { } | detection method: two identifiers 'i' and 'v' are present
-		var first, second ast.Node
-		first = parseExpr(p, ASSIGNMENT_BP)
+		var key *ast.IdentifierExpr
+		value := parseLoopVariable(p)
 		if p.currentTokenKind() == lexer.COMMA_TOKEN {
 			p.advance()
-			second = parseExpr(p, ASSIGNMENT_BP)
-		} else {
-			second = first
-			first = nil
+			first := value
+			key = &first
+			value = parseLoopVariable(p)
 		}
 
 		p.expect(lexer.IN_TOKEN)
 
-		// parse the array expression
-		array := parseExpr(p, ASSIGNMENT_BP)
-
-		p.expect(lexer.OPEN_CURLY)
+		// parse the iterable expression
+		iterable := parseExpr(p, ASSIGNMENT_BP)
 
 		// parse the block
 		block := parseBlock(p)
 
 		return ast.ForEachStmt{
-			Key:      first,
-			Value:    second,
-			Iterable: array,
+			Key:      key,
+			Value:    value,
+			Iterable: iterable,
 			Block:    block,
 			Location: ast.Location{
 				Start: loopType.Start,
@@ -113,3 +103,123 @@ func parseForStmt(p *Parser) ast.Node {
 
 	return nil
 }
+
+// parseLoopVariable parses a variable bound by a foreach loop. It must be a plain identifier.
+func parseLoopVariable(p *Parser) ast.IdentifierExpr {
+	identifier := p.expectError(lexer.IDENTIFIER_TOKEN, fmt.Errorf("foreach loop variables must be identifiers"))
+	return ast.IdentifierExpr{
+		Name: identifier.Value,
+		Location: ast.Location{
+			Start: identifier.Start,
+			End:   identifier.End,
+		},
+	}
+}
+
+// parseLoopVarDecl parses the short declaration `i := 0;` that can start a traditional for loop.
+// It declares a single mutable variable, like `let i := 0;` does.
+func parseLoopVarDecl(p *Parser) ast.Node {
+
+	identifier := p.advance()
+	p.expect(lexer.WALRUS_TOKEN)
+	value := parseExpr(p, ASSIGNMENT_BP)
+	end := p.expect(lexer.SEMI_COLON_TOKEN).End
+
+	return ast.VarDeclStmt{
+		Variables: []ast.VarDeclStmtVar{
+			{
+				Identifier: ast.IdentifierExpr{
+					Name: identifier.Value,
+					Location: ast.Location{
+						Start: identifier.Start,
+						End:   identifier.End,
+					},
+				},
+				Value: value,
+				Location: ast.Location{
+					Start: identifier.Start,
+					End:   value.EndPos(),
+				},
+			},
+		},
+		Location: ast.Location{
+			Start: identifier.Start,
+			End:   end,
+		},
+	}
+}
+
+// parseLabeledLoop parses a loop with a label in front of it, like `outer: for { }`.
+// The label lets break and continue statements in nested loops refer to this loop.
+func parseLabeledLoop(p *Parser) ast.Node {
+
+	label := p.advance() // eat the label
+	p.expect(lexer.COLON_TOKEN)
+
+	switch p.currentTokenKind() {
+	case lexer.FOR_TOKEN, lexer.FOREACH_TOKEN:
+	default:
+		token := p.currentToken()
+		errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, fmt.Sprintf("label '%s' must be followed by a loop", label.Value)).Level(errgen.SYNTAX_ERROR)
+	}
+
+	switch loop := parseForStmt(p).(type) {
+	case ast.ForStmt:
+		loop.Label = label.Value
+		loop.Start = label.Start
+		return loop
+	case ast.ForEachStmt:
+		loop.Label = label.Value
+		loop.Start = label.Start
+		return loop
+	}
+
+	return nil
+}
+
+// parseBreakStmt parses `break;` or `break label;`
+func parseBreakStmt(p *Parser) ast.Node {
+	start := p.advance().Start // eat break token
+	label := parseLoopLabel(p)
+	end := p.expect(lexer.SEMI_COLON_TOKEN).End
+
+	return ast.BreakStmt{
+		Label: label,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+// parseContinueStmt parses `continue;` or `continue label;`
+func parseContinueStmt(p *Parser) ast.Node {
+	start := p.advance().Start // eat continue token
+	label := parseLoopLabel(p)
+	end := p.expect(lexer.SEMI_COLON_TOKEN).End
+
+	return ast.ContinueStmt{
+		Label: label,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+// parseLoopLabel parses the optional label after break or continue. It returns nil if there is none.
+func parseLoopLabel(p *Parser) *ast.IdentifierExpr {
+	if p.currentTokenKind() != lexer.IDENTIFIER_TOKEN {
+		return nil
+	}
+
+	label := p.advance()
+
+	return &ast.IdentifierExpr{
+		Name: label.Value,
+		Location: ast.Location{
+			Start: label.Start,
+			End:   label.End,
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+)
+
+// parseMatchExpr parses a match expression from the input and returns an AST node representing it.
+// It expects the parser to be positioned at the 'match' token.
+//
+// The structure of a match expression is as follows:
+//
+//	match <expression> {
+//	    case <pattern> => <value>,
+//	    case <pattern> if <guard> => <value>,
+//	}
+//
+// Patterns are parsed as expressions, so Shape.Circle(r), 1..10 and @Point{ x: 0, y: y } are all read
+// the same way as anywhere else. The typechecker decides what each of them matches.
+//
+// Parameters:
+// - p: A pointer to the Parser instance.
+//
+// Returns:
+// - An ast.Node representing the parsed match expression.
+func parseMatchExpr(p *Parser) ast.Node {
+
+	start := p.advance().Start // eat match token
+
+	subject := parseExpr(p, ASSIGNMENT_BP)
+
+	p.expect(lexer.OPEN_CURLY)
+
+	var cases []ast.MatchCase
+
+	for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_CURLY {
+
+		caseStart := p.expect(lexer.CASE_TOKEN).Start
+
+		pattern := parseExpr(p, COMMA_BP)
+
+		var guard ast.Node
+		if p.currentTokenKind() == lexer.IF_TOKEN {
+			p.advance() // eat if token
+			guard = parseExpr(p, COMMA_BP)
+		}
+
+		p.expect(lexer.FAT_ARROW_TOKEN)
+
+		value := parseExpr(p, COMMA_BP)
+
+		cases = append(cases, ast.MatchCase{
+			Pattern: pattern,
+			Guard:   guard,
+			Value:   value,
+			Location: ast.Location{
+				Start: caseStart,
+				End:   value.EndPos(),
+			},
+		})
+
+		if p.currentTokenKind() != lexer.CLOSE_CURLY {
+			p.expect(lexer.COMMA_TOKEN)
+		}
+	}
+
+	end := p.expect(lexer.CLOSE_CURLY).End
+
+	return ast.MatchExpr{
+		Subject: subject,
+		Cases:   cases,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"errors"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+)
+
+// parsePackageStmt parses the package declaration of a file, like package main;
+// It must be the first statement of the file.
+func parsePackageStmt(p *Parser) ast.Node {
+
+	token := p.advance() // eat package token
+
+	if p.index != 1 {
+		errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, "the package declaration must be the first statement of the file").Level(errgen.SYNTAX_ERROR)
+	}
+
+	nameToken := p.expectError(lexer.IDENTIFIER_TOKEN, errors.New("expected a package name"))
+
+	end := p.expect(lexer.SEMI_COLON_TOKEN).End
+
+	return ast.PackageStmt{
+		Name: ast.IdentifierExpr{
+			Name: nameToken.Value,
+			Location: ast.Location{
+				Start: nameToken.Start,
+				End:   nameToken.End,
+			},
+		},
+		Location: ast.Location{
+			Start: token.Start,
+			End:   end,
+		},
+	}
+}
+
+// parseImportStmt parses an import statement. It imports a single package, import "sys";
+// or a list of them in curly braces, import { "sys", "lib.helpers.ds" as ds };
+func parseImportStmt(p *Parser) ast.Node {
+
+	start := p.advance().Start // eat import token
+
+	imports := make([]ast.ImportSpec, 0)
+
+	if p.currentTokenKind() == lexer.OPEN_CURLY {
+		p.advance()
+		for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_CURLY {
+			imports = append(imports, parseImportSpec(p))
+			if p.currentTokenKind() != lexer.CLOSE_CURLY {
+				p.expect(lexer.COMMA_TOKEN)
+			}
+		}
+		closing := p.expect(lexer.CLOSE_CURLY)
+		if len(imports) == 0 {
+			errgen.Add(p.FilePath, start.Line, closing.End.Line, start.Column, closing.End.Column, "expected at least one package to import").Hint("remove the empty import").Level(errgen.SYNTAX_ERROR)
+		}
+	} else {
+		imports = append(imports, parseImportSpec(p))
+	}
+
+	end := p.expect(lexer.SEMI_COLON_TOKEN).End
+
+	return ast.ImportStmt{
+		Imports: imports,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+// parseImportSpec parses the path of an imported package and its optional alias, like "lib.helpers.ds" as ds
+func parseImportSpec(p *Parser) ast.ImportSpec {
+
+	pathToken := p.expectError(lexer.STR_TOKEN, errors.New("expected the path of a package as a string, like \"lib.helpers.ds\""))
+
+	if pathToken.Value == "" {
+		errgen.Add(p.FilePath, pathToken.Start.Line, pathToken.End.Line, pathToken.Start.Column, pathToken.End.Column, "the path of an imported package cannot be empty").Level(errgen.SYNTAX_ERROR)
+	}
+
+	spec := ast.ImportSpec{
+		Path: ast.StringLiteralExpr{
+			Value: pathToken.Value,
+			Location: ast.Location{
+				Start: pathToken.Start,
+				End:   pathToken.End,
+			},
+		},
+		Location: ast.Location{
+			Start: pathToken.Start,
+			End:   pathToken.End,
+		},
+	}
+
+	if p.currentTokenKind() == lexer.AS_TOKEN {
+		p.advance()
+		aliasToken := p.expectError(lexer.IDENTIFIER_TOKEN, errors.New("expected a name for the imported package"))
+		spec.Alias = &ast.IdentifierExpr{
+			Name: aliasToken.Value,
+			Location: ast.Location{
+				Start: aliasToken.Start,
+				End:   aliasToken.End,
+			},
+		}
+		spec.End = aliasToken.End
+	}
+
+	return spec
+}
+
+// parsePublicStmt parses a declaration marked pub, which other packages can then use.
+// Functions, variables, constants and types can be pub.
+func parsePublicStmt(p *Parser) ast.Node {
+
+	token := p.advance() // eat pub token
+
+	switch p.currentTokenKind() {
+	case lexer.FUNCTION_TOKEN, lexer.LET_TOKEN, lexer.CONST_TOKEN, lexer.TYPE_TOKEN:
+	default:
+		errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, "only functions, variables, constants and types can be pub").Level(errgen.SYNTAX_ERROR)
+	}
+
+	switch node := parseNode(p).(type) {
+	case ast.FunctionDeclStmt:
+		node.IsPublic = true
+		return node
+	case ast.VarDeclStmt:
+		node.IsPublic = true
+		node.Start = token.Start
+		return node
+	case ast.TypeDeclStmt:
+		node.IsPublic = true
+		node.Start = token.Start
+		return node
+	default:
+		return node
+	}
+}
+
+// parseMemberVisibility parses the optional pub or priv before a struct property or a method.
+// A pub member can be used from other packages, and a priv one only by the methods of its type.
+func parseMemberVisibility(p *Parser) (isPublic bool, isPrivate bool) {
+
+	switch p.currentTokenKind() {
+	case lexer.PUBLIC_TOKEN:
+		isPublic = true
+	case lexer.PRIVATE_TOKEN:
+		isPrivate = true
+	default:
+		return false, false
+	}
+
+	p.advance()
+
+	if kind := p.currentTokenKind(); kind == lexer.PUBLIC_TOKEN || kind == lexer.PRIVATE_TOKEN {
+		token := p.currentToken()
+		errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, "a member is either pub or priv").Level(errgen.SYNTAX_ERROR)
+	}
+
+	return isPublic, isPrivate
+}
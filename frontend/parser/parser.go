@@ -77,6 +77,11 @@ func parseNode(p *Parser) ast.Node {
 		return stmt_fn(p)
 	}
 
+	// label: for ... { }
+	if p.currentTokenKind() == lexer.IDENTIFIER_TOKEN && p.index+1 < len(p.tokens) && p.tokens[p.index+1].Kind == lexer.COLON_TOKEN {
+		return parseLabeledLoop(p)
+	}
+
 	// if not a statement, then it must be an expression
 	expr := parseExpr(p, DEFAULT_BP)
 
@@ -85,9 +90,8 @@ func parseNode(p *Parser) ast.Node {
 	return expr
 }
 
-func (p *Parser) Parse(saveJson bool) ast.Node {
-
-	utils.GREEN.Printf("Parsing %s\n", p.FilePath)
+// parseProgram parses every statement up to the end of the token stream.
+func (p *Parser) parseProgram() ast.ProgramStmt {
 
 	var contents []ast.Node
 
@@ -96,9 +100,16 @@ func (p *Parser) Parse(saveJson bool) ast.Node {
 		contents = append(contents, stmt)
 	}
 
-	program := ast.ProgramStmt{
+	return ast.ProgramStmt{
 		Contents: contents,
 	}
+}
+
+func (p *Parser) Parse(saveJson bool) ast.Node {
+
+	utils.GREEN.Printf("Parsing %s\n", p.FilePath)
+
+	program := p.parseProgram()
 
 	if saveJson {
 		file, err := os.Create(strings.TrimSuffix(p.FilePath, filepath.Ext(p.FilePath)) + ".json")
@@ -139,3 +150,24 @@ func NewParser(filePath string, tokens []lexer.Token) *Parser {
 	}
 	return parser
 }
+
+// ParseSource tokenizes and parses source code that is already in memory, such as an editor buffer.
+// The name is used as the file path of the diagnostics. Nothing is read from disk or printed, and
+// errors are returned as diagnostics instead of stopping the program.
+// The tree is nil if lexing or parsing stopped on an error. As with lexer.TokenizeSource, the buffer must be
+// registered with errgen.RegisterSource for errgen.Render to show the lines of the diagnostics.
+func ParseSource(name string, src []byte) ([]lexer.Token, ast.Node, []errgen.Diagnostic) {
+
+	tokens, diagnostics := lexer.TokenizeSource(name, src)
+	if tokens == nil {
+		return nil, nil, diagnostics
+	}
+
+	var tree ast.Node
+
+	diagnostics = append(diagnostics, errgen.Collect(func() {
+		tree = NewParser(name, tokens).parseProgram()
+	})...)
+
+	return tokens, tree, diagnostics
+}
@@ -0,0 +1,686 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/builtins"
+)
+
+func TestParseSource(t *testing.T) {
+	tokens, tree, diagnostics := ParseSource("buffer.wal", []byte("let a := 10;\nfn add(x: i32) -> i32 { ret x + a; }"))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	if len(tokens) == 0 {
+		t.Fatalf("expected tokens")
+	}
+
+	program, ok := tree.(ast.ProgramStmt)
+	if !ok {
+		t.Fatalf("expected a program, got %T", tree)
+	}
+
+	if len(program.Contents) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Contents))
+	}
+
+	if _, ok := program.Contents[1].(ast.FunctionDeclStmt); !ok {
+		t.Errorf("expected a function declaration, got %T", program.Contents[1])
+	}
+}
+
+func TestParseSourceSyntaxError(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte("let a := 10 +;"))
+
+	if tree != nil {
+		t.Errorf("expected no tree, got %v", tree)
+	}
+
+	if len(diagnostics) != 1 || diagnostics[0].Level != errgen.SYNTAX_ERROR {
+		t.Fatalf("expected 1 syntax error, got %v", diagnostics)
+	}
+
+	if diagnostics[0].LineStart != 1 || diagnostics[0].ColStart != 14 {
+		t.Errorf("unexpected diagnostic position %v", diagnostics[0])
+	}
+}
+
+func TestRenderInMemorySyntaxError(t *testing.T) {
+	src := []byte("let a := 10;\nlet b := a +;")
+	errgen.RegisterSource("unsaved.wal", src)
+	defer errgen.UnregisterSource("unsaved.wal")
+
+	_, _, diagnostics := ParseSource("unsaved.wal", src)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diagnostics)
+	}
+
+	rendered, err := errgen.Render(diagnostics[0])
+	if err != nil {
+		t.Fatalf("expected the diagnostic to render from memory, got %v", err)
+	}
+
+	if !strings.HasPrefix(rendered, "2 | let b := a +;\n") || !strings.Contains(rendered, "at: unsaved.wal:2:") {
+		t.Errorf("expected the line of the in-memory file, got %q", rendered)
+	}
+}
+
+func TestParseEscapedLiterals(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let s := "say \"hi\"\n"; let b := '\'';`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	program := tree.(ast.ProgramStmt)
+
+	str, ok := program.Contents[0].(ast.VarDeclStmt).Variables[0].Value.(ast.StringLiteralExpr)
+	if !ok || str.Value != "say \"hi\"\n" {
+		t.Errorf("expected the decoded string, got %#v", program.Contents[0].(ast.VarDeclStmt).Variables[0].Value)
+	}
+
+	b, ok := program.Contents[1].(ast.VarDeclStmt).Variables[0].Value.(ast.ByteLiteralExpr)
+	if !ok || b.Value != "'" {
+		t.Errorf("expected a byte literal, got %#v", program.Contents[1].(ast.VarDeclStmt).Variables[0].Value)
+	}
+}
+
+func TestParseInterpolatedString(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let s := "Hello {user.name}, you are {age + 1} years old";`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	value := tree.(ast.ProgramStmt).Contents[0].(ast.VarDeclStmt).Variables[0].Value
+
+	str, ok := value.(ast.InterpolatedStringExpr)
+	if !ok {
+		t.Fatalf("expected an interpolated string, got %T", value)
+	}
+
+	if len(str.Parts) != 5 {
+		t.Fatalf("expected 5 parts, got %d", len(str.Parts))
+	}
+
+	if _, ok := str.Parts[1].(ast.StructPropertyAccessExpr); !ok {
+		t.Errorf("expected a property access, got %T", str.Parts[1])
+	}
+
+	if _, ok := str.Parts[3].(ast.BinaryExpr); !ok {
+		t.Errorf("expected a binary expression, got %T", str.Parts[3])
+	}
+
+	if text, ok := str.Parts[4].(ast.StringLiteralExpr); !ok || text.Value != " years old" {
+		t.Errorf("expected the trailing text, got %#v", str.Parts[4])
+	}
+}
+
+func TestParseEmptyInterpolation(t *testing.T) {
+	_, _, diagnostics := ParseSource("buffer.wal", []byte(`let s := "a{}b";`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "empty expression in string interpolation" {
+		t.Fatalf("expected an empty interpolation error, got %v", diagnostics)
+	}
+}
+
+func TestParseSuffixedLiterals(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let a := 255u8, b := 3.0f64, c := 'c', d := 7;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	variables := tree.(ast.ProgramStmt).Contents[0].(ast.VarDeclStmt).Variables
+
+	if a, ok := variables[0].Value.(ast.IntegerLiteralExpr); !ok || a.BitSize != 8 || a.IsSigned || a.IsUntyped {
+		t.Errorf("expected an unsigned 8 bit integer, got %#v", variables[0].Value)
+	}
+
+	if b, ok := variables[1].Value.(ast.FloatLiteralExpr); !ok || b.BitSize != 64 {
+		t.Errorf("expected a 64 bit float, got %#v", variables[1].Value)
+	}
+
+	if _, ok := variables[2].Value.(ast.ByteLiteralExpr); !ok {
+		t.Errorf("expected a byte literal, got %#v", variables[2].Value)
+	}
+
+	if d, ok := variables[3].Value.(ast.IntegerLiteralExpr); !ok || !d.IsUntyped {
+		t.Errorf("expected an untyped integer, got %#v", variables[3].Value)
+	}
+}
+
+func TestParseLogicalPrecedence(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let a := x || y && z < 1;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	value := tree.(ast.ProgramStmt).Contents[0].(ast.VarDeclStmt).Variables[0].Value
+
+	or, ok := value.(ast.LogicalExpr)
+	if !ok || or.Operator.Value != "||" {
+		t.Fatalf("expected || at the root, got %#v", value)
+	}
+
+	and, ok := or.Right.(ast.LogicalExpr)
+	if !ok || and.Operator.Value != "&&" {
+		t.Fatalf("expected && on the right of ||, got %#v", or.Right)
+	}
+
+	if _, ok := and.Right.(ast.BinaryExpr); !ok {
+		t.Errorf("expected the comparison to bind tighter than &&, got %#v", and.Right)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let r := 0..=n + 1 step 2;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	value := tree.(ast.ProgramStmt).Contents[0].(ast.VarDeclStmt).Variables[0].Value
+
+	rangeExpr, ok := value.(ast.RangeExpr)
+	if !ok || !rangeExpr.Inclusive {
+		t.Fatalf("expected an inclusive range at the root, got %#v", value)
+	}
+
+	if _, ok := rangeExpr.End.(ast.BinaryExpr); !ok {
+		t.Errorf("expected the addition to bind tighter than the range, got %#v", rangeExpr.End)
+	}
+
+	if rangeExpr.Step == nil {
+		t.Errorf("expected a step")
+	}
+}
+
+func TestParseStepAsName(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let step := 2;
+	let r := 0..10 step step;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	if name := contents[0].(ast.VarDeclStmt).Variables[0].Identifier.Name; name != "step" {
+		t.Errorf("expected a variable named step, got %q", name)
+	}
+
+	rangeExpr := contents[1].(ast.VarDeclStmt).Variables[0].Value.(ast.RangeExpr)
+	if step, ok := rangeExpr.Step.(ast.IdentifierExpr); !ok || step.Name != "step" {
+		t.Errorf("expected the variable step as the step, got %#v", rangeExpr.Step)
+	}
+}
+
+func TestParseResultAsName(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let result := 1;
+	let r: result{i32, error} = result;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	if name := contents[0].(ast.VarDeclStmt).Variables[0].Identifier.Name; name != "result" {
+		t.Errorf("expected a variable named result, got %q", name)
+	}
+
+	variable := contents[1].(ast.VarDeclStmt).Variables[0]
+	if _, ok := variable.ExplicitType.(ast.ResultType); !ok {
+		t.Errorf("expected a result type, got %#v", variable.ExplicitType)
+	}
+	if value, ok := variable.Value.(ast.IdentifierExpr); !ok || value.Name != "result" {
+		t.Errorf("expected the variable result as the value, got %#v", variable.Value)
+	}
+}
+
+func TestParseBitwisePrecedence(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let a := x | y & z << 2 == 0;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	value := tree.(ast.ProgramStmt).Contents[0].(ast.VarDeclStmt).Variables[0].Value
+
+	// ((x | ((y & z) << 2)) == 0)
+	compare, ok := value.(ast.BinaryExpr)
+	if !ok || compare.Operator.Value != "==" {
+		t.Fatalf("expected == at the root, got %#v", value)
+	}
+
+	or, ok := compare.Left.(ast.BinaryExpr)
+	if !ok || or.Operator.Value != "|" {
+		t.Fatalf("expected | below ==, got %#v", compare.Left)
+	}
+
+	shift, ok := or.Right.(ast.BinaryExpr)
+	if !ok || shift.Operator.Value != "<<" {
+		t.Fatalf("expected << on the right of |, got %#v", or.Right)
+	}
+
+	if and, ok := shift.Left.(ast.BinaryExpr); !ok || and.Operator.Value != "&" {
+		t.Errorf("expected & to be shifted, got %#v", shift.Left)
+	}
+}
+
+func TestParseSwitch(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`switch a { case 1, 2: { b = 1; } case 3: {} default: {} }`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	switchStmt, ok := tree.(ast.ProgramStmt).Contents[0].(ast.SwitchStmt)
+	if !ok {
+		t.Fatalf("expected a switch statement, got %T", tree.(ast.ProgramStmt).Contents[0])
+	}
+
+	if len(switchStmt.Cases) != 2 || len(switchStmt.Cases[0].Values) != 2 || len(switchStmt.Cases[0].Block.Contents) != 1 {
+		t.Errorf("unexpected cases %#v", switchStmt.Cases)
+	}
+
+	if switchStmt.Default == nil {
+		t.Errorf("expected a default arm")
+	}
+}
+
+func TestParseSwitchMultipleDefaults(t *testing.T) {
+	_, _, diagnostics := ParseSource("buffer.wal", []byte(`switch a { default: {} default: {} }`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "multiple default cases in switch" || diagnostics[0].ColStart != 24 {
+		t.Fatalf("expected a multiple default error, got %v", diagnostics)
+	}
+}
+
+func TestParseLabeledLoop(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`outer: for { for { break outer; } continue; }`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	loop, ok := tree.(ast.ProgramStmt).Contents[0].(ast.ForStmt)
+	if !ok || loop.Label != "outer" || loop.Start.Column != 1 {
+		t.Fatalf("expected a loop labeled 'outer', got %#v", tree.(ast.ProgramStmt).Contents[0])
+	}
+
+	inner := loop.Block.Contents[0].(ast.ForStmt)
+	if brk, ok := inner.Block.Contents[0].(ast.BreakStmt); !ok || brk.Label == nil || brk.Label.Name != "outer" {
+		t.Errorf("expected 'break outer', got %#v", inner.Block.Contents[0])
+	}
+
+	if cont, ok := loop.Block.Contents[1].(ast.ContinueStmt); !ok || cont.Label != nil {
+		t.Errorf("expected 'continue' without a label, got %#v", loop.Block.Contents[1])
+	}
+}
+
+func TestParseForForms(t *testing.T) {
+	tests := []struct {
+		name             string
+		src              string
+		init, cond, incr bool
+	}{
+		{"infinite", "for {}", false, false, false},
+		{"condition only", "for i < 10 {}", false, true, false},
+		{"short declaration", "for i := 0; i < 10; i++ {}", true, true, true},
+		{"let declaration", "for let i: u8 = 0; i < 10; i += 2 {}", true, true, true},
+		{"assignment", "for i = 0; i < 10; i = i + 1 {}", true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, tree, diagnostics := ParseSource("buffer.wal", []byte(tt.src))
+			if len(diagnostics) != 0 {
+				t.Fatalf("expected no diagnostics, got %v", diagnostics)
+			}
+
+			loop, ok := tree.(ast.ProgramStmt).Contents[0].(ast.ForStmt)
+			if !ok {
+				t.Fatalf("expected a for loop, got %T", tree.(ast.ProgramStmt).Contents[0])
+			}
+
+			if (loop.Init != nil) != tt.init || (loop.Condition != nil) != tt.cond || (loop.Increment != nil) != tt.incr {
+				t.Errorf("unexpected clauses %#v", loop)
+			}
+		})
+	}
+}
+
+func TestParseForEach(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`foreach i, v in arr { } foreach v in arr { }`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	both := contents[0].(ast.ForEachStmt)
+	if both.Key == nil || both.Key.Name != "i" || both.Value.Name != "v" {
+		t.Errorf("expected key 'i' and value 'v', got %#v", both)
+	}
+
+	valueOnly := contents[1].(ast.ForEachStmt)
+	if valueOnly.Key != nil || valueOnly.Value.Name != "v" {
+		t.Errorf("expected only value 'v', got %#v", valueOnly)
+	}
+}
+
+func TestParseForEachVariables(t *testing.T) {
+	_, _, diagnostics := ParseSource("buffer.wal", []byte(`foreach a.b in arr { }`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "unexpected token '.' found. expected 'in'" {
+		t.Fatalf("expected an error at the property access, got %v", diagnostics)
+	}
+
+	_, _, diagnostics = ParseSource("buffer.wal", []byte(`foreach 1 in arr { }`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "foreach loop variables must be identifiers" {
+		t.Fatalf("expected a loop variable error, got %v", diagnostics)
+	}
+}
+
+func TestParseEnum(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`type Shape enum { Circle(radius: f32), Rect(w: f32, h: f32), Empty };`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	enum, ok := tree.(ast.ProgramStmt).Contents[0].(ast.TypeDeclStmt).UDTypeValue.(ast.EnumType)
+	if !ok || len(enum.Variants) != 3 {
+		t.Fatalf("expected an enum with 3 variants, got %#v", enum)
+	}
+
+	fields := []int{1, 2, 0}
+	for i, variant := range enum.Variants {
+		if len(variant.Fields) != fields[i] {
+			t.Errorf("expected variant '%s' to have %d fields, got %d", variant.Identifier.Name, fields[i], len(variant.Fields))
+		}
+	}
+
+	_, _, diagnostics = ParseSource("buffer.wal", []byte(`type Shape enum { Empty() };`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "enum variant 'Empty' has no fields" {
+		t.Fatalf("expected an empty payload error, got %v", diagnostics)
+	}
+}
+
+func TestParseMatch(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let a := match s {
+		case Shape.Circle(r) if r > 1 => r,
+		case 1..10 => 2,
+		case _ => 0,
+	};`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	match, ok := tree.(ast.ProgramStmt).Contents[0].(ast.VarDeclStmt).Variables[0].Value.(ast.MatchExpr)
+	if !ok || len(match.Cases) != 3 {
+		t.Fatalf("expected a match with 3 cases, got %#v", match)
+	}
+
+	if _, ok := match.Cases[0].Pattern.(ast.FunctionCallExpr); !ok || match.Cases[0].Guard == nil {
+		t.Errorf("expected a variant pattern with a guard, got %#v", match.Cases[0])
+	}
+
+	if _, ok := match.Cases[1].Pattern.(ast.RangeExpr); !ok || match.Cases[1].Guard != nil {
+		t.Errorf("expected a range pattern without a guard, got %#v", match.Cases[1])
+	}
+}
+
+func TestParseGenerics(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`fn largest<T: Comparable>(xs: []T) -> maybe{T} { ret xs[0]; }
+	type Pair<K, V> struct { first: K, second: V };
+	let p: Box<Box<i32>> = @Pair<i32, str>{first: 1, second: "a"};`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	fn := contents[0].(ast.FunctionDeclStmt)
+	if len(fn.TypeParams) != 1 || fn.TypeParams[0].Identifier.Name != "T" || fn.TypeParams[0].Constraint == nil {
+		t.Errorf("expected a constrained type parameter T, got %#v", fn.TypeParams)
+	}
+
+	if params := contents[1].(ast.TypeDeclStmt).TypeParams; len(params) != 2 || params[1].Constraint != nil {
+		t.Errorf("expected type parameters K and V, got %#v", params)
+	}
+
+	variable := contents[2].(ast.VarDeclStmt).Variables[0]
+
+	// the closing '>>' of nested type arguments is split in two
+	outer := variable.ExplicitType.(ast.UserDefinedType)
+	if len(outer.TypeArgs) != 1 || len(outer.TypeArgs[0].(ast.UserDefinedType).TypeArgs) != 1 {
+		t.Errorf("expected Box<Box<i32>>, got %#v", outer)
+	}
+
+	if literal := variable.Value.(ast.StructLiteral); len(literal.TypeArgs) != 2 {
+		t.Errorf("expected a struct literal with 2 type arguments, got %#v", literal.TypeArgs)
+	}
+}
+
+func TestParseTuples(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`fn divmod(a: i32, b: i32) -> (i32, i32) { ret a / b, a % b; }
+	let (q, r) := divmod(7, 2), {name, age} := person, [first, _] := xs;
+	let t: (i32, str) = (1, "one");`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	fn := contents[0].(ast.FunctionDeclStmt)
+	if returnType, ok := fn.ReturnType.(ast.TupleType); !ok || len(returnType.ElementTypes) != 2 {
+		t.Errorf("expected a tuple return type, got %#v", fn.ReturnType)
+	}
+	if value, ok := fn.Body.Contents[0].(ast.ReturnStmt).Value.(ast.TupleLiteral); !ok || len(value.Values) != 2 {
+		t.Errorf("expected ret to return a tuple of 2 values, got %#v", fn.Body.Contents[0])
+	}
+
+	kinds := []builtins.PARSER_TYPE{builtins.TUPLE, builtins.STRUCT, builtins.ARRAY}
+	for i, variable := range contents[1].(ast.VarDeclStmt).Variables {
+		if variable.Pattern == nil || variable.Pattern.Kind != kinds[i] || len(variable.Pattern.Names) != 2 {
+			t.Errorf("expected a %s pattern with 2 names, got %#v", kinds[i], variable.Pattern)
+		}
+	}
+
+	variable := contents[2].(ast.VarDeclStmt).Variables[0]
+	if _, ok := variable.Value.(ast.TupleLiteral); !ok || variable.Pattern != nil {
+		t.Errorf("expected a tuple literal, got %#v", variable.Value)
+	}
+
+	_, _, diagnostics = ParseSource("buffer.wal", []byte(`let t: (i32) = 1;`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "a tuple type needs at least two elements" {
+		t.Fatalf("expected a tuple type error, got %v", diagnostics)
+	}
+}
+
+func TestParsePackages(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`package main;
+	import { "sys", "lib.helpers.ds" as stacks, };
+	import "geo";
+	let s: stacks.Stack<i32> = @stacks.Stack<i32>{items: []};`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	if pkg, ok := contents[0].(ast.PackageStmt); !ok || pkg.Name.Name != "main" {
+		t.Errorf("expected package main, got %#v", contents[0])
+	}
+
+	imports := contents[1].(ast.ImportStmt).Imports
+	if len(imports) != 2 || imports[0].Name() != "sys" || imports[1].Path.Value != "lib.helpers.ds" || imports[1].Name() != "stacks" {
+		t.Errorf("expected imports of sys and lib.helpers.ds as stacks, got %#v", imports)
+	}
+
+	if imports := contents[2].(ast.ImportStmt).Imports; len(imports) != 1 || imports[0].Name() != "geo" {
+		t.Errorf("expected an import of geo, got %#v", imports)
+	}
+
+	variable := contents[3].(ast.VarDeclStmt).Variables[0]
+	if explicit, ok := variable.ExplicitType.(ast.UserDefinedType); !ok || explicit.Package != "stacks" || explicit.AliasName != "Stack" {
+		t.Errorf("expected the type stacks.Stack, got %#v", variable.ExplicitType)
+	}
+	if literal, ok := variable.Value.(ast.StructLiteral); !ok || literal.Package == nil || literal.Package.Name != "stacks" || literal.Identifier.Name != "Stack" {
+		t.Errorf("expected a literal of stacks.Stack, got %#v", variable.Value)
+	}
+
+	_, _, diagnostics = ParseSource("buffer.wal", []byte(`let a := 1; package main;`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "the package declaration must be the first statement of the file" {
+		t.Fatalf("expected a package position error, got %v", diagnostics)
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`pub fn f() {}
+	pub let a := 1;
+	pub type P struct { pub x: i32, priv y: i32, z: i32 };`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	if fn, ok := contents[0].(ast.FunctionDeclStmt); !ok || !fn.IsPublic {
+		t.Errorf("expected a pub function, got %#v", contents[0])
+	}
+	if decl, ok := contents[1].(ast.VarDeclStmt); !ok || !decl.IsPublic {
+		t.Errorf("expected a pub variable, got %#v", contents[1])
+	}
+
+	typeDecl := contents[2].(ast.TypeDeclStmt)
+	props := typeDecl.UDTypeValue.(ast.StructType).Properties
+	if !typeDecl.IsPublic || !props[0].IsPublic || !props[1].IsPrivate || props[2].IsPublic || props[2].IsPrivate {
+		t.Errorf("expected a pub type with a pub, a priv and a plain property, got %#v", typeDecl)
+	}
+
+	_, _, diagnostics = ParseSource("buffer.wal", []byte(`type Q struct { pub priv x: i32 };`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "a member is either pub or priv" {
+		t.Fatalf("expected a visibility error, got %v", diagnostics)
+	}
+
+	_, _, diagnostics = ParseSource("buffer.wal", []byte(`pub impl Q {}`))
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "only functions, variables, constants and types can be pub" {
+		t.Fatalf("expected a pub placement error, got %v", diagnostics)
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let a: result{i32, error};
+	let b: []i32!error;
+	let c := parse(s)? * 2;`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	for i := 0; i < 2; i++ {
+		explicit := contents[i].(ast.VarDeclStmt).Variables[0].ExplicitType
+		if result, ok := explicit.(ast.ResultType); !ok || result.ErrType.(ast.UserDefinedType).AliasName != "error" {
+			t.Errorf("expected a result type with errors of type error, got %#v", explicit)
+		}
+	}
+
+	if result := contents[1].(ast.VarDeclStmt).Variables[0].ExplicitType.(ast.ResultType); result.OkType.Type() != builtins.PARSER_TYPE(builtins.ARRAY) {
+		t.Errorf("expected []i32!error to be a result of an array, got %#v", result)
+	}
+
+	product, ok := contents[2].(ast.VarDeclStmt).Variables[0].Value.(ast.BinaryExpr)
+	if !ok {
+		t.Fatalf("expected a binary expression, got %#v", contents[2])
+	}
+	if _, ok := product.Left.(ast.TryExpr); !ok {
+		t.Errorf("expected a try expression on the left, got %#v", product.Left)
+	}
+}
+
+func TestParseMaybeOperators(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`let a := u?.name ?? b ?? "x";
+	let c := u!.name;
+	let d := u?.nick ?? "" == "";`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	coalescing, ok := contents[0].(ast.VarDeclStmt).Variables[0].Value.(ast.NullCoalescingExpr)
+	if !ok {
+		t.Fatalf("expected a null coalescing expression, got %#v", contents[0])
+	}
+	if _, ok := coalescing.Left.(ast.OptionalPropertyAccessExpr); !ok {
+		t.Errorf("expected u?.name on the left, got %#v", coalescing.Left)
+	}
+	if _, ok := coalescing.Right.(ast.NullCoalescingExpr); !ok {
+		t.Errorf("expected ?? to group to the right, got %#v", coalescing.Right)
+	}
+
+	access, ok := contents[1].(ast.VarDeclStmt).Variables[0].Value.(ast.StructPropertyAccessExpr)
+	if _, isUnwrap := access.Object.(ast.UnwrapExpr); !ok || !isUnwrap {
+		t.Errorf("expected the property of u!, got %#v", contents[1])
+	}
+
+	if comparison, ok := contents[2].(ast.VarDeclStmt).Variables[0].Value.(ast.BinaryExpr); !ok || comparison.Operator.Value != "==" {
+		t.Errorf("expected ?? to bind tighter than ==, got %#v", contents[2])
+	}
+}
+
+func TestParseSafeBindings(t *testing.T) {
+	_, tree, diagnostics := ParseSource("buffer.wal", []byte(`safe a, v := m["k"], w := find(1) {
+	} otherwise {
+	}
+	safe a {
+	}`))
+
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+
+	contents := tree.(ast.ProgramStmt).Contents
+
+	safe := contents[0].(ast.SafeStmt)
+	if len(safe.Bindings) != 3 || safe.UnsafeBlock == nil {
+		t.Fatalf("expected three bindings and an otherwise block, got %#v", safe)
+	}
+	if safe.Bindings[0].Identifier.Name != "a" || safe.Bindings[0].Value != nil {
+		t.Errorf("expected the declared variable a, got %#v", safe.Bindings[0])
+	}
+	if _, ok := safe.Bindings[1].Value.(ast.Indexable); !ok || safe.Bindings[1].Identifier.Name != "v" {
+		t.Errorf("expected v bound to an index, got %#v", safe.Bindings[1])
+	}
+	if _, ok := safe.Bindings[2].Value.(ast.FunctionCallExpr); !ok || safe.Bindings[2].Identifier.Name != "w" {
+		t.Errorf("expected w bound to a call, got %#v", safe.Bindings[2])
+	}
+
+	if safe := contents[1].(ast.SafeStmt); safe.UnsafeBlock != nil {
+		t.Errorf("expected no otherwise block, got %#v", safe.UnsafeBlock)
+	}
+}
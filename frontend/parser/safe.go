@@ -6,20 +6,21 @@ import (
 )
 
 // parseSafeStmt parses a safe statement in the source code.
-// A safe statement consists of a safe block and an unsafe block.
+// A safe statement consists of a safe block and an optional unsafe block.
 // The function expects the parser to be positioned at the start of the safe statement.
 //
 // The structure of a safe statement is as follows:
 //
-//	safe <identifier> {
+//	safe <binding>, <binding> {
 //	    // safe block
 //	} otherwise {
 //
 //	    // unsafe block
 //	}
 //
-// The function advances the parser, extracts the identifier, parses the safe block,
-// expects an 'otherwise' token, and then parses the unsafe block.
+// A binding is either a declared variable, like a, or a new name and the expression it is bound to, like v := lookup(key).
+// The function advances the parser, extracts the bindings, parses the safe block,
+// and then parses the unsafe block if an 'otherwise' token follows it.
 //
 // Parameters:
 // - p: A pointer to the Parser instance.
@@ -31,30 +32,64 @@ func parseSafeStmt(p *Parser) ast.Node {
 
 	start := p.advance().Start // eat safe token
 
-	varName := p.expect(lexer.IDENTIFIER_TOKEN)
+	var bindings []ast.SafeBinding
+	for {
+		bindings = append(bindings, parseSafeBinding(p))
+		if p.currentTokenKind() != lexer.COMMA_TOKEN {
+			break
+		}
+		p.advance() // eat comma
+	}
 
 	//now we are in the safe block
 	safeBody := parseBlock(p)
+	end := safeBody.End
 
 	//unsafe block
-	p.expect(lexer.OTHERWISE_TOKEN) // eat unsafe token
-
-	//now we are in the unsafe block
-	unsafeBody := parseBlock(p)
+	var unsafeBody *ast.BlockStmt
+	if p.currentTokenKind() == lexer.OTHERWISE_TOKEN {
+		p.advance() // eat otherwise token
+		//now we are in the unsafe block
+		block := parseBlock(p)
+		unsafeBody = &block
+		end = block.End
+	}
 
 	return ast.SafeStmt{
-		Value: ast.IdentifierExpr{
+		Bindings:    bindings,
+		SafeBlock:   safeBody,
+		UnsafeBlock: unsafeBody,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+// parseSafeBinding parses one binding of a safe statement, a declared variable or name := value
+func parseSafeBinding(p *Parser) ast.SafeBinding {
+
+	varName := p.expect(lexer.IDENTIFIER_TOKEN)
+
+	binding := ast.SafeBinding{
+		Identifier: ast.IdentifierExpr{
 			Name: varName.Value,
 			Location: ast.Location{
 				Start: varName.Start,
 				End:   varName.End,
 			},
 		},
-		SafeBlock:   safeBody,
-		UnsafeBlock: unsafeBody,
 		Location: ast.Location{
-			Start: start,
-			End:   unsafeBody.End,
+			Start: varName.Start,
+			End:   varName.End,
 		},
 	}
+
+	if p.currentTokenKind() == lexer.WALRUS_TOKEN {
+		p.advance() // eat :=
+		binding.Value = parseExpr(p, COMMA_BP)
+		binding.End = binding.Value.EndPos()
+	}
+
+	return binding
 }
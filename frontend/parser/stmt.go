@@ -22,6 +22,8 @@ func parseUserDefinedTypeStmt(p *Parser) ast.Node {
 
 	typeName := p.expect(lexer.IDENTIFIER_TOKEN)
 
+	typeParams := parseTypeParams(p)
+
 	udType := parseUDTType(p)
 
 	p.expect(lexer.SEMI_COLON_TOKEN)
@@ -35,6 +37,7 @@ func parseUserDefinedTypeStmt(p *Parser) ast.Node {
 				End:   typeName.End,
 			},
 		},
+		TypeParams: typeParams,
 		Location: ast.Location{
 			Start: start,
 			End:   udType.EndPos(),
@@ -76,6 +79,7 @@ func parseBlock(p *Parser) ast.BlockStmt {
 // parseReturnStmt parses a return statement in the source code.
 // It expects the current token to be a return token and advances the parser.
 // If the next token is not a semicolon, it parses an expression for the return value.
+// Several values separated by commas, like ret q, r; are returned as one tuple.
 // Finally, it expects a semicolon to end the return statement and returns an ast.ReturnStmt node.
 //
 // Parameters:
@@ -91,6 +95,16 @@ func parseReturnStmt(p *Parser) ast.Node {
 
 	if p.currentTokenKind() != lexer.SEMI_COLON_TOKEN {
 		value = parseExpr(p, ASSIGNMENT_BP)
+		if p.currentTokenKind() == lexer.COMMA_TOKEN {
+			values := parseTupleValues(p, value, lexer.SEMI_COLON_TOKEN)
+			value = ast.TupleLiteral{
+				Values: values,
+				Location: ast.Location{
+					Start: value.StartPos(),
+					End:   values[len(values)-1].EndPos(),
+				},
+			}
+		}
 	}
 
 	end := p.expect(lexer.SEMI_COLON_TOKEN).End
@@ -9,7 +9,8 @@ import (
 // parseStructLiteral parses a struct literal from the input tokens.
 // It expects the following sequence of tokens:
 // - An '@' token indicating the start of a struct literal.
-// - An identifier token representing the struct name.
+// - An identifier token representing the struct name, optionally qualified by an imported package, like ds.Stack.
+// - Optional type arguments of a generic struct, like <i32, str>.
 // - An opening curly brace '{'.
 // - A series of property definitions, each consisting of:
 //   - An identifier token for the property name.
@@ -40,6 +41,28 @@ func parseStructLiteral(p *Parser) ast.Node {
 		},
 	}
 
+	// @ds.Stack{...} builds a struct of the imported package ds
+	var pkg *ast.IdentifierExpr
+	if p.currentTokenKind() == lexer.DOT_TOKEN {
+		p.advance()
+		pkgName := identidier
+		pkg = &pkgName
+		idetifierToken = p.expectError(lexer.IDENTIFIER_TOKEN, fmt.Errorf("expected a struct name"))
+		identidier = ast.IdentifierExpr{
+			Name: idetifierToken.Value,
+			Location: ast.Location{
+				Start: idetifierToken.Start,
+				End:   idetifierToken.End,
+			},
+		}
+	}
+
+	// explicit type arguments of a generic struct. without them they are inferred from the values
+	var typeArgs []ast.DataType
+	if p.currentTokenKind() == lexer.LESS_TOKEN {
+		typeArgs, _ = parseTypeArgs(p)
+	}
+
 	p.expect(lexer.OPEN_CURLY)
 
 	//parse the values
@@ -74,7 +97,9 @@ func parseStructLiteral(p *Parser) ast.Node {
 	end := p.expect(lexer.CLOSE_CURLY).End
 
 	structVal := ast.StructLiteral{
+		Package:    pkg,
 		Identifier: identidier,
+		TypeArgs:   typeArgs,
 		Properties: props,
 		Location: ast.Location{
 			Start: start,
@@ -119,3 +144,28 @@ func parsePropertyExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
 		},
 	}
 }
+
+// parseOptionalPropertyExpr parses a property read through a maybe value, like user?.name or user?.getName()
+func parseOptionalPropertyExpr(p *Parser, left ast.Node, bp BINDING_POWER) ast.Node {
+
+	p.expect(lexer.OPTIONAL_CHAIN_TOKEN)
+
+	identifier := p.expect(lexer.IDENTIFIER_TOKEN)
+
+	property := ast.IdentifierExpr{
+		Name: identifier.Value,
+		Location: ast.Location{
+			Start: identifier.Start,
+			End:   identifier.End,
+		},
+	}
+
+	return ast.OptionalPropertyAccessExpr{
+		Object:   left,
+		Property: property,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   property.End,
+		},
+	}
+}
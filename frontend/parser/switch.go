@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+)
+
+// parseSwitchStmt parses a switch statement from the input and returns an AST node representing it.
+// It expects the parser to be positioned at the 'switch' token.
+//
+// The structure of a switch statement is as follows:
+//
+//	switch <expression> {
+//	    case <value>, <value>: {
+//	        // block
+//	    }
+//	    default: {
+//	        // block
+//	    }
+//	}
+//
+// A case can list several values, and at most one default arm is allowed.
+//
+// Parameters:
+// - p: A pointer to the Parser instance.
+//
+// Returns:
+// - An ast.Node representing the parsed switch statement.
+func parseSwitchStmt(p *Parser) ast.Node {
+
+	start := p.advance().Start // eat switch token
+
+	discriminant := parseExpr(p, ASSIGNMENT_BP)
+
+	p.expect(lexer.OPEN_CURLY)
+
+	var cases []ast.SwitchCase
+	var defaultBlock *ast.BlockStmt
+
+	for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_CURLY {
+
+		token := p.currentToken()
+
+		switch token.Kind {
+		case lexer.CASE_TOKEN:
+			p.advance() // eat case token
+			var values []ast.Node
+			for {
+				values = append(values, parseExpr(p, COMMA_BP))
+				if p.currentTokenKind() != lexer.COMMA_TOKEN {
+					break
+				}
+				p.advance() // eat comma
+			}
+			p.expect(lexer.COLON_TOKEN)
+			block := parseBlock(p)
+			cases = append(cases, ast.SwitchCase{
+				Values: values,
+				Block:  block,
+				Location: ast.Location{
+					Start: token.Start,
+					End:   block.End,
+				},
+			})
+		case lexer.DEFAULT_TOKEN:
+			p.advance() // eat default token
+			p.expect(lexer.COLON_TOKEN)
+			block := parseBlock(p)
+			if defaultBlock != nil {
+				errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, "multiple default cases in switch").Level(errgen.SYNTAX_ERROR)
+			}
+			defaultBlock = &block
+		default:
+			errgen.Add(p.FilePath, token.Start.Line, token.End.Line, token.Start.Column, token.End.Column, "expected 'case' or 'default' in switch").Level(errgen.SYNTAX_ERROR)
+		}
+	}
+
+	end := p.expect(lexer.CLOSE_CURLY).End
+
+	return ast.SwitchStmt{
+		Discriminant: discriminant,
+		Cases:        cases,
+		Default:      defaultBlock,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
@@ -20,12 +20,93 @@ func typeNUD(kind builtins.TOKEN_KIND, handler typeNUDHandler) {
 	typeNUDLookup[kind] = handler
 }
 
+func typeLED(kind builtins.TOKEN_KIND, bp BINDING_POWER, handler typeLEDHandler) {
+	bpTypeLookups[kind] = bp
+	typeLEDLookup[kind] = handler
+}
+
 func bindTypeLookups() {
 	typeNUD(lexer.IDENTIFIER_TOKEN, parseDataType)
 	typeNUD(lexer.OPEN_BRACKET, parseArrayType)
 	typeNUD(lexer.FUNCTION_TOKEN, parseFunctionType)
 	typeNUD(lexer.MAP_TOKEN, parseMapType)
 	typeNUD(lexer.MAYBE_TOKEN, parseMaybeType)
+	typeNUD(lexer.RANGE_TOKEN, parseRangeType)
+	typeNUD(lexer.OPEN_PAREN, parseTupleType)
+	typeNUD(lexer.RESULT_TOKEN, parseResultType)
+
+	typeLED(lexer.NOT_TOKEN, UNARY_BP, parseShortResultType) // T!E
+}
+
+// parseResultType parses a result type like result{i32, ParseError}
+func parseResultType(p *Parser) ast.DataType {
+	start := p.advance().Start
+
+	p.expect(lexer.OPEN_CURLY)
+
+	okType := parseType(p, DEFAULT_BP)
+
+	p.expect(lexer.COMMA_TOKEN)
+
+	errType := parseType(p, DEFAULT_BP)
+
+	end := p.expect(lexer.CLOSE_CURLY).End
+
+	return ast.ResultType{
+		TypeName: builtins.PARSER_TYPE(builtins.RESULT),
+		OkType:   okType,
+		ErrType:  errType,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+// parseShortResultType parses the short form of a result type, like i32!ParseError for result{i32, ParseError}
+func parseShortResultType(p *Parser, left ast.DataType, bp BINDING_POWER) ast.DataType {
+	p.advance() // eat !
+
+	errType := parseType(p, bp)
+
+	return ast.ResultType{
+		TypeName: builtins.PARSER_TYPE(builtins.RESULT),
+		OkType:   left,
+		ErrType:  errType,
+		Location: ast.Location{
+			Start: left.StartPos(),
+			End:   errType.EndPos(),
+		},
+	}
+}
+
+// parseTupleType parses a tuple type like (i32, str). A tuple has at least two elements.
+func parseTupleType(p *Parser) ast.DataType {
+	start := p.advance().Start // eat (
+
+	elements := make([]ast.DataType, 0)
+
+	for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_PAREN {
+		elements = append(elements, parseType(p, DEFAULT_BP))
+		if p.currentTokenKind() != lexer.CLOSE_PAREN {
+			p.expect(lexer.COMMA_TOKEN)
+		}
+	}
+
+	end := p.expect(lexer.CLOSE_PAREN).End
+
+	if len(elements) < 2 {
+		errgen.Add(p.FilePath, start.Line, end.Line, start.Column, end.Column, "a tuple type needs at least two elements").Level(errgen.SYNTAX_ERROR)
+	}
+
+	return ast.TupleType{
+		TypeName:     builtins.PARSER_TYPE(builtins.TUPLE),
+		ElementTypes: elements,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
 }
 
 func parseMaybeType(p *Parser) ast.DataType {
@@ -47,6 +128,25 @@ func parseMaybeType(p *Parser) ast.DataType {
 	}
 }
 
+func parseRangeType(p *Parser) ast.DataType {
+	start := p.advance().Start
+
+	p.expect(lexer.OPEN_CURLY)
+
+	dataType := parseType(p, DEFAULT_BP)
+
+	end := p.expect(lexer.CLOSE_CURLY).End
+
+	return ast.RangeType{
+		TypeName:    builtins.PARSER_TYPE(builtins.RANGE),
+		ElementType: dataType,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
 func parseMapType(p *Parser) ast.DataType {
 
 	// map[<keyType>]<valueType>
@@ -190,7 +290,7 @@ func getFunctionTypeSignature(p *Parser) (builtins.PARSER_TYPE, []ast.FunctionTy
 
 // Parses the builtin types like int, float, bool, char, str, null.
 // If the type is not a builtin type, then it is a user defined type
-// Type must be a single token identifier
+// Type must be a single token identifier. A user defined type may be qualified by an imported package, like ds.Stack
 func parseDataType(p *Parser) ast.DataType {
 
 	identifier := p.advance()
@@ -239,9 +339,24 @@ func parseDataType(p *Parser) ast.DataType {
 			Location: loc,
 		}
 	default:
+		// ds.Stack names the type Stack of the imported package ds
+		pkg := ""
+		if p.currentTokenKind() == lexer.DOT_TOKEN {
+			p.advance()
+			pkg = value
+			typeToken := p.expectError(lexer.IDENTIFIER_TOKEN, errors.New("expected a type name after the package name"))
+			value, loc.End = typeToken.Value, typeToken.End
+		}
+		var typeArgs []ast.DataType
+		// Pair<i32, str> instantiates a generic type
+		if p.currentTokenKind() == lexer.LESS_TOKEN {
+			typeArgs, loc.End = parseTypeArgs(p)
+		}
 		return ast.UserDefinedType{
 			TypeName:  builtins.PARSER_TYPE(builtins.USER_DEFINED),
+			Package:   pkg,
 			AliasName: value,
+			TypeArgs:  typeArgs,
 			Location:  loc,
 		}
 	}
@@ -263,7 +378,8 @@ func parseArrayType(p *Parser) ast.DataType {
 	p.advance()
 	p.expect(lexer.CLOSE_BRACKET)
 
-	elemType := parseType(p, DEFAULT_BP)
+	// []i32!E is a result of an array, so the element type stops before the !
+	elemType := parseType(p, UNARY_BP)
 
 	return ast.ArrayType{
 		TypeName:  builtins.PARSER_TYPE(builtins.ARRAY),
@@ -290,6 +406,9 @@ func parseArrayType(p *Parser) ast.DataType {
 func parseType(p *Parser, bp BINDING_POWER) ast.DataType {
 	// Fist parse the NUD
 	tokenKind := p.currentTokenKind()
+	if tokenKind == lexer.IDENTIFIER_TOKEN && p.currentToken().Value == lexer.RESULT_KEYWORD {
+		tokenKind = lexer.RESULT_TOKEN
+	}
 	nudFunction, exists := typeNUDLookup[tokenKind]
 
 	if !exists {
@@ -332,6 +451,8 @@ func parseUDTType(p *Parser) ast.DataType {
 		return parseStructType(p)
 	case builtins.INTERFACE:
 		return parseInterfaceType(p)
+	case builtins.ENUM:
+		return parseEnumType(p)
 	default:
 		return parseType(p, DEFAULT_BP)
 	}
@@ -365,12 +486,7 @@ func parseStructType(p *Parser) ast.DataType {
 
 	for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_CURLY {
 
-		isPrivate := false
-
-		if p.currentTokenKind() == lexer.PRIVATE_TOKEN {
-			isPrivate = true
-			p.advance()
-		}
+		isPublic, isPrivate := parseMemberVisibility(p)
 
 		iden := p.expect(lexer.IDENTIFIER_TOKEN)
 
@@ -390,6 +506,7 @@ func parseStructType(p *Parser) ast.DataType {
 			Prop:      idenExpr,
 			PropType:  typeName,
 			IsPrivate: isPrivate,
+			IsPublic:  isPublic,
 		})
 
 		if p.currentTokenKind() != lexer.CLOSE_CURLY {
@@ -415,6 +532,95 @@ func parseStructType(p *Parser) ast.DataType {
 	}
 }
 
+// parseEnumType parses an enum type definition. Each variant is a name, optionally followed by its payload fields:
+//
+//	type Shape enum {
+//		Circle(radius: f32),
+//		Rect(w: f32, h: f32),
+//		Empty,
+//	};
+func parseEnumType(p *Parser) ast.DataType {
+
+	identifier := p.advance() // eat enum token
+
+	variants := make([]ast.EnumVariant, 0)
+
+	start := p.expect(lexer.OPEN_CURLY).Start
+
+	for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_CURLY {
+
+		name := p.expect(lexer.IDENTIFIER_TOKEN)
+
+		fields := make([]ast.EnumVariantField, 0)
+		end := name.End
+
+		if p.currentTokenKind() == lexer.OPEN_PAREN {
+			p.advance()
+
+			for p.hasToken() && p.currentTokenKind() != lexer.CLOSE_PAREN {
+				field := p.expect(lexer.IDENTIFIER_TOKEN)
+				p.expect(lexer.COLON_TOKEN)
+				fieldType := parseType(p, DEFAULT_BP)
+
+				fields = append(fields, ast.EnumVariantField{
+					Field: ast.IdentifierExpr{
+						Name: field.Value,
+						Location: ast.Location{
+							Start: field.Start,
+							End:   field.End,
+						},
+					},
+					FieldType: fieldType,
+				})
+
+				if p.currentTokenKind() != lexer.CLOSE_PAREN {
+					p.expect(lexer.COMMA_TOKEN)
+				}
+			}
+
+			end = p.expect(lexer.CLOSE_PAREN).End
+
+			if len(fields) == 0 {
+				errgen.Add(p.FilePath, name.Start.Line, end.Line, name.Start.Column, end.Column, fmt.Sprintf("enum variant '%s' has no fields", name.Value)).Hint("remove the parentheses").Level(errgen.SYNTAX_ERROR)
+			}
+		}
+
+		variants = append(variants, ast.EnumVariant{
+			Identifier: ast.IdentifierExpr{
+				Name: name.Value,
+				Location: ast.Location{
+					Start: name.Start,
+					End:   name.End,
+				},
+			},
+			Fields: fields,
+			Location: ast.Location{
+				Start: name.Start,
+				End:   end,
+			},
+		})
+
+		if p.currentTokenKind() != lexer.CLOSE_CURLY {
+			p.expect(lexer.COMMA_TOKEN)
+		}
+	}
+
+	end := p.expect(lexer.CLOSE_CURLY).End
+
+	if len(variants) == 0 {
+		errgen.Add(p.FilePath, identifier.Start.Line, identifier.End.Line, identifier.Start.Column, identifier.End.Column, "enum is empty").Level(errgen.SYNTAX_ERROR)
+	}
+
+	return ast.EnumType{
+		TypeName: builtins.PARSER_TYPE(builtins.ENUM),
+		Variants: variants,
+		Location: ast.Location{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
 func parseInterfaceType(p *Parser) ast.DataType {
 
 	start := p.advance().Start
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"walrus/errgen"
 	"walrus/frontend/ast"
+	"walrus/frontend/builtins"
 	"walrus/frontend/lexer"
 )
 
@@ -20,7 +21,7 @@ import (
 // The function performs the following steps:
 // 1. Advances the parser to consume the `let` or `const` keyword.
 // 2. Determines if the declaration is a constant.
-// 3. Expects and consumes an identifier token for the variable name.
+// 3. Expects and consumes an identifier token for the variable name, or a destructuring pattern like (a, b), {name, age} or [first, second].
 // 4. Optionally parses an explicit type if a colon `:` is present.
 // 5. Parses the assignment operator `:=` or `=` and the initial value expression if present.
 // 6. Ensures that constants have an initial value.
@@ -32,6 +33,7 @@ func parseVarDeclStmt(p *Parser) ast.Node {
 	// 	let a: i32 = 10, b: f32 = 20, c : bool;
 	// 	let a := 10, b := 20, c := true;
 	// 	const a: i32 = 10, b: f32 = 20, c : bool;
+	// 	let (q, r) := divmod(7, 2), {name, age} := person;
 
 	// advance the let/const keyword
 	declToken := p.advance()
@@ -42,8 +44,17 @@ func parseVarDeclStmt(p *Parser) ast.Node {
 	var variables []ast.VarDeclStmtVar
 
 	for {
-		// parse the variable name
-		identifier := p.expect(lexer.IDENTIFIER_TOKEN)
+		var identifier lexer.Token
+		var pattern *ast.DestructuringPattern
+
+		// parse the variable name, or the names a destructuring pattern takes out of the value
+		switch p.currentTokenKind() {
+		case lexer.OPEN_PAREN, lexer.OPEN_CURLY, lexer.OPEN_BRACKET:
+			pattern = parseDestructuringPattern(p)
+			identifier.Start, identifier.End = pattern.Start, pattern.End
+		default:
+			identifier = p.expect(lexer.IDENTIFIER_TOKEN)
+		}
 
 		// parse the explicit type if present. This will be nil if no type is specified.
 		var explicitType ast.DataType
@@ -73,6 +84,10 @@ func parseVarDeclStmt(p *Parser) ast.Node {
 			errgen.Add(p.FilePath, p.currentToken().Start.Line, p.currentToken().End.Line, p.currentToken().Start.Column, p.currentToken().End.Column, msg).Level(errgen.SYNTAX_ERROR)
 		}
 
+		if pattern != nil && value == nil {
+			errgen.Add(p.FilePath, pattern.Start.Line, pattern.End.Line, pattern.Start.Column, pattern.End.Column, "a destructuring declaration must have a value").Level(errgen.SYNTAX_ERROR)
+		}
+
 		variables = append(variables, ast.VarDeclStmtVar{
 			Identifier: ast.IdentifierExpr{
 				Name: identifier.Value,
@@ -81,6 +96,7 @@ func parseVarDeclStmt(p *Parser) ast.Node {
 					End:   identifier.End,
 				},
 			},
+			Pattern:      pattern,
 			Value:        value,
 			ExplicitType: explicitType,
 			Location: ast.Location{
@@ -110,6 +126,56 @@ func parseVarDeclStmt(p *Parser) ast.Node {
 	return node
 }
 
+// parseDestructuringPattern parses the names of a destructuring declaration: (a, b) for a tuple,
+// {name, age} for the properties of a struct, or [first, second] for the elements of an array.
+func parseDestructuringPattern(p *Parser) *ast.DestructuringPattern {
+
+	open := p.advance()
+
+	var kind builtins.PARSER_TYPE
+	var closing builtins.TOKEN_KIND
+
+	switch open.Kind {
+	case lexer.OPEN_PAREN:
+		kind, closing = builtins.TUPLE, lexer.CLOSE_PAREN
+	case lexer.OPEN_CURLY:
+		kind, closing = builtins.STRUCT, lexer.CLOSE_CURLY
+	default:
+		kind, closing = builtins.ARRAY, lexer.CLOSE_BRACKET
+	}
+
+	names := make([]ast.IdentifierExpr, 0)
+
+	for p.hasToken() && p.currentTokenKind() != closing {
+		name := p.expect(lexer.IDENTIFIER_TOKEN)
+		names = append(names, ast.IdentifierExpr{
+			Name: name.Value,
+			Location: ast.Location{
+				Start: name.Start,
+				End:   name.End,
+			},
+		})
+		if p.currentTokenKind() != closing {
+			p.expect(lexer.COMMA_TOKEN)
+		}
+	}
+
+	end := p.expect(closing).End
+
+	if len(names) == 0 {
+		errgen.Add(p.FilePath, open.Start.Line, end.Line, open.Start.Column, end.Column, "expected at least one variable to destructure into").Level(errgen.SYNTAX_ERROR)
+	}
+
+	return &ast.DestructuringPattern{
+		Kind:  kind,
+		Names: names,
+		Location: ast.Location{
+			Start: open.Start,
+			End:   end,
+		},
+	}
+}
+
 // parseVarAssignmentExpr parses a variable assignment expression in the source code.
 // It takes a parser instance, the left-hand side node, and the binding power as arguments.
 // The function ensures that the left-hand side of the assignment is a valid identifier,
@@ -21,13 +21,22 @@ import (
 //
 // If both checks pass, the function returns the type of the elements contained in the array.
 func evaluateIndexableAccess(indexable ast.Indexable, e *TypeEnvironment) ExprType {
+	indexedValueType, _ := evaluateIndexedValue(indexable, e)
+	return indexedValueType
+}
+
+// evaluateIndexedValue returns the type of the element an index expression reads, and whether it reads a map,
+// where the key may be missing
+func evaluateIndexedValue(indexable ast.Indexable, e *TypeEnvironment) (ExprType, bool) {
 
 	container := parseNodeValue(indexable.Container, e)
 	index := parseNodeValue(indexable.Index, e)
 
 	var indexedValueType ExprType
 
-	switch t := container.(type) {
+	switch t := unwrapType(container).(type) {
+	case Unknown:
+		return t, false
 	case Array:
 		if !isIntType(index) {
 			errgen.Add(e.filePath, indexable.Start.Line, indexable.End.Line, indexable.Index.StartPos().Column, indexable.Index.EndPos().Column, fmt.Sprintf("cannot use type '%s' to index array\n", tcValueToString(index))+errgen.TreeFormatString("type must be a valid signed integer")).Level(errgen.NORMAL_ERROR)
@@ -37,36 +46,55 @@ func evaluateIndexableAccess(indexable ast.Indexable, e *TypeEnvironment) ExprTy
 		if !isIntType(index) {
 			errgen.Add(e.filePath, indexable.Start.Line, indexable.End.Line, indexable.Index.StartPos().Column, indexable.Index.EndPos().Column, fmt.Sprintf("cannot use type '%s' to index string\n", tcValueToString(index))+errgen.TreeFormatString("type must be a valid signed integer")).Level(errgen.NORMAL_ERROR)
 		}
-		return NewInt(8, false)
+		return NewInt(8, false), false
 	case Map:
 		//if key is interface then error
 		if unwrapType(t.KeyType).DType() == INTERFACE_TYPE {
 			errgen.Add(e.filePath, indexable.Start.Line, indexable.End.Line, indexable.Index.StartPos().Column, indexable.Index.EndPos().Column, fmt.Sprintf("cannot access index of type %s", INTERFACE_TYPE)).Level(errgen.NORMAL_ERROR)
 		}
-		indexedValueType = t.ValueType
+		return t.ValueType, true
 	default:
 		errgen.Add(e.filePath, indexable.Start.Line, indexable.End.Line, indexable.Container.StartPos().Column, indexable.Container.EndPos().Column, fmt.Sprintf("cannot access index of type %s", container.DType())).Level(errgen.CRITICAL_ERROR)
 	}
 
-	return indexedValueType
+	return indexedValueType, false
 }
 
 // evaluateArrayExpr evaluates an array expression within a given type environment.
 // It checks that all elements in the array are of the same type and returns an Array type.
 //
+// When no element has a type of its own, the untyped constants take the number type of the elements the context expects,
+// so [1, 2, 3] is a []u8 where a []u8 is expected.
+//
 // Parameters:
 // - array: The array expression to evaluate.
+// - expected: The type the context expects for the array, or nil when it expects none.
 // - env: The type environment in which the array expression is evaluated.
 //
 // Returns:
 // - ValueTypeInterface: The type of the array, which includes the data type and the type of the array elements.
-func evaluateArrayExpr(array ast.ArrayLiteral, env *TypeEnvironment) ExprType {
-	var expectedType ExprType
+func evaluateArrayExpr(array ast.ArrayLiteral, expected ExprType, env *TypeEnvironment) ExprType {
+
+	if maybe, ok := unwrapType(expected).(Maybe); ok {
+		expected = maybe.MaybeType
+	}
+
+	var contextType ExprType
+	if expectedArray, ok := unwrapType(expected).(Array); ok {
+		contextType = expectedArray.ArrayType
+	}
+
+	values := make([]ExprType, len(array.Values))
 	for i, value := range array.Values {
-		v := parseNodeValue(value, env)
-		if i == 0 {
-			expectedType = v
-		}
+		values[i] = checkValueFor(value, contextType, env)
+	}
+
+	expectedType := arrayElementType(values)
+	if isUntypedElements(values) && contextType != nil && isNumberType(contextType) {
+		expectedType = contextType
+	}
+
+	for i, v := range values {
 		//check every type is same or not
 		err := matchTypes(expectedType, v)
 		if err != nil {
@@ -79,3 +107,41 @@ func evaluateArrayExpr(array ast.ArrayLiteral, env *TypeEnvironment) ExprType {
 		ArrayType: expectedType,
 	}
 }
+
+// isUntypedElements reports whether every element of an array literal is an untyped constant
+func isUntypedElements(values []ExprType) bool {
+	for _, v := range values {
+		if !isUntyped(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkValueFor checks a value where the context expects the given type, or nil when it expects none.
+// An array literal gives the type to its untyped constants, the way a constant takes the type it is assigned to.
+func checkValueFor(node ast.Node, expected ExprType, env *TypeEnvironment) ExprType {
+	if array, ok := node.(ast.ArrayLiteral); ok {
+		return evaluateArrayExpr(array, expected, env)
+	}
+	return parseNodeValue(node, env)
+}
+
+// arrayElementType returns the element type of an array literal: the type of its first typed element.
+// If every element is an untyped constant, the default type is used, f32 if any of them is a float.
+func arrayElementType(values []ExprType) ExprType {
+	if len(values) == 0 {
+		return nil
+	}
+	for _, v := range values {
+		if !isUntyped(v) {
+			return v
+		}
+	}
+	for _, v := range values {
+		if _, ok := v.(UntypedFloat); ok {
+			return defaultType(v)
+		}
+	}
+	return defaultType(values[0])
+}
@@ -3,6 +3,7 @@ package typechecker
 import (
 	"walrus/errgen"
 	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
 )
 
 func checkConditionBlock(block ast.BlockStmt, env *TypeEnvironment) ExprType {
@@ -12,24 +13,182 @@ func checkConditionBlock(block ast.BlockStmt, env *TypeEnvironment) ExprType {
 	return NewVoid()
 }
 
+// checkIfStmt checks an if statement. Each branch has its own scope, where the maybe variables the condition
+// compares with null are narrowed: in if a != null { }, a has the type of its value. When only some branches reach
+// the end of the statement, what holds at the end of each of them also holds after it, like after if a == null { ret; }.
 func checkIfStmt(ifNode ast.IfStmt, env *TypeEnvironment) ExprType {
+
+	after, reached := checkIfBranches(ifNode, env)
+	if reached {
+		for name, valueType := range after {
+			env.narrow(name, valueType)
+		}
+	}
+
+	return NewVoid()
+}
+
+// checkIfBranches checks the branches of an if statement. It returns the narrowings that hold at the end of every
+// branch that reaches the end of the statement, and whether any branch does.
+func checkIfBranches(ifNode ast.IfStmt, env *TypeEnvironment) (map[string]ExprType, bool) {
 	//condition
 	cond := parseNodeValue(ifNode.Condition, env)
-	if cond.DType() != BOOLEAN_TYPE {
+	if cond.DType() != BOOLEAN_TYPE && !isUnknown(cond) {
 		errgen.Add(env.filePath, ifNode.Condition.StartPos().Line, ifNode.Condition.EndPos().Line, ifNode.Condition.StartPos().Column, ifNode.Condition.EndPos().Column, "Condition must be a boolean expression").Level(errgen.NORMAL_ERROR)
 	}
 
+	whenTrue, whenFalse := conditionNarrowing(ifNode.Condition, env)
+
+	var reached []map[string]ExprType
+
 	//then block
-	checkConditionBlock(ifNode.Block, env)
+	thenEnv := narrowedEnv(env, "if block", whenTrue)
+	checkConditionBlock(ifNode.Block, thenEnv)
+	if !blockExits(ifNode.Block) {
+		reached = append(reached, outerNarrowing(thenEnv))
+	}
 
-	if ifNode.AlternateBlock != nil {
-		switch t := ifNode.AlternateBlock.(type) {
-		case ast.IfStmt:
-			checkIfStmt(t, env)
-		case ast.BlockStmt:
-			checkConditionBlock(t, env)
+	elseEnv := narrowedEnv(env, "else block", whenFalse)
+	switch t := ifNode.AlternateBlock.(type) {
+	case ast.IfStmt:
+		if after, ok := checkIfBranches(t, elseEnv); ok {
+			reached = append(reached, unionNarrowing(outerNarrowing(elseEnv), after))
+		}
+	case ast.BlockStmt:
+		checkConditionBlock(t, elseEnv)
+		if !blockExits(t) {
+			reached = append(reached, outerNarrowing(elseEnv))
 		}
+	default:
+		reached = append(reached, outerNarrowing(elseEnv))
 	}
 
-	return NewVoid()
+	if len(reached) == 0 {
+		return nil, false
+	}
+
+	after := reached[0]
+	for _, narrowing := range reached[1:] {
+		after = intersectNarrowing(after, narrowing)
+	}
+	return after, true
+}
+
+// narrowedEnv creates the scope of a branch, where the given maybe variables are narrowed
+func narrowedEnv(env *TypeEnvironment, scopeName string, narrowing map[string]ExprType) *TypeEnvironment {
+	scope := NewTypeENV(env, CONDITIONAL_SCOPE, scopeName, env.filePath)
+	for name, valueType := range narrowing {
+		scope.narrow(name, valueType)
+	}
+	return scope
+}
+
+// outerNarrowing returns the narrowings of a branch scope for variables declared outside of it, which still exist after the branch
+func outerNarrowing(scope *TypeEnvironment) map[string]ExprType {
+	narrowing := make(map[string]ExprType)
+	for name, valueType := range scope.narrowed {
+		if !scope.isDeclared(name) {
+			narrowing[name] = valueType
+		}
+	}
+	return narrowing
+}
+
+// conditionNarrowing returns the types the maybe variables of a condition have when it is true and when it is false.
+// a != null narrows a to the type of its value when true and to null when false, and a == null the other way around.
+// ! swaps them, and && and || combine the narrowings of their sides. The right side of && is only evaluated when the
+// left side is true, so it is narrowed by it, and the right side of || by the left side being false.
+func conditionNarrowing(cond ast.Node, env *TypeEnvironment) (map[string]ExprType, map[string]ExprType) {
+
+	switch t := cond.(type) {
+	case ast.BinaryExpr:
+		if t.Operator.Kind != lexer.DOUBLE_EQUAL_TOKEN && t.Operator.Kind != lexer.NOT_EQUAL_TOKEN {
+			return nil, nil
+		}
+		variable, other := t.Left, t.Right
+		if isNullLiteral(variable) {
+			variable, other = other, variable
+		}
+		if !isNullLiteral(other) {
+			return nil, nil
+		}
+		name, maybe, ok := maybeVariable(variable, env)
+		if !ok {
+			return nil, nil
+		}
+		notNull := map[string]ExprType{name: maybe.MaybeType}
+		null := map[string]ExprType{name: NewNull()}
+		if t.Operator.Kind == lexer.DOUBLE_EQUAL_TOKEN {
+			return null, notNull
+		}
+		return notNull, null
+	case ast.UnaryExpr:
+		if t.Operator.Kind != lexer.NOT_TOKEN {
+			return nil, nil
+		}
+		whenTrue, whenFalse := conditionNarrowing(t.Argument, env)
+		return whenFalse, whenTrue
+	case ast.LogicalExpr:
+		leftTrue, leftFalse := conditionNarrowing(t.Left, env)
+		if t.Operator.Kind == lexer.AND_TOKEN {
+			rightTrue, rightFalse := conditionNarrowing(t.Right, narrowedEnv(env, "condition", leftTrue))
+			return unionNarrowing(leftTrue, rightTrue), intersectNarrowing(leftFalse, unionNarrowing(leftTrue, rightFalse))
+		}
+		rightTrue, rightFalse := conditionNarrowing(t.Right, narrowedEnv(env, "condition", leftFalse))
+		return intersectNarrowing(leftTrue, unionNarrowing(leftFalse, rightTrue)), unionNarrowing(leftFalse, rightFalse)
+	}
+
+	return nil, nil
+}
+
+// isNullLiteral reports whether a node is the builtin value null, which cannot be redeclared
+func isNullLiteral(node ast.Node) bool {
+	identifier, ok := node.(ast.IdentifierExpr)
+	return ok && identifier.Name == "null"
+}
+
+// maybeVariable returns the name and type of a variable that may be null where the node is, without reporting
+// anything when it is not one
+func maybeVariable(node ast.Node, env *TypeEnvironment) (string, Maybe, bool) {
+
+	identifier, ok := node.(ast.IdentifierExpr)
+	if !ok {
+		return "", Maybe{}, false
+	}
+
+	declaredEnv, err := env.resolveVar(identifier.Name)
+	if err != nil {
+		return "", Maybe{}, false
+	}
+
+	variable := declaredEnv.variables[identifier.Name]
+	if valueType, ok := env.narrowedType(identifier.Name); ok {
+		variable = valueType
+	}
+
+	maybe, ok := unwrapType(variable).(Maybe)
+	return identifier.Name, maybe, ok
+}
+
+// unionNarrowing returns the narrowings of both, for when both hold. The second wins for a variable in both.
+func unionNarrowing(a, b map[string]ExprType) map[string]ExprType {
+	union := make(map[string]ExprType, len(a)+len(b))
+	for name, valueType := range a {
+		union[name] = valueType
+	}
+	for name, valueType := range b {
+		union[name] = valueType
+	}
+	return union
+}
+
+// intersectNarrowing returns the narrowings that are the same in both, for when either may hold
+func intersectNarrowing(a, b map[string]ExprType) map[string]ExprType {
+	intersection := make(map[string]ExprType)
+	for name, valueType := range a {
+		if other, ok := b[name]; ok && tcValueToString(other) == tcValueToString(valueType) {
+			intersection[name] = valueType
+		}
+	}
+	return intersection
 }
@@ -0,0 +1,331 @@
+package typechecker
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+)
+
+// constantPrecision is the mantissa precision, in bits, used for untyped float constants
+const constantPrecision = 256
+
+// maxConstantShift is the largest count an untyped constant can be shifted by
+const maxConstantShift = 512
+
+// checkIntegerLiteral returns the type of an integer literal. A literal without a type suffix is an untyped constant.
+// A suffixed literal must fit in its type.
+func checkIntegerLiteral(node ast.IntegerLiteralExpr, env *TypeEnvironment) ExprType {
+	value, ok := new(big.Int).SetString(node.Value, 0)
+	if !ok {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, fmt.Sprintf("invalid integer literal '%s'", node.Value)).Level(errgen.CRITICAL_ERROR)
+	}
+
+	constant := NewUntypedInt(value)
+	if node.IsUntyped {
+		return constant
+	}
+
+	intType := NewInt(node.BitSize, node.IsSigned)
+	if err := representable(constant, intType); err != nil {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	}
+
+	return intType
+}
+
+// checkFloatLiteral returns the type of a float literal. A literal without a type suffix is an untyped constant.
+// A suffixed literal must fit in its type.
+func checkFloatLiteral(node ast.FloatLiteralExpr, env *TypeEnvironment) ExprType {
+	value, _, err := big.ParseFloat(node.Value, 10, constantPrecision, big.ToNearestEven)
+	if err != nil {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, fmt.Sprintf("invalid float literal '%s'", node.Value)).Level(errgen.CRITICAL_ERROR)
+	}
+
+	constant := NewUntypedFloat(value)
+	if node.IsUntyped {
+		return constant
+	}
+
+	floatType := NewFloat(node.BitSize)
+	if err := representable(constant, floatType); err != nil {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	}
+
+	return floatType
+}
+
+func isUntyped(value ExprType) bool {
+	switch value.(type) {
+	case UntypedInt, UntypedFloat:
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultType returns the type an untyped constant takes when its context does not expect one: i32 or f32,
+// or range{i32} for a range between two untyped constants. The elements of a tuple take their default types too.
+// Any other type is returned unchanged.
+func defaultType(value ExprType) ExprType {
+	switch v := value.(type) {
+	case UntypedInt:
+		return NewInt(32, true)
+	case UntypedFloat:
+		return NewFloat(32)
+	case Range:
+		if v.Bounds != nil {
+			return NewRange(NewInt(32, true))
+		}
+		return value
+	case Tuple:
+		elements := make([]ExprType, len(v.ElementTypes))
+		for i, element := range v.ElementTypes {
+			elements[i] = defaultType(element)
+		}
+		return NewTuple(elements)
+	default:
+		return value
+	}
+}
+
+// defaultTypeAt gives a value its default type like defaultType, and reports at node the untyped constants in it
+// that do not fit the type they take, like 3000000000 for i32
+func defaultTypeAt(node ast.Node, value ExprType, env *TypeEnvironment) ExprType {
+	for _, err := range defaultOverflows(value) {
+		errgen.Add(env.filePath, node.StartPos().Line, node.EndPos().Line, node.StartPos().Column, node.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	}
+	return defaultType(value)
+}
+
+// defaultOverflows returns an error for each untyped constant in a value that does not fit its default type:
+// the constant itself, the bounds of an untyped range or the elements of a tuple
+func defaultOverflows(value ExprType) []error {
+	var errs []error
+	switch v := value.(type) {
+	case UntypedInt, UntypedFloat:
+		if err := representable(v, defaultType(v)); err != nil {
+			errs = append(errs, err)
+		}
+	case Range:
+		for _, bound := range v.Bounds {
+			if err := representable(bound, NewInt(32, true)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	case Tuple:
+		for _, element := range v.ElementTypes {
+			errs = append(errs, defaultOverflows(element)...)
+		}
+	}
+	return errs
+}
+
+// representable checks that the untyped constant can be used as a value of the target type.
+// Integer constants fit an integer type when they are within its range; float constants fit an integer
+// type only when they have no fractional part. Either fits a float type when within its range.
+func representable(constant ExprType, target ExprType) error {
+
+	target = unwrapType(target)
+
+	var intValue *big.Int
+	var floatValue *big.Float
+
+	switch c := constant.(type) {
+	case UntypedInt:
+		intValue = c.Value
+		floatValue = new(big.Float).SetInt(c.Value)
+	case UntypedFloat:
+		floatValue = c.Value
+		if c.Value.IsInt() {
+			intValue, _ = c.Value.Int(nil)
+		}
+	}
+
+	switch t := target.(type) {
+	case Maybe:
+		return representable(constant, t.MaybeType)
+	case Result:
+		return representable(constant, t.OkType)
+	case Int:
+		if intValue == nil {
+			return fmt.Errorf("constant %s truncated to integer type '%s'", constantString(constant), tcValueToString(t))
+		}
+		min, max := intRange(t)
+		if intValue.Cmp(min) < 0 || intValue.Cmp(max) > 0 {
+			return fmt.Errorf("constant %s overflows '%s'", constantString(constant), tcValueToString(t))
+		}
+		return nil
+	case Float:
+		limit := big.NewFloat(math.MaxFloat64)
+		if t.BitSize == 32 {
+			limit = big.NewFloat(math.MaxFloat32)
+		}
+		if new(big.Float).Abs(floatValue).Cmp(limit) > 0 {
+			return fmt.Errorf("constant %s overflows '%s'", constantString(constant), tcValueToString(t))
+		}
+		return nil
+	}
+
+	return fmt.Errorf("cannot assign value of type '%s' to type '%s'", tcValueToString(constant), tcValueToString(target))
+}
+
+// intRange returns the smallest and largest value of the integer type
+func intRange(t Int) (*big.Int, *big.Int) {
+	if t.IsSigned {
+		max := new(big.Int).Lsh(big.NewInt(1), uint(t.BitSize-1))
+		min := new(big.Int).Neg(max)
+		return min, max.Sub(max, big.NewInt(1))
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), uint(t.BitSize))
+	return big.NewInt(0), max.Sub(max, big.NewInt(1))
+}
+
+func constantString(constant ExprType) string {
+	switch c := constant.(type) {
+	case UntypedInt:
+		return c.Value.String()
+	case UntypedFloat:
+		return c.Value.Text('g', -1)
+	default:
+		return tcValueToString(constant)
+	}
+}
+
+// foldConstants computes an arithmetic operation between two untyped constants, so 1 + 2 is the untyped constant 3.
+// It reports false if either operand is not an untyped constant or the operation cannot be folded.
+func foldConstants(node ast.BinaryExpr, left, right ExprType, env *TypeEnvironment) (ExprType, bool) {
+
+	if !isUntyped(left) || !isUntyped(right) {
+		return nil, false
+	}
+
+	op := node.Operator.Kind
+
+	leftInt, leftIsInt := left.(UntypedInt)
+	rightInt, rightIsInt := right.(UntypedInt)
+
+	if (op == lexer.DIV_TOKEN || op == lexer.MOD_TOKEN) && constantIsZero(right) {
+		errgen.Add(env.filePath, node.Right.StartPos().Line, node.Right.EndPos().Line, node.Right.StartPos().Column, node.Right.EndPos().Column, "division by zero").Level(errgen.NORMAL_ERROR)
+		return nil, false
+	}
+
+	if leftIsInt && rightIsInt {
+		result := new(big.Int)
+		switch op {
+		case lexer.PLUS_TOKEN:
+			result.Add(leftInt.Value, rightInt.Value)
+		case lexer.MINUS_TOKEN:
+			result.Sub(leftInt.Value, rightInt.Value)
+		case lexer.MUL_TOKEN:
+			result.Mul(leftInt.Value, rightInt.Value)
+		case lexer.DIV_TOKEN:
+			result.Quo(leftInt.Value, rightInt.Value)
+		case lexer.MOD_TOKEN:
+			result.Rem(leftInt.Value, rightInt.Value)
+		case lexer.BIT_AND_TOKEN:
+			result.And(leftInt.Value, rightInt.Value)
+		case lexer.BIT_OR_TOKEN:
+			result.Or(leftInt.Value, rightInt.Value)
+		case lexer.BIT_XOR_TOKEN:
+			result.Xor(leftInt.Value, rightInt.Value)
+		case lexer.SHIFT_LEFT_TOKEN, lexer.SHIFT_RIGHT_TOKEN:
+			count := rightInt.Value
+			if count.Sign() < 0 || count.Cmp(big.NewInt(maxConstantShift)) > 0 {
+				errgen.Add(env.filePath, node.Right.StartPos().Line, node.Right.EndPos().Line, node.Right.StartPos().Column, node.Right.EndPos().Column, fmt.Sprintf("invalid shift count %s", count.String())).Hint(fmt.Sprintf("constants can be shifted by 0 to %d", maxConstantShift)).Level(errgen.NORMAL_ERROR)
+				return left, true
+			}
+			if op == lexer.SHIFT_LEFT_TOKEN {
+				result.Lsh(leftInt.Value, uint(count.Uint64()))
+			} else {
+				result.Rsh(leftInt.Value, uint(count.Uint64()))
+			}
+		default:
+			return nil, false
+		}
+		return NewUntypedInt(result), true
+	}
+
+	leftFloat, rightFloat := constantToFloat(left), constantToFloat(right)
+	result := new(big.Float).SetPrec(constantPrecision)
+	switch op {
+	case lexer.PLUS_TOKEN:
+		result.Add(leftFloat, rightFloat)
+	case lexer.MINUS_TOKEN:
+		result.Sub(leftFloat, rightFloat)
+	case lexer.MUL_TOKEN:
+		result.Mul(leftFloat, rightFloat)
+	case lexer.DIV_TOKEN:
+		result.Quo(leftFloat, rightFloat)
+	default:
+		return nil, false
+	}
+	return NewUntypedFloat(result), true
+}
+
+func constantToFloat(constant ExprType) *big.Float {
+	switch c := constant.(type) {
+	case UntypedInt:
+		return new(big.Float).SetPrec(constantPrecision).SetInt(c.Value)
+	case UntypedFloat:
+		return c.Value
+	}
+	return nil
+}
+
+func constantIsZero(constant ExprType) bool {
+	switch c := constant.(type) {
+	case UntypedInt:
+		return c.Value.Sign() == 0
+	case UntypedFloat:
+		return c.Value.Sign() == 0
+	}
+	return false
+}
+
+// negateConstant returns the untyped constant with its sign flipped
+func negateConstant(constant ExprType) ExprType {
+	switch c := constant.(type) {
+	case UntypedInt:
+		return NewUntypedInt(new(big.Int).Neg(c.Value))
+	case UntypedFloat:
+		return NewUntypedFloat(new(big.Float).Neg(c.Value))
+	}
+	return constant
+}
+
+// complementConstant returns the bitwise not of the untyped integer constant, -x - 1
+func complementConstant(constant UntypedInt) ExprType {
+	return NewUntypedInt(new(big.Int).Not(constant.Value))
+}
+
+// convertOperands gives an untyped operand of a binary expression the type of the other operand.
+// Operands that are both untyped, or whose other side is not a number, take their default type.
+func convertOperands(node ast.BinaryExpr, left, right ExprType, env *TypeEnvironment) (ExprType, ExprType) {
+	left = convertOperand(node.Left, left, right, env)
+	right = convertOperand(node.Right, right, left, env)
+	return left, right
+}
+
+func convertOperand(operandNode ast.Node, operand, other ExprType, env *TypeEnvironment) ExprType {
+	if !isUntyped(operand) {
+		return operand
+	}
+
+	switch t := unwrapType(other).(type) {
+	case Int, Float:
+		if err := representable(operand, t); err != nil {
+			errgen.Add(env.filePath, operandNode.StartPos().Line, operandNode.EndPos().Line, operandNode.StartPos().Column, operandNode.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
+		}
+		return t
+	}
+
+	if _, ok := other.(UntypedFloat); ok {
+		// 1 < 2.5 compares as floats
+		return NewFloat(32)
+	}
+
+	return defaultTypeAt(operandNode, operand, env)
+}
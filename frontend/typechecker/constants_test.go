@@ -0,0 +1,126 @@
+package typechecker
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestUntypedConstantContexts(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		let a: u8 = 10;
+		let b: i8 = -128;
+		let c: f64 = 1;
+		let d: maybe{u16} = 65535;
+		let e: i64 = 0xFFFF_FFFF;
+		let f: f32 = 2.0 * 3;
+
+		fn takesByte(x: u8) -> u16 {
+			ret 300;
+		}
+		takesByte(255);
+
+		type ConstCounter struct {
+			count: u32,
+		};
+		let counter := @ConstCounter{ count: 4000000000 };
+
+		let m := $map[u8]i16 {
+			1 => -1,
+			255 => 32767,
+		};
+
+		let g: f32 = 1.5;
+		let h := g * 2;
+		let i: f32 = h;
+
+		let bytes: []u8 = [1, 2, 255];
+		let grid: [][]u8 = [[1], [2, 3]];
+		let floats: []f64 = [1, 2.5];
+		let maybeBytes: maybe{[]u8} = [7];
+		fn byteArray(xs: []u8) -> []u8 {
+			ret [0, xs[0]];
+		}
+		byteArray([9]);
+	`))
+}
+
+func TestUntypedConstantDefaults(t *testing.T) {
+	diagnostics := checkSource(t, `
+		let a := 10;
+		let b := 1.5;
+		let c: i32 = a;
+		let d: f32 = b;
+		let e: i64 = a;
+	`)
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 problem, got %v", diagnostics)
+	}
+
+	if diagnostics[0].LineStart != 6 {
+		t.Errorf("expected the problem on line 6, got %v", diagnostics[0])
+	}
+}
+
+func TestUntypedConstantOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+		col     int
+	}{
+		{"declaration", "let b: i8 = 300;", "error declaring variable 'b'. constant 300 overflows 'i8'", 13},
+		{"negative unsigned", "let b: u8 = -1;", "error declaring variable 'b'. constant -1 overflows 'u8'", 13},
+		{"folded", "let b: i8 = 100 + 100;", "error declaring variable 'b'. constant 200 overflows 'i8'", 13},
+		{"suffixed literal", "let b := 256u8;", "constant 256 overflows 'u8'", 10},
+		{"float to int", "let b: i32 = 2.5;", "error declaring variable 'b'. constant 2.5 truncated to integer type 'i32'", 14},
+		{"float overflow", "let b: f32 = 1e39;", "error declaring variable 'b'. constant 1e+39 overflows 'f32'", 14},
+		{"argument", "fn constArg(x: u8) {}\nconstArg(1000);", "constant 1000 overflows 'u8'", 10},
+		{"binary operand", "let x: u8 = 1;\nlet y := x < 256;", "constant 256 overflows 'u8'", 14},
+		{"cast", "let b := 300 as i8;", "constant 300 overflows 'i8'", 10},
+		{"default int", "let b := 3000000000;", "constant 3000000000 overflows 'i32'", 10},
+		{"default shift", "let b := 1 << 40;", "constant 1099511627776 overflows 'i32'", 10},
+		{"default float", "let b := 1e39;", "constant 1e+39 overflows 'f32'", 10},
+		{"return", "fn constRet() -> u8 {\n\tret 256;\n}", "constant 256 overflows 'u8'", 6},
+		{"array element", "let b: []u8 = [1, 256];", "constant 256 overflows 'u8'", 19},
+		{"division by zero", "let b := 1 / 0;", "division by zero", 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected 1 problem, got %v", diagnostics)
+			}
+			if diagnostics[0].Message != tt.message || diagnostics[0].ColStart != tt.col {
+				t.Errorf("expected %q at column %d, got %v", tt.message, tt.col, diagnostics[0])
+			}
+		})
+	}
+}
+
+func TestRepresentable(t *testing.T) {
+	tests := []struct {
+		name     string
+		constant ExprType
+		target   ExprType
+		fits     bool
+	}{
+		{"max i8", NewUntypedInt(big.NewInt(127)), NewInt(8, true), true},
+		{"min i8", NewUntypedInt(big.NewInt(-128)), NewInt(8, true), true},
+		{"above i8", NewUntypedInt(big.NewInt(128)), NewInt(8, true), false},
+		{"max u64", NewUntypedInt(new(big.Int).SetUint64(^uint64(0))), NewInt(64, false), true},
+		{"integral float to int", NewUntypedFloat(big.NewFloat(2)), NewInt(32, true), true},
+		{"int to float", NewUntypedInt(big.NewInt(3)), NewFloat(32), true},
+		{"int to str", NewUntypedInt(big.NewInt(3)), NewStr(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := representable(tt.constant, tt.target)
+			if (err == nil) != tt.fits {
+				t.Errorf("expected fits to be %v, got %v", tt.fits, err)
+			}
+		})
+	}
+}
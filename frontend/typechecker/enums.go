@@ -0,0 +1,81 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/utils"
+)
+
+// checkEnumTypeDecl evaluates the variants of an enum declaration. Variant names must be unique in the enum,
+// and field names unique in their variant.
+func checkEnumTypeDecl(name string, enumType ast.EnumType, env *TypeEnvironment) Enum {
+
+	enumEnv := NewTypeENV(env, STRUCT_SCOPE, name, env.filePath)
+
+	variants := make([]EnumVariant, 0, len(enumType.Variants))
+	declared := make(map[string]bool)
+
+	for _, variant := range enumType.Variants {
+		variantName := variant.Identifier
+
+		if declared[variantName.Name] {
+			errgen.Add(env.filePath, variantName.Start.Line, variantName.End.Line, variantName.Start.Column, variantName.End.Column, fmt.Sprintf("variant '%s' is already declared in enum '%s'", variantName.Name, name)).Level(errgen.NORMAL_ERROR)
+			continue
+		}
+		declared[variantName.Name] = true
+
+		fields := make([]FnParam, 0, len(variant.Fields))
+		for _, field := range variant.Fields {
+			if utils.Some(fields, func(f FnParam) bool { return f.Name == field.Field.Name }) {
+				errgen.Add(env.filePath, field.Field.Start.Line, field.Field.End.Line, field.Field.Start.Column, field.Field.End.Column, fmt.Sprintf("field '%s' is already declared in variant '%s'", field.Field.Name, variantName.Name)).Level(errgen.NORMAL_ERROR)
+				continue
+			}
+			fields = append(fields, FnParam{
+				Name: field.Field.Name,
+				Type: evaluateTypeName(field.FieldType, env),
+			})
+		}
+
+		variants = append(variants, EnumVariant{
+			Name:   variantName.Name,
+			Fields: fields,
+		})
+	}
+
+	enumTypeValue := Enum{
+		DataType:  ENUM_TYPE,
+		EnumName:  name,
+		Variants:  variants,
+		EnumScope: *enumEnv,
+	}
+
+	//declare 'this' variable to be used in the enum's methods
+	err := enumEnv.declareVar("this", enumTypeValue, true, false)
+	if err != nil {
+		errgen.Add(env.filePath, enumType.StartPos().Line, enumType.EndPos().Line, enumType.StartPos().Column, enumType.EndPos().Column, err.Error()).Level(errgen.CRITICAL_ERROR)
+	}
+
+	return enumTypeValue
+}
+
+// checkEnumVariantAccess checks Shape.Circle. A variant with fields is a constructor function that takes the
+// fields in order and returns the enum; a variant without fields is a value of the enum by itself.
+func checkEnumVariantAccess(enum Enum, variantName ast.IdentifierExpr, env *TypeEnvironment) ExprType {
+
+	variant, ok := enum.variant(variantName.Name)
+	if !ok {
+		errgen.Add(env.filePath, variantName.Start.Line, variantName.End.Line, variantName.Start.Column, variantName.End.Column, fmt.Sprintf("'%s' is not a variant of enum '%s'", variantName.Name, enum.EnumName)).Level(errgen.CRITICAL_ERROR)
+		return NewVoid()
+	}
+
+	if len(variant.Fields) == 0 {
+		return enum
+	}
+
+	return Fn{
+		DataType: FUNCTION_TYPE,
+		Params:   variant.Fields,
+		Returns:  enum,
+	}
+}
@@ -0,0 +1,68 @@
+package typechecker
+
+import (
+	"testing"
+)
+
+func TestEnums(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type EnumShape enum {
+			Circle(radius: f32),
+			Rect(w: f32, h: f32),
+			Empty,
+		};
+		impl EnumShape {
+			fn describe() -> str {
+				ret "shape";
+			}
+		}
+		let c := EnumShape.Circle(2.0);
+		let r: EnumShape = EnumShape.Rect(1, 2);
+		let e: EnumShape = EnumShape.Empty;
+		let d: str = c.describe();
+		let makeCircle := EnumShape.Circle;
+		let m: EnumShape = makeCircle(1.5);
+	`))
+}
+
+func TestEnumInterface(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type EnumNamed interface {
+			fn name() -> str;
+		};
+		type EnumColor enum {
+			Red,
+			Green,
+		};
+		impl EnumColor {
+			fn name() -> str {
+				ret "color";
+			}
+		}
+		let n: EnumNamed = EnumColor.Red;
+	`))
+}
+
+func TestEnumProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"unknown variant", "type EnumA enum { One }; let a := EnumA.Two;", "'Two' is not a variant of enum 'EnumA'"},
+		{"payload type", "type EnumB enum { One(n: i32) }; let b := EnumB.One(\"x\");", "cannot assign value of type 'str' to type 'i32'"},
+		{"payload count", "type EnumC enum { One(n: i32) }; let c := EnumC.One();", "function expects at least 1 arguments, got 0"},
+		{"duplicate variant", "type EnumD enum { One, One };", "variant 'One' is already declared in enum 'EnumD'"},
+		{"duplicate field", "type EnumE enum { One(n: i32, n: i32) };", "field 'n' is already declared in variant 'One'"},
+		{"no fields", "type EnumF enum { One(n: i32) }; let f := EnumF.One(1); let n := f.n;", "'n' does not exist on type 'EnumF'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
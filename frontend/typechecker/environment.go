@@ -34,19 +34,103 @@ var typeDefinitions = map[string]ExprType{
 	string(BOOLEAN_TYPE): NewBool(),
 	string(NULL_TYPE):    NewNull(),
 	string(VOID_TYPE):    NewVoid(),
+	STRINGER_INTERFACE:   stringer,
+	COMPARABLE_INTERFACE: comparableConstraint,
+	ERROR_INTERFACE:      errorInterface,
+}
+
+// STRINGER_INTERFACE is the builtin interface for values that can be embedded in an interpolated string
+const STRINGER_INTERFACE = "Stringer"
+
+var stringer = Interface{
+	DataType:      INTERFACE_TYPE,
+	InterfaceName: STRINGER_INTERFACE,
+	Methods: []InterfaceMethodType{
+		{
+			Name: "toString",
+			Method: Fn{
+				DataType: FUNCTION_TYPE,
+				Params:   []FnParam{},
+				Returns:  NewStr(),
+			},
+		},
+	},
+}
+
+// COMPARABLE_INTERFACE is the builtin constraint for type parameters whose values can be ordered with < <= > >=.
+// It is satisfied by the integer and float types.
+const COMPARABLE_INTERFACE = "Comparable"
+
+var comparableConstraint = Interface{
+	DataType:      INTERFACE_TYPE,
+	InterfaceName: COMPARABLE_INTERFACE,
+	Methods:       []InterfaceMethodType{},
+}
+
+// ERROR_INTERFACE is the builtin interface for the errors a result{T, E} carries: a method fn message() -> str
+// that describes what went wrong.
+const ERROR_INTERFACE = "error"
+const ERROR_METHOD = "message"
+
+var errorInterface = Interface{
+	DataType:      INTERFACE_TYPE,
+	InterfaceName: ERROR_INTERFACE,
+	Methods: []InterfaceMethodType{
+		{
+			Name: ERROR_METHOD,
+			Method: Fn{
+				DataType: FUNCTION_TYPE,
+				Params:   []FnParam{},
+				Returns:  NewStr(),
+			},
+		},
+	},
+}
+
+// ITERATOR_INTERFACE is the builtin protocol foreach uses to walk a struct: a method fn next() -> maybe{T}
+// that returns null once there are no elements left.
+const ITERATOR_INTERFACE = "Iterator"
+const ITERATOR_METHOD = "next"
+
+// iterator returns the Iterator interface for elements of the given type
+func iterator(elementType ExprType) Interface {
+	return Interface{
+		DataType:      INTERFACE_TYPE,
+		InterfaceName: ITERATOR_INTERFACE,
+		Methods: []InterfaceMethodType{
+			{
+				Name: ITERATOR_METHOD,
+				Method: Fn{
+					DataType: FUNCTION_TYPE,
+					Params:   []FnParam{},
+					Returns:  NewMaybe(elementType),
+				},
+			},
+		},
+	}
 }
 
 var builtinValues = make(map[string]bool)
 
 type TypeEnvironment struct {
-	parent     *TypeEnvironment
-	scopeType  SCOPE_TYPE
-	scopeName  string
-	variables  map[string]ExprType
-	constants  map[string]bool
-	isOptional map[string]bool
-	interfaces map[string]Interface
-	filePath   string
+	parent        *TypeEnvironment
+	scopeType     SCOPE_TYPE
+	scopeName     string
+	variables     map[string]ExprType
+	constants     map[string]bool
+	isOptional    map[string]bool
+	interfaces    map[string]Interface
+	typeParams    map[string]TypeParam // type parameters of the generic function or type the scope belongs to
+	types         map[string]ExprType  // types declared in the scope. the builtin types are in typeDefinitions
+	packages      map[string]Package   // packages the files of a package can import, by import path. nil outside a program
+	public        map[string]bool      // top-level variables, constants and functions declared with pub
+	publicType    map[string]bool      // top-level types declared with pub
+	narrowed      map[string]ExprType  // maybe variables of this or an enclosing scope that are known not to be null here
+	assigned      map[string]bool      // variables assigned in the body of a loop, for LOOP_SCOPE environments
+	fnAssigned    map[string]bool      // variables assigned in the functions nested in a function or package, for FUNCTION_SCOPE and GLOBAL_SCOPE environments
+	runtimeChecks []RuntimeCheck       // sites checked at runtime, kept in the top-level scope of a package
+	filePath      string
+	loopLabel     string // label of the loop, for LOOP_SCOPE environments
 }
 
 func ProgramEnv(filepath string) *TypeEnvironment {
@@ -80,6 +164,11 @@ func NewTypeENV(parent *TypeEnvironment, scope SCOPE_TYPE, scopeName string, fil
 		constants:  make(map[string]bool),
 		isOptional: make(map[string]bool),
 		interfaces: make(map[string]Interface),
+		typeParams: make(map[string]TypeParam),
+		types:      make(map[string]ExprType),
+		public:     make(map[string]bool),
+		publicType: make(map[string]bool),
+		narrowed:   make(map[string]ExprType),
 	}
 }
 
@@ -93,14 +182,39 @@ func (t *TypeEnvironment) isInFunctionScope() bool {
 	return t.parent.isInFunctionScope()
 }
 
-func (t *TypeEnvironment) isInStructScope() bool {
-	if t.scopeType == STRUCT_SCOPE {
-		return true
+// isInMethodOf reports whether the scope is inside a method of the struct or enum that owns the given members scope.
+// Closures in a method count as the method, but the methods of any other type do not.
+func (t *TypeEnvironment) isInMethodOf(owner TypeEnvironment) bool {
+	for env := t; env != nil; env = env.parent {
+		if env.scopeType == STRUCT_SCOPE {
+			// a type is declared once in its scope, so its name and that scope tell it apart from every other type
+			return env.scopeName == owner.scopeName && env.parent == owner.parent
+		}
 	}
-	if t.parent == nil {
-		return false
+	return false
+}
+
+// packageScope returns the top-level scope of the package the scope is in, the one just inside the builtin scope
+func (t *TypeEnvironment) packageScope() *TypeEnvironment {
+	env := t
+	for env.parent != nil && env.parent.parent != nil {
+		env = env.parent
 	}
-	return t.parent.isInStructScope()
+	return env
+}
+
+// resolveLoop finds the loop a break or continue refers to: the innermost loop, or the one with the given label.
+// The search stops at a function, so a closure cannot leave a loop that it is declared in.
+func (t *TypeEnvironment) resolveLoop(label string) (*TypeEnvironment, bool) {
+	for env := t; env != nil; env = env.parent {
+		if env.scopeType == LOOP_SCOPE && (label == "" || env.loopLabel == label) {
+			return env, true
+		}
+		if env.scopeType == FUNCTION_SCOPE {
+			break
+		}
+	}
+	return nil, false
 }
 
 func (t *TypeEnvironment) resolveFunctionEnv() (*TypeEnvironment, error) {
@@ -128,9 +242,61 @@ func (t *TypeEnvironment) resolveVar(name string) (*TypeEnvironment, error) {
 	return t.parent.resolveVar(name)
 }
 
+// narrow gives a maybe variable the type of its value for the rest of this scope, once it is known not to be null.
+// A variable that a nested function assigns is not narrowed, as a call to the function may make it null at any point.
+func (t *TypeEnvironment) narrow(name string, valueType ExprType) {
+	if t.assignedByFunction(name) {
+		return
+	}
+	t.narrowed[name] = valueType
+}
+
+// assignedByFunction reports whether a function nested in the function or package that declares the variable assigns it
+func (t *TypeEnvironment) assignedByFunction(name string) bool {
+	scope, err := t.resolveVar(name)
+	if err != nil {
+		return false
+	}
+	// the packages that import a pub variable can assign it too
+	if scope.public[name] && !scope.constants[name] {
+		return true
+	}
+	for env := scope; env != nil; env = env.parent {
+		if env.fnAssigned != nil {
+			return env.fnAssigned[name]
+		}
+	}
+	return false
+}
+
+// narrowedType finds the type a maybe variable is narrowed to where it is used. A function may be called after the
+// variable is null again, so the narrowing of a scope outside of it is not used in it. When a loop assigns the variable,
+// its next iteration may see it null again, so the narrowing of a scope outside of the loop is not used in it either.
+func (t *TypeEnvironment) narrowedType(name string) (ExprType, bool) {
+	for env := t; env != nil; env = env.parent {
+		if valueType, ok := env.narrowed[name]; ok {
+			return valueType, true
+		}
+		if env.isDeclared(name) || env.scopeType == FUNCTION_SCOPE || env.assigned[name] {
+			break
+		}
+	}
+	return nil, false
+}
+
+// widen forgets the narrowing of a variable that may be null again, in every scope up to the one it is declared in
+func (t *TypeEnvironment) widen(name string) {
+	for env := t; env != nil; env = env.parent {
+		delete(env.narrowed, name)
+		if env.isDeclared(name) {
+			break
+		}
+	}
+}
+
 func (t *TypeEnvironment) declareVar(name string, typeVar ExprType, isConst bool, isOptional bool) error {
 
-	if _, ok := typeDefinitions[name]; ok && name != "null" && name != "void" {
+	if t.isTypeDefined(name) && name != "null" && name != "void" {
 		return fmt.Errorf("type name '%s' cannot be used as variable name", name)
 	}
 
@@ -147,17 +313,42 @@ func (t *TypeEnvironment) declareVar(name string, typeVar ExprType, isConst bool
 	t.constants[name] = isConst
 	t.isOptional[name] = isOptional
 
+	// a new variable with the name of a narrowed one is not narrowed
+	delete(t.narrowed, name)
+
 	return nil
 }
 
-func declareType(name string, typeType ExprType) error {
-	if _, ok := typeDefinitions[name]; ok {
+// declareType declares a type in this scope. The name must not be a builtin type or a type of an enclosing scope.
+func (t *TypeEnvironment) declareType(name string, typeType ExprType) error {
+	if t.isTypeDefined(name) {
 		return fmt.Errorf("type '%s' is already defined", name)
 	}
-	typeDefinitions[name] = typeType
+	t.types[name] = typeType
 	return nil
 }
 
+// resolveTypeParam finds the type parameter with the given name in this scope or an enclosing one
+func (t *TypeEnvironment) resolveTypeParam(name string) (TypeParam, bool) {
+	for env := t; env != nil; env = env.parent {
+		if param, ok := env.typeParams[name]; ok {
+			return param, true
+		}
+	}
+	return TypeParam{}, false
+}
+
+// resolveImport finds the package with the given import path among the packages this scope's program can import
+func (t *TypeEnvironment) resolveImport(path string) (Package, bool) {
+	for env := t; env != nil; env = env.parent {
+		if env.packages != nil {
+			pkg, ok := env.packages[path]
+			return pkg, ok
+		}
+	}
+	return Package{}, false
+}
+
 func (t *TypeEnvironment) isDeclared(name string) bool {
 	if _, ok := t.variables[name]; ok {
 		return true
@@ -165,8 +356,19 @@ func (t *TypeEnvironment) isDeclared(name string) bool {
 	return false
 }
 
-func getTypeDefinition(name string) (ExprType, error) {
-	if typ, ok := typeDefinitions[name]; !ok {
+// resolveType finds the declared type with the given name in this scope, an enclosing one or the builtin types
+func (t *TypeEnvironment) resolveType(name string) (ExprType, bool) {
+	for env := t; env != nil; env = env.parent {
+		if typ, ok := env.types[name]; ok {
+			return typ, true
+		}
+	}
+	typ, ok := typeDefinitions[name]
+	return typ, ok
+}
+
+func (t *TypeEnvironment) getTypeDefinition(name string) (ExprType, error) {
+	if typ, ok := t.resolveType(name); !ok {
 		return nil, fmt.Errorf("unknown type '%s'", name)
 	} else {
 		return unwrapType(typ), nil
@@ -177,12 +379,14 @@ func unwrapType(value ExprType) ExprType {
 	switch t := value.(type) {
 	case UserDefined:
 		return unwrapType(t.TypeDef)
+	case StructProperty:
+		return unwrapType(t.Type)
 	default:
 		return t
 	}
 }
 
-func isTypeDefined(name string) bool {
-	_, ok := typeDefinitions[name]
+func (t *TypeEnvironment) isTypeDefined(name string) bool {
+	_, ok := t.resolveType(name)
 	return ok
 }
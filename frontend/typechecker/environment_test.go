@@ -69,26 +69,39 @@ func TestResolveVar(t *testing.T) {
 }
 
 func TestDeclareType(t *testing.T) {
+	env := NewTypeENV(nil, GLOBAL_SCOPE, "global", FILE)
 	structType := Struct{DataType: STRUCT_TYPE, StructName: "MyStruct"}
 
-	err := declareType("MyStruct", structType)
+	err := env.declareType("MyStruct", structType)
 	if err != nil {
 		t.Fatalf(EXPECTED_NO_ERROR, err)
 	}
 
-	if _, err := getTypeDefinition("MyStruct"); err != nil {
+	if _, err := env.getTypeDefinition("MyStruct"); err != nil {
 		t.Errorf("Expected type 'MyStruct' to be declared")
 	}
+
+	if err := env.declareType("MyStruct", structType); err == nil {
+		t.Errorf(EXPECTED_ERROR)
+	}
 }
 
 func TestResolveType(t *testing.T) {
+	env := NewTypeENV(nil, GLOBAL_SCOPE, "global", FILE)
 	structType := Struct{DataType: STRUCT_TYPE, StructName: "MyStruct"}
-	declareType("MyStruct", structType)
+	env.declareType("MyStruct", structType)
 
-	_, err := getTypeDefinition("MyStruct")
+	funcEnv := NewTypeENV(env, FUNCTION_SCOPE, "function", FILE)
+	_, err := funcEnv.getTypeDefinition("MyStruct")
 	if err != nil {
 		t.Fatalf(EXPECTED_NO_ERROR, err)
 	}
+
+	// a type is only known in the scope it is declared in and the scopes inside it
+	otherEnv := NewTypeENV(nil, GLOBAL_SCOPE, "other", FILE)
+	if _, err := otherEnv.getTypeDefinition("MyStruct"); err == nil {
+		t.Errorf(EXPECTED_ERROR)
+	}
 }
 
 func TestResolveFunctionEnv(t *testing.T) {
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"walrus/errgen"
 	"walrus/frontend/ast"
-	"walrus/frontend/builtins"
 	"walrus/frontend/lexer"
 	"walrus/utils"
 )
@@ -14,7 +13,7 @@ func checkIncrementalExpr(node ast.IncrementalInterface, env *TypeEnvironment) E
 	arg := node.Arg()
 	// the argument must be an identifier evaluated to a number
 	typeVal := parseNodeValue(arg, env)
-	if !isNumberType(typeVal) {
+	if !isNumberType(typeVal) && !isUnknown(typeVal) {
 
 		errgen.Add(env.filePath, arg.StartPos().Line, arg.EndPos().Line, arg.StartPos().Column, arg.EndPos().Column, "invalid prefix operation with non-numeric type").Level(errgen.NORMAL_ERROR)
 	}
@@ -37,6 +36,17 @@ func checkTypeCast(node ast.TypeCastExpr, env *TypeEnvironment) ExprType {
 	originalType := parseNodeValue(node.Expression, env)
 	toCast := evaluateTypeName(node.ToCast, env)
 
+	if isUnknown(originalType) {
+		return toCast
+	}
+
+	if isUntyped(originalType) && isNumberType(toCast) {
+		if err := representable(originalType, toCast); err != nil {
+			errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+		}
+		return toCast
+	}
+
 	if originalType.DType() == toCast.DType() {
 		logCastSuccess(originalType, toCast)
 		return originalType
@@ -58,13 +68,36 @@ func checkUnaryExpr(node ast.UnaryExpr, env *TypeEnvironment) ExprType {
 	//evaluate argument. must be evaluated to number or boolean for ! (not)
 	typeVal := parseNodeValue(arg, env)
 
-	switch t := typeVal.(type) {
+	switch t := unwrapType(typeVal).(type) {
+	case Unknown:
+		return typeVal
 	case Int, Float:
-		//allow - only
-		if op.Kind != lexer.MINUS_TOKEN {
-
+		//allow - and ~ only
+		switch op.Kind {
+		case lexer.MINUS_TOKEN:
+			if intType, ok := t.(Int); ok && !intType.IsSigned {
+				errgen.Add(env.filePath, op.Start.Line, op.End.Line, op.Start.Column, op.End.Column, fmt.Sprintf("cannot negate unsigned type '%s'", tcValueToString(t))).Level(errgen.NORMAL_ERROR)
+			}
+		case lexer.BIT_XOR_TOKEN:
+			if !isIntType(t) {
+				errgen.Add(env.filePath, op.Start.Line, op.End.Line, op.Start.Column, op.End.Column, fmt.Sprintf("operator '~' is only defined on integers, got '%s'", tcValueToString(t))).Level(errgen.NORMAL_ERROR)
+			}
+		default:
+			errgen.Add(env.filePath, op.Start.Line, op.End.Line, op.Start.Column, op.End.Column, "invalid unary operation with numeric types").Level(errgen.NORMAL_ERROR)
+		}
+	case UntypedInt, UntypedFloat:
+		switch op.Kind {
+		case lexer.MINUS_TOKEN:
+			return negateConstant(t)
+		case lexer.BIT_XOR_TOKEN:
+			if intConstant, ok := t.(UntypedInt); ok {
+				return complementConstant(intConstant)
+			}
+			errgen.Add(env.filePath, op.Start.Line, op.End.Line, op.Start.Column, op.End.Column, fmt.Sprintf("operator '~' is only defined on integers, got '%s'", tcValueToString(t))).Level(errgen.NORMAL_ERROR)
+		default:
 			errgen.Add(env.filePath, op.Start.Line, op.End.Line, op.Start.Column, op.End.Column, "invalid unary operation with numeric types").Level(errgen.NORMAL_ERROR)
 		}
+		return typeVal
 	case Bool:
 		if op.Kind != lexer.NOT_TOKEN {
 
@@ -78,48 +111,48 @@ func checkUnaryExpr(node ast.UnaryExpr, env *TypeEnvironment) ExprType {
 }
 
 func checkBinaryExpr(node ast.BinaryExpr, env *TypeEnvironment) ExprType {
-	op := node.Operator
 
 	left := parseNodeValue(node.Left, env)
 	right := parseNodeValue(node.Right, env)
 
-	leftType := tcValueToString(left)
-	rightType := tcValueToString(right)
+	return checkBinaryOperands(node, left, right, env)
+}
 
-	var errLineStart, errLineEnd, errStart, errEnd int
-	var errMsg string
+// checkBinaryOperands checks the operator of a binary expression against operand types that are already evaluated.
+// Compound assignments like a += b use it with the type of the assignee as the left operand.
+func checkBinaryOperands(node ast.BinaryExpr, left ExprType, right ExprType, env *TypeEnvironment) ExprType {
+	op := node.Operator
 
-	switch op.Kind {
-	case lexer.PLUS_TOKEN:
-		return checkAdditionAndConcat(node, left, right, env)
-	case lexer.MINUS_TOKEN, lexer.MUL_TOKEN, lexer.DIV_TOKEN, lexer.MOD_TOKEN, lexer.EXP_TOKEN:
-		//must have to be numeric type on both side
-		if leftType != builtins.INT32 && leftType != builtins.FLOAT32 {
-			errMsg = fmt.Sprintf("cannot perform numeric operation between type '%s' and '%s'. left hand side expression must be evaluated to a numeric type.", leftType, rightType)
-			errLineStart = node.Left.StartPos().Line
-			errLineEnd = node.Left.EndPos().Line
-			errStart = node.Left.StartPos().Column
-			errEnd = node.Left.EndPos().Column
-		} else if rightType != builtins.INT32 && rightType != builtins.FLOAT32 {
-			errMsg = fmt.Sprintf("cannot perform numeric operation between type '%s' and '%s'. right hand side expression must be evaluated to a numeric type.", leftType, rightType)
-			errLineStart = node.Right.StartPos().Line
-			errLineEnd = node.Right.EndPos().Line
-			errStart = node.Right.StartPos().Column
-			errEnd = node.Right.EndPos().Column
-		} else {
-			return left
+	// the problem that made an operand unknown is already reported
+	if isUnknown(left) || isUnknown(right) {
+		switch op.Kind {
+		case lexer.DOUBLE_EQUAL_TOKEN, lexer.NOT_EQUAL_TOKEN, lexer.LESS_EQUAL_TOKEN, lexer.LESS_TOKEN, lexer.GREATER_EQUAL_TOKEN, lexer.GREATER_TOKEN:
+			return NewBool()
 		}
+		return NewUnknown()
+	}
+
+	if folded, ok := foldConstants(node, left, right, env); ok {
+		return folded
+	}
+
+	if op.Kind == lexer.SHIFT_LEFT_TOKEN || op.Kind == lexer.SHIFT_RIGHT_TOKEN {
+		// the count does not take the type of the shifted value, so the operands are not converted to each other
+		return checkShift(node, left, right, env)
+	}
+
+	left, right = convertOperands(node, left, right, env)
+
+	switch op.Kind {
+	case lexer.PLUS_TOKEN, lexer.MINUS_TOKEN, lexer.MUL_TOKEN, lexer.DIV_TOKEN, lexer.MOD_TOKEN, lexer.EXP_TOKEN:
+		return checkArithmetic(node, left, right, env)
+	case lexer.BIT_AND_TOKEN, lexer.BIT_OR_TOKEN, lexer.BIT_XOR_TOKEN:
+		return checkBitwise(node, left, right, env)
 	case lexer.DOUBLE_EQUAL_TOKEN, lexer.NOT_EQUAL_TOKEN, lexer.LESS_EQUAL_TOKEN, lexer.LESS_TOKEN, lexer.GREATER_EQUAL_TOKEN, lexer.GREATER_TOKEN:
 		return checkComparison(node, left, right, env)
-	default:
-		errMsg = "invalid operator"
-		errLineStart = op.Start.Line
-		errLineEnd = op.End.Line
-		errStart = op.Start.Column
-		errEnd = op.End.Column
 	}
 
-	errgen.Add(env.filePath, errLineStart, errLineEnd, errStart, errEnd, errMsg).Level(errgen.NORMAL_ERROR)
+	errgen.Add(env.filePath, op.Start.Line, op.End.Line, op.Start.Column, op.End.Column, "invalid operator").Level(errgen.NORMAL_ERROR)
 	return left
 }
 
@@ -133,55 +166,245 @@ func checkComparison(node ast.BinaryExpr, left ExprType, right ExprType, env *Ty
 	boolean := NewBool()
 
 	if op.Kind == lexer.DOUBLE_EQUAL_TOKEN || op.Kind == lexer.NOT_EQUAL_TOKEN {
-		// ( ==, != ) allow every type
-		if isNumberType(left) && isNumberType(right) {
-			return boolean
-		} else if leftType == rightType {
+		// ( ==, != ) allow every type, as long as both sides have the same type. A maybe can also be compared with null
+		if leftType == rightType || isNullComparison(left, right) || isNullComparison(right, left) {
 			return boolean
 		}
 	} else {
-		// ( >=, >, <=, < ) allow only numeric types
-		if isNumberType(left) && isNumberType(right) {
+		// ( >=, >, <=, < ) allow only numeric types, or type parameters constrained by Comparable, of the same type
+		if isOrdered(left) && isOrdered(right) && leftType == rightType {
 			return boolean
 		}
 	}
 	errMsg := fmt.Sprintf("invalid compare operation between '%s' and '%s'", leftType, rightType)
 
 	errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Level(errgen.NORMAL_ERROR)
+	return boolean
+}
+
+// isNullComparison reports whether a maybe value is compared with null
+func isNullComparison(value ExprType, other ExprType) bool {
+	_, isMaybe := unwrapType(value).(Maybe)
+	return isMaybe && other.DType() == NULL_TYPE
+}
+
+// checkArithmetic checks the operators + - * / % ^.
+// Both operands must have the same numeric type, which is also the type of the result: mixing widths,
+// signedness or ints with floats needs an explicit cast. % is only defined for integers. + also concatenates two strings.
+func checkArithmetic(node ast.BinaryExpr, left ExprType, right ExprType, env *TypeEnvironment) ExprType {
+
+	op := node.Operator
+
+	left = unwrapType(left)
+	right = unwrapType(right)
+
+	leftType := tcValueToString(left)
+	rightType := tcValueToString(right)
+
+	if op.Kind == lexer.PLUS_TOKEN && (left.DType() == STRING_TYPE || right.DType() == STRING_TYPE) {
+		if left.DType() != right.DType() {
+			errMsg := fmt.Sprintf("cannot concatenate '%s' and '%s'", leftType, rightType)
+			errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Hint("embed the value in a string instead, like \"text {value}\"").Level(errgen.NORMAL_ERROR)
+		}
+		return NewStr()
+	}
+
+	if !isNumberType(left) || !isNumberType(right) {
+		errMsg := fmt.Sprintf("operator '%s' is not defined between '%s' and '%s'", op.Value, leftType, rightType)
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Level(errgen.NORMAL_ERROR)
+		return left
+	}
+
+	if leftType != rightType {
+		errMsg := fmt.Sprintf("mismatched types '%s' and '%s' for operator '%s'", leftType, rightType, op.Value)
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Hint(fmt.Sprintf("convert one side with 'as', like (value as %s)", leftType)).Level(errgen.NORMAL_ERROR)
+		return left
+	}
+
+	if op.Kind == lexer.MOD_TOKEN && !isIntType(left) {
+		errMsg := fmt.Sprintf("operator '%%' is only defined between integers, got '%s' and '%s'", leftType, rightType)
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Level(errgen.NORMAL_ERROR)
+	}
+
 	return left
 }
 
-func checkAdditionAndConcat(node ast.BinaryExpr, left ExprType, right ExprType, env *TypeEnvironment) ExprType {
+// checkBitwise checks the operators & | ~. Both operands must have the same integer type, which is also the type of the result.
+func checkBitwise(node ast.BinaryExpr, left ExprType, right ExprType, env *TypeEnvironment) ExprType {
+
+	op := node.Operator
+
+	left = unwrapType(left)
+	right = unwrapType(right)
 
 	leftType := tcValueToString(left)
 	rightType := tcValueToString(right)
 
-	var errLineStart, errLineEnd, errStart, errEnd int
-	var errMsg string
-	//only string concat, int and floats are allowed.
-	if leftType == builtins.INT32 || leftType == builtins.FLOAT32 {
-		//right has to be int or float
-		if rightType != builtins.INT32 && rightType != builtins.FLOAT32 {
-			errMsg = fmt.Sprintf("cannot perform numeric operation between type '%s' and '%s'. right hand side expression must be evaluated to a numeric type.", leftType, rightType)
-			errLineStart = node.Right.StartPos().Line
-			errLineEnd = node.Right.EndPos().Line
-			errStart = node.Right.StartPos().Column
-			errEnd = node.Right.EndPos().Column
-		} else {
-			//return the type if left
-			return left
-		}
-	} else if leftType == builtins.STRING {
-		// we concat the type
+	if !isIntType(left) || !isIntType(right) {
+		errMsg := fmt.Sprintf("operator '%s' is only defined between integers, got '%s' and '%s'", op.Value, leftType, rightType)
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Level(errgen.NORMAL_ERROR)
 		return left
-	} else {
-		errMsg = "invalid expression"
-		errLineStart = node.Start.Line
-		errLineEnd = node.End.Line
-		errStart = node.StartPos().Column
-		errEnd = node.EndPos().Column
 	}
 
-	errgen.Add(env.filePath, errLineStart, errLineEnd, errStart, errEnd, errMsg).Level(errgen.NORMAL_ERROR)
+	if leftType != rightType {
+		errMsg := fmt.Sprintf("mismatched types '%s' and '%s' for operator '%s'", leftType, rightType, op.Value)
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Hint(fmt.Sprintf("convert one side with 'as', like (value as %s)", leftType)).Level(errgen.NORMAL_ERROR)
+	}
+
+	return left
+}
+
+// checkShift checks the operators << and >>. The shifted value must be an integer and gives the type of the result.
+// The count must be an unsigned integer or a non-negative constant, of any width.
+func checkShift(node ast.BinaryExpr, left ExprType, right ExprType, env *TypeEnvironment) ExprType {
+
+	op := node.Operator
+
+	left = unwrapType(defaultTypeAt(node.Left, left, env))
+
+	if !isIntType(left) {
+		errMsg := fmt.Sprintf("operator '%s' is only defined on integers, got '%s'", op.Value, tcValueToString(left))
+		errgen.Add(env.filePath, node.Left.StartPos().Line, node.Left.EndPos().Line, node.Left.StartPos().Column, node.Left.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+	}
+
+	count := node.Right
+	switch t := unwrapType(right).(type) {
+	case UntypedInt:
+		if t.Value.Sign() < 0 {
+			errgen.Add(env.filePath, count.StartPos().Line, count.EndPos().Line, count.StartPos().Column, count.EndPos().Column, fmt.Sprintf("negative shift count %s", t.Value.String())).Level(errgen.NORMAL_ERROR)
+		}
+	case Int:
+		if t.IsSigned {
+			errMsg := fmt.Sprintf("shift count must be an unsigned integer, got '%s'", tcValueToString(t))
+			errgen.Add(env.filePath, count.StartPos().Line, count.EndPos().Line, count.StartPos().Column, count.EndPos().Column, errMsg).Hint("cast the count, like (count as u32)").Level(errgen.NORMAL_ERROR)
+		}
+	default:
+		errMsg := fmt.Sprintf("shift count must be an unsigned integer, got '%s'", tcValueToString(right))
+		errgen.Add(env.filePath, count.StartPos().Line, count.EndPos().Line, count.StartPos().Column, count.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+	}
+
 	return left
 }
+
+// checkLogicalExpr checks && and ||. Both sides must be bool.
+// The right side is checked where the left side is true for && and false for ||, so in a != null && a > 0, a is not a maybe on the right.
+func checkLogicalExpr(node ast.LogicalExpr, env *TypeEnvironment) ExprType {
+
+	op := node.Operator
+
+	whenTrue, whenFalse := conditionNarrowing(node.Left, env)
+	rightEnv := narrowedEnv(env, "condition", whenTrue)
+	if op.Kind == lexer.OR_TOKEN {
+		rightEnv = narrowedEnv(env, "condition", whenFalse)
+	}
+
+	for i, operand := range []ast.Node{node.Left, node.Right} {
+		operandEnv := env
+		if i == 1 {
+			operandEnv = rightEnv
+		}
+		operandType := parseNodeValue(operand, operandEnv)
+		if _, ok := unwrapType(operandType).(Bool); ok || isUnknown(operandType) {
+			continue
+		}
+		errMsg := fmt.Sprintf("operator '%s' expects 'bool' operands, got '%s'", op.Value, tcValueToString(operandType))
+		errgen.Add(env.filePath, operand.StartPos().Line, operand.EndPos().Line, operand.StartPos().Column, operand.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+	}
+
+	return NewBool()
+}
+
+// checkRangeExpr checks start..end and start..=end. Both bounds must have the same integer type, which is the
+// element type of the range; an untyped bound takes the type of the other bound, or of the step when both are untyped.
+// A range of untyped constants stays untyped until its context gives it a type, like 0..10 passed as a range{u8}.
+// A step must be an integer of the element type and cannot be a zero constant.
+func checkRangeExpr(node ast.RangeExpr, env *TypeEnvironment) ExprType {
+
+	start := parseNodeValue(node.Start, env)
+	end := parseNodeValue(node.End, env)
+
+	var step ExprType
+	if node.Step != nil {
+		step = parseNodeValue(node.Step, env)
+		if constant, ok := step.(UntypedInt); ok && constant.Value.Sign() == 0 {
+			errgen.Add(env.filePath, node.Step.StartPos().Line, node.Step.EndPos().Line, node.Step.StartPos().Column, node.Step.EndPos().Column, "range step cannot be zero").Level(errgen.NORMAL_ERROR)
+		}
+	}
+
+	startConstant, startIsConstant := start.(UntypedInt)
+	endConstant, endIsConstant := end.(UntypedInt)
+
+	if startIsConstant && endIsConstant {
+		if step == nil || isUntyped(step) {
+			if _, ok := step.(UntypedFloat); ok {
+				errMsg := fmt.Sprintf("range step must be an integer, got '%s'", tcValueToString(step))
+				errgen.Add(env.filePath, node.Step.StartPos().Line, node.Step.EndPos().Line, node.Step.StartPos().Column, node.Step.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+			}
+			return Range{DataType: RANGE_TYPE, ElementType: startConstant, Bounds: []UntypedInt{startConstant, endConstant}}
+		}
+		// 10..0 step s counts with the type of s
+		start = convertOperand(node.Start, start, step, env)
+		end = convertOperand(node.End, end, step, env)
+	} else {
+		start = convertOperand(node.Start, start, end, env)
+		end = convertOperand(node.End, end, start, env)
+	}
+
+	startType := tcValueToString(start)
+	endType := tcValueToString(end)
+
+	elementType := unwrapType(start)
+
+	if !isIntType(start) || !isIntType(end) {
+		errMsg := fmt.Sprintf("range bounds must be integers, got '%s' and '%s'", startType, endType)
+		errgen.Add(env.filePath, node.Start.StartPos().Line, node.End.EndPos().Line, node.Start.StartPos().Column, node.End.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+		return NewRange(elementType)
+	}
+
+	if startType != endType {
+		errMsg := fmt.Sprintf("mismatched range bounds '%s' and '%s'", startType, endType)
+		errgen.Add(env.filePath, node.Start.StartPos().Line, node.End.EndPos().Line, node.Start.StartPos().Column, node.End.EndPos().Column, errMsg).Hint(fmt.Sprintf("convert one bound with 'as', like (value as %s)", startType)).Level(errgen.NORMAL_ERROR)
+		return NewRange(elementType)
+	}
+
+	if step == nil {
+		return NewRange(elementType)
+	}
+
+	stepNode := node.Step
+	step = convertOperand(stepNode, step, elementType, env)
+
+	if !isIntType(step) {
+		errMsg := fmt.Sprintf("range step must be an integer, got '%s'", tcValueToString(step))
+		errgen.Add(env.filePath, stepNode.StartPos().Line, stepNode.EndPos().Line, stepNode.StartPos().Column, stepNode.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+	} else if stepType := tcValueToString(step); stepType != startType {
+		errMsg := fmt.Sprintf("mismatched types '%s' and '%s' for range step", startType, stepType)
+		errgen.Add(env.filePath, stepNode.StartPos().Line, stepNode.EndPos().Line, stepNode.StartPos().Column, stepNode.EndPos().Column, errMsg).Hint(fmt.Sprintf("convert the step with 'as', like (step as %s)", startType)).Level(errgen.NORMAL_ERROR)
+	}
+
+	return NewRange(elementType)
+}
+
+// checkInterpolatedString checks the expressions embedded in an interpolated string.
+// Numeric, bool and str values can be embedded, as can any type that implements Stringer.
+func checkInterpolatedString(node ast.InterpolatedStringExpr, env *TypeEnvironment) ExprType {
+	for _, part := range node.Parts {
+		partType := parseNodeValue(part, env)
+
+		valueType := unwrapType(partType)
+
+		switch valueType.(type) {
+		case Int, Float, Bool, Str, UntypedInt, UntypedFloat, Unknown:
+			continue
+		}
+
+		if len(checkMethodsImplementations(stringer, valueType)) == 0 {
+			continue
+		}
+
+		errMsg := fmt.Sprintf("cannot embed value of type '%s' in a string", tcValueToString(partType))
+		errgen.Add(env.filePath, part.StartPos().Line, part.EndPos().Line, part.StartPos().Column, part.EndPos().Column, errMsg).Hint(fmt.Sprintf("implement '%s' with a method toString() -> str", STRINGER_INTERFACE)).Level(errgen.NORMAL_ERROR)
+	}
+
+	return NewStr()
+}
@@ -0,0 +1,89 @@
+package typechecker
+
+import (
+	"testing"
+)
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"i64", "let a: i64 = 1; let b: i64 = 2; let c: i64 = a + b * a - b / a;", ""},
+		{"u8", "let a: u8 = 1; let b: u8 = 2; let c: u8 = a % b;", ""},
+		{"f64", "let a: f64 = 1; let b: f64 = 2; let c: f64 = a ^ b;", ""},
+		{"int power", "let a: u16 = 2; let c: u16 = a ^ 3;", ""},
+		{"cast", "let a: i32 = 1; let b: f32 = 2; let c: f32 = a as f32 + b;", ""},
+		{"concat", `let a := "a" + "b";`, ""},
+		{"mixed kinds", "let a: i32 = 1; let b: f32 = 2; let c := a + b;", "mismatched types 'i32' and 'f32' for operator '+'"},
+		{"mixed widths", "let a: i32 = 1; let b: i64 = 2; let c := a * b;", "mismatched types 'i32' and 'i64' for operator '*'"},
+		{"mixed signedness", "let a: u32 = 1; let b: i32 = 2; let c := a - b;", "mismatched types 'u32' and 'i32' for operator '-'"},
+		{"float modulo", "let a: f32 = 1; let c := a % 2;", "operator '%' is only defined between integers, got 'f32' and 'f32'"},
+		{"non numeric", `let a := true; let c := a * 2;`, "operator '*' is not defined between 'bool' and 'i32'"},
+		{"concat number", `let c := "a" + 1;`, "cannot concatenate 'str' and 'i32'"},
+		{"negate unsigned", "let a: u8 = 1; let c := -a;", "cannot negate unsigned type 'u8'"},
+		{"compare mixed", "let a: i32 = 1; let b: f32 = 2; let c := a < b;", "invalid compare operation between 'i32' and 'f32'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if tt.message == "" {
+				expectNoProblems(t, diagnostics)
+				return
+			}
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
+
+func TestLogicalExpr(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		let a: i32 = 1;
+		let b := a > 0 && a < 10 || !(a == 5);
+		let c: bool = b && true;
+	`))
+
+	diagnostics := checkSource(t, "let a: i32 = 1;\nlet b := a && true;")
+	if len(diagnostics) != 1 || diagnostics[0].Message != "operator '&&' expects 'bool' operands, got 'i32'" || diagnostics[0].ColStart != 10 {
+		t.Errorf("expected an operand error on 'a', got %v", diagnostics)
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"and or xor", "let a: u8 = 0xF0; let b: u8 = 0x0F; let c: u8 = a & b | a ~ b;", ""},
+		{"not", "let a: u32 = 1; let c: u32 = ~a;", ""},
+		{"shift", "let a: i64 = 1; let n: u8 = 3; let c: i64 = a << n >> 1;", ""},
+		{"constants", "let c: u8 = 1 << 7 | 0b1;", ""},
+		{"compound", "let a: u16 = 1; let n: u32 = 2; a |= 4; a &= 0xFF; a ~= a; a <<= n; a >>= 1;", ""},
+		{"float", "let a: f32 = 1; let c := a & 1;", "operator '&' is only defined between integers, got 'f32' and 'f32'"},
+		{"mismatched", "let a: u8 = 1; let b: u16 = 2; let c := a | b;", "mismatched types 'u8' and 'u16' for operator '|'"},
+		{"signed count", "let a: u8 = 1; let n: i32 = 2; let c := a << n;", "shift count must be an unsigned integer, got 'i32'"},
+		{"negative count", "let a: u8 = 1; let c := a >> -1;", "negative shift count -1"},
+		{"shift float", "let a: f64 = 1; let c := a << 1;", "operator '<<' is only defined on integers, got 'f64'"},
+		{"not float", "let a: f32 = 1; let c := ~a;", "operator '~' is only defined on integers, got 'f32'"},
+		{"constant overflow", "let c: u8 = 1 << 8;", "error declaring variable 'c'. constant 256 overflows 'u8'"},
+		{"compound float", "let a: f32 = 1; a %= 2;", "operator '%' is only defined between integers, got 'f32' and 'f32'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if tt.message == "" {
+				expectNoProblems(t, diagnostics)
+				return
+			}
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
@@ -1,47 +1,370 @@
 package typechecker
 
 import (
+	"fmt"
 	"walrus/errgen"
 	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
 )
 
 func checkForStmt(forStmt ast.ForStmt, env *TypeEnvironment) ExprType {
 
 	// for loop can be infinite loop or have a start, end and step
 
-	forLoopEnv := NewTypeENV(env, LOOP_SCOPE, "for loop", env.filePath)
+	forLoopEnv := newLoopEnv(forStmt.Label, forStmt.Start, "for loop", env)
+	forLoopEnv.assigned = assignedVariables(forStmt.Condition, forStmt.Increment, forStmt.Block)
 
-	if forStmt.Init != nil || forStmt.Condition != nil || forStmt.Increment != nil {
+	// the init variable is declared in the loop scope, so it is not visible after the loop
+	switch t := forStmt.Init.(type) {
+	case nil:
+	case ast.VarDeclStmt:
+		checkVariableDeclaration(t, forLoopEnv)
+	case ast.VarAssignmentExpr:
+		checkVariableAssignment(t, forLoopEnv)
+	default:
+		errgen.Add(env.filePath, t.StartPos().Line, t.EndPos().Line, t.StartPos().Column, t.EndPos().Column, "for loop initialization must be a variable declaration or assignment").Level(errgen.NORMAL_ERROR)
+	}
 
-		//must be a variable declaration, or an assignment
-		switch t := forStmt.Init.(type) {
-		case ast.VarDeclStmt:
-			checkVariableDeclaration(t, forLoopEnv)
+	if forStmt.Condition != nil {
+		cond := parseNodeValue(forStmt.Condition, forLoopEnv)
+		if _, ok := unwrapType(cond).(Bool); !ok && !isUnknown(cond) {
+			errMsg := fmt.Sprintf("for loop condition must be a boolean expression, got '%s'", tcValueToString(cond))
+			errgen.Add(env.filePath, forStmt.Condition.StartPos().Line, forStmt.Condition.EndPos().Line, forStmt.Condition.StartPos().Column, forStmt.Condition.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+		}
+	}
+
+	switch t := forStmt.Increment.(type) {
+	case nil:
+	case ast.VarAssignmentExpr, ast.IncrementalInterface:
+		parseNodeValue(t, forLoopEnv)
+	default:
+		errgen.Add(env.filePath, t.StartPos().Line, t.EndPos().Line, t.StartPos().Column, t.EndPos().Column, "for loop increment must be an assignment or an increment").Level(errgen.NORMAL_ERROR)
+	}
+
+	// each iteration starts where the condition is true, and the loop ends where it is false unless the body breaks out of it
+	var whenTrue, whenFalse map[string]ExprType
+	if forStmt.Condition != nil {
+		whenTrue, whenFalse = conditionNarrowing(forStmt.Condition, forLoopEnv)
+	}
+	for name, valueType := range whenTrue {
+		forLoopEnv.narrow(name, valueType)
+	}
+
+	for _, stmt := range forStmt.Block.Contents {
+		CheckAST(stmt, forLoopEnv)
+	}
+
+	if !containsBreak(forStmt.Block.Contents) {
+		for name, valueType := range whenFalse {
+			if !forLoopEnv.isDeclared(name) {
+				env.narrow(name, valueType)
+			}
+		}
+	}
+
+	return NewVoid()
+}
+
+// containsBreak reports whether any of the statements, or the statements nested in them, is a break.
+// A break in a nested loop may have the label of an outer one, so those count too.
+func containsBreak(stmts []ast.Node) bool {
+	for _, stmt := range stmts {
+		switch t := stmt.(type) {
+		case ast.BreakStmt:
+			return true
+		case ast.BlockStmt:
+			if containsBreak(t.Contents) {
+				return true
+			}
+		case ast.IfStmt:
+			if containsBreak(t.Block.Contents) {
+				return true
+			}
+			if alternate, ok := t.AlternateBlock.(ast.Node); ok && containsBreak([]ast.Node{alternate}) {
+				return true
+			}
+		case ast.ForStmt:
+			if containsBreak(t.Block.Contents) {
+				return true
+			}
+		case ast.ForEachStmt:
+			if containsBreak(t.Block.Contents) {
+				return true
+			}
+		case ast.SafeStmt:
+			if containsBreak(t.SafeBlock.Contents) || (t.UnsafeBlock != nil && containsBreak(t.UnsafeBlock.Contents)) {
+				return true
+			}
+		case ast.SwitchStmt:
+			for _, switchCase := range t.Cases {
+				if containsBreak(switchCase.Block.Contents) {
+					return true
+				}
+			}
+			if t.Default != nil && containsBreak(t.Default.Contents) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// assignedVariables returns the names of the variables the nodes assign, including in the loops and functions nested in them
+func assignedVariables(nodes ...ast.Node) map[string]bool {
+	c := newAssignmentCollector()
+	c.collect(nodes...)
+	return c.assigned
+}
+
+// functionAssignedVariables returns the names of the variables assigned in the bodies of the functions nested in the nodes.
+// A call to such a function may make the variable null wherever it is called, so it is never narrowed.
+func functionAssignedVariables(nodes ...ast.Node) map[string]bool {
+	c := newAssignmentCollector()
+	c.collect(nodes...)
+	return c.inFunctions
+}
+
+// declareFunctionAssigned records the variables the functions in the top-level nodes of a package assign
+func (t *TypeEnvironment) declareFunctionAssigned(nodes ...ast.Node) {
+	if t.fnAssigned == nil {
+		t.fnAssigned = make(map[string]bool)
+	}
+	for name := range functionAssignedVariables(nodes...) {
+		t.fnAssigned[name] = true
+	}
+}
+
+// assignmentCollector walks nodes for the names of the variables they assign
+type assignmentCollector struct {
+	assigned    map[string]bool // variables assigned anywhere in the nodes
+	inFunctions map[string]bool // variables assigned in the body of a function nested in the nodes
+	inFunction  bool            // whether the walk is in the body of such a function
+}
+
+func newAssignmentCollector() *assignmentCollector {
+	return &assignmentCollector{
+		assigned:    make(map[string]bool),
+		inFunctions: make(map[string]bool),
+	}
+}
+
+func (c *assignmentCollector) collect(nodes ...ast.Node) {
+	for _, node := range nodes {
+		switch t := node.(type) {
 		case ast.VarAssignmentExpr:
-			checkVariableAssignment(t, forLoopEnv)
-		default:
-			errgen.Add(env.filePath, forStmt.StartPos().Line, forStmt.EndPos().Line, forStmt.StartPos().Column, forStmt.EndPos().Column, "for loop initialization must be a variable declaration or assignment").Level(errgen.CRITICAL_ERROR)
+			if identifier, ok := t.Assignee.(ast.IdentifierExpr); ok {
+				c.assigned[identifier.Name] = true
+				if c.inFunction {
+					c.inFunctions[identifier.Name] = true
+				}
+			}
+			c.collect(t.Assignee, t.Value)
+		case ast.BlockStmt:
+			c.collect(t.Contents...)
+		case ast.IfStmt:
+			c.collect(t.Condition, t.Block)
+			if alternate, ok := t.AlternateBlock.(ast.Node); ok {
+				c.collect(alternate)
+			}
+		case ast.ForStmt:
+			c.collect(t.Init, t.Condition, t.Increment, t.Block)
+		case ast.ForEachStmt:
+			c.collect(t.Iterable, t.Block)
+		case ast.SafeStmt:
+			for _, binding := range t.Bindings {
+				c.collect(binding.Value)
+			}
+			c.collect(t.SafeBlock)
+			if t.UnsafeBlock != nil {
+				c.collect(*t.UnsafeBlock)
+			}
+		case ast.SwitchStmt:
+			c.collect(t.Discriminant)
+			for _, switchCase := range t.Cases {
+				c.collect(switchCase.Values...)
+				c.collect(switchCase.Block)
+			}
+			if t.Default != nil {
+				c.collect(*t.Default)
+			}
+		case ast.VarDeclStmt:
+			for _, variable := range t.Variables {
+				c.collect(variable.Value)
+			}
+		case ast.ReturnStmt:
+			c.collect(t.Value)
+		case ast.FunctionDeclStmt:
+			c.collect(t.FunctionLiteral)
+		case ast.ImplStmt:
+			for _, method := range t.Methods {
+				c.collect(method.FunctionLiteral)
+			}
+		case ast.FunctionLiteral:
+			inFunction := c.inFunction
+			c.inFunction = true
+			for _, param := range t.Params {
+				c.collect(param.DefaultValue)
+			}
+			c.collect(t.Body)
+			c.inFunction = inFunction
+		case ast.UnaryExpr:
+			c.collect(t.Argument)
+		case ast.TypeCastExpr:
+			c.collect(t.Expression)
+		case ast.BinaryExpr:
+			c.collect(t.Left, t.Right)
+		case ast.LogicalExpr:
+			c.collect(t.Left, t.Right)
+		case ast.NullCoalescingExpr:
+			c.collect(t.Left, t.Right)
+		case ast.RangeExpr:
+			c.collect(t.Start, t.End, t.Step)
+		case ast.InterpolatedStringExpr:
+			c.collect(t.Parts...)
+		case ast.TupleLiteral:
+			c.collect(t.Values...)
+		case ast.ArrayLiteral:
+			c.collect(t.Values...)
+		case ast.MapLiteral:
+			for _, prop := range t.Values {
+				c.collect(prop.Key, prop.Value)
+			}
+		case ast.StructLiteral:
+			for _, prop := range t.Properties {
+				c.collect(prop.Value)
+			}
+		case ast.Indexable:
+			c.collect(t.Container, t.Index)
+		case ast.StructPropertyAccessExpr:
+			c.collect(t.Object)
+		case ast.OptionalPropertyAccessExpr:
+			c.collect(t.Object)
+		case ast.FunctionCallExpr:
+			c.collect(t.Caller)
+			c.collect(t.Arguments...)
+		case ast.MatchExpr:
+			c.collect(t.Subject)
+			for _, matchCase := range t.Cases {
+				c.collect(matchCase.Pattern, matchCase.Guard, matchCase.Value)
+			}
+		case ast.TryExpr:
+			c.collect(t.Argument)
+		case ast.UnwrapExpr:
+			c.collect(t.Argument)
 		}
+	}
+}
 
-		cond := parseNodeValue(forStmt.Condition, forLoopEnv)
+// newLoopEnv creates the scope of a loop body. A label that is already used by an enclosing loop is reported.
+func newLoopEnv(label string, start lexer.Position, scopeName string, env *TypeEnvironment) *TypeEnvironment {
+
+	loopEnv := NewTypeENV(env, LOOP_SCOPE, scopeName, env.filePath)
+	loopEnv.loopLabel = label
 
-		//must be a boolean if !cond -> error, if !cond.Type == bool -> error
-		if cond == nil || cond.DType() != BOOLEAN_TYPE {
-			errgen.Add(env.filePath, forStmt.StartPos().Line, forStmt.EndPos().Line, forStmt.StartPos().Column, forStmt.EndPos().Column, "for loop condition must be a boolean expression").Level(errgen.CRITICAL_ERROR)
+	if label != "" {
+		if _, found := env.resolveLoop(label); found {
+			errgen.Add(env.filePath, start.Line, start.Line, start.Column, start.Column+len(label), fmt.Sprintf("label '%s' is already used by an enclosing loop", label)).Level(errgen.NORMAL_ERROR)
 		}
+	}
+
+	return loopEnv
+}
+
+// checkForEachStmt checks a foreach loop. The key and value are declared in the loop scope with the types
+// the iterable gives them: index i32 and element T for []T, K and V for map[K]V, index i32 and byte u8 for str,
+// index i32 and value T for range{T}, and index i32 and element T for a struct that implements Iterator with next() -> maybe{T}.
+func checkForEachStmt(node ast.ForEachStmt, env *TypeEnvironment) ExprType {
+
+	loopEnv := newLoopEnv(node.Label, node.Start, "foreach loop", env)
+	loopEnv.assigned = assignedVariables(node.Block)
 
-		incr := parseNodeValue(forStmt.Increment, forLoopEnv)
+	iterable := parseNodeValue(node.Iterable, env)
 
-		//must be assignment
-		if _, ok := incr.(ast.IncrementalInterface); !ok {
-			errgen.Add(env.filePath, forStmt.StartPos().Line, forStmt.EndPos().Line, forStmt.StartPos().Column, forStmt.EndPos().Column, "for loop increment must be incremental assignment").Level(errgen.CRITICAL_ERROR)
+	// when the value cannot be iterated, the loop variables are unknown, so the body is still checked
+	// without reporting the problem again at every use of them
+	var keyType, valueType ExprType = NewUnknown(), NewUnknown()
+
+	switch t := unwrapType(iterable).(type) {
+	case Unknown:
+	case Array:
+		keyType, valueType = NewInt(32, true), t.ArrayType
+	case Map:
+		keyType, valueType = t.KeyType, t.ValueType
+	case Str:
+		keyType, valueType = NewInt(32, true), NewInt(8, false)
+	case Range:
+		keyType, valueType = NewInt(32, true), defaultTypeAt(node.Iterable, t, env).(Range).ElementType
+	case Struct, Enum:
+		elementType, errs := iteratorElement(t)
+		if len(errs) > 0 {
+			errMsg := fmt.Sprintf("cannot iterate over value of type '%s'\n", tcValueToString(iterable)) + errgen.TreeFormatError(errs...).Error()
+			errgen.Add(env.filePath, node.Iterable.StartPos().Line, node.Iterable.EndPos().Line, node.Iterable.StartPos().Column, node.Iterable.EndPos().Column, errMsg).Hint(fmt.Sprintf("a struct or enum is iterable when it implements %s, a method fn %s() -> maybe{T}", ITERATOR_INTERFACE, ITERATOR_METHOD)).Level(errgen.NORMAL_ERROR)
+		} else {
+			keyType, valueType = NewInt(32, true), elementType
 		}
+	default:
+		errgen.Add(env.filePath, node.Iterable.StartPos().Line, node.Iterable.EndPos().Line, node.Iterable.StartPos().Column, node.Iterable.EndPos().Column, fmt.Sprintf("cannot iterate over value of type '%s'", tcValueToString(iterable))).Hint("foreach can iterate over arrays, maps, strings and ranges").Level(errgen.NORMAL_ERROR)
 	}
 
-	//infinte loop
-	for _, stmt := range forStmt.Block.Contents {
-		CheckAST(stmt, forLoopEnv)
+	if node.Key != nil {
+		declareLoopVariable(*node.Key, keyType, loopEnv)
+	}
+	declareLoopVariable(node.Value, valueType, loopEnv)
+
+	for _, stmt := range node.Block.Contents {
+		CheckAST(stmt, loopEnv)
 	}
 
 	return NewVoid()
 }
+
+// iteratorElement returns the element type of a struct or enum used as a foreach iterable. T is read from the return
+// type of its next method, and the type is then checked against Iterator of T like any other interface.
+func iteratorElement(iterable ExprType) (ExprType, []error) {
+	var elementType ExprType
+	scope, _, _ := methodScope(iterable)
+	if method, ok := scope.variables[ITERATOR_METHOD].(StructMethod); ok {
+		if maybe, ok := unwrapType(method.Returns).(Maybe); ok {
+			elementType = maybe.MaybeType
+		}
+	}
+	return elementType, checkMethodsImplementations(iterator(elementType), iterable)
+}
+
+func declareLoopVariable(variable ast.IdentifierExpr, varType ExprType, env *TypeEnvironment) {
+	if err := env.declareVar(variable.Name, varType, false, false); err != nil {
+		errgen.Add(env.filePath, variable.Start.Line, variable.End.Line, variable.Start.Column, variable.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	}
+}
+
+// checkLoopJump checks that a break or continue statement is inside a loop of the same function,
+// and that its label, if any, names one of the enclosing loops.
+func checkLoopJump(keyword string, label *ast.IdentifierExpr, node ast.Node, env *TypeEnvironment) ExprType {
+
+	labelName := ""
+	if label != nil {
+		labelName = label.Name
+	}
+
+	if _, found := env.resolveLoop(labelName); found {
+		return NewVoid()
+	}
+
+	errMsg := fmt.Sprintf("'%s' is not inside a loop", keyword)
+	if label != nil {
+		errMsg = fmt.Sprintf("label '%s' does not name an enclosing loop", labelName)
+	}
+
+	problem := errgen.Add(env.filePath, node.StartPos().Line, node.EndPos().Line, node.StartPos().Column, node.EndPos().Column, errMsg)
+
+	// the loop may be outside the function the statement is in
+	if fnEnv, err := env.resolveFunctionEnv(); err == nil && fnEnv.parent != nil {
+		if _, found := fnEnv.parent.resolveLoop(labelName); found {
+			problem.Hint(fmt.Sprintf("a function cannot %s a loop outside of it", keyword))
+		}
+	}
+
+	problem.Level(errgen.NORMAL_ERROR)
+
+	return NewVoid()
+}
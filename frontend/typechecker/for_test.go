@@ -0,0 +1,283 @@
+package typechecker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoopJumps(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		outer: for {
+			for {
+				if true {
+					break outer;
+				}
+				continue outer;
+			}
+			let a: i32 = 1;
+			switch a {
+				case 1: {
+					continue;
+				}
+			}
+			break;
+		}
+	`))
+}
+
+func TestLoopJumpProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+		hint    string
+	}{
+		{"outside loop", "break;", "'break' is not inside a loop", ""},
+		{"unknown label", "outer: for { continue inner; }", "label 'inner' does not name an enclosing loop", ""},
+		{"closure", "for { let f := fn() { break; }; }", "'break' is not inside a loop", "a function cannot break a loop outside of it"},
+		{"closure label", "outer: for { fn jump() { for { continue outer; } } }", "label 'outer' does not name an enclosing loop", "a function cannot continue a loop outside of it"},
+		{"reused label", "outer: for { outer: for {} }", "label 'outer' is already used by an enclosing loop", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Fatalf("expected %q, got %v", tt.message, diagnostics)
+			}
+			hint := ""
+			if len(diagnostics[0].Hints) > 0 {
+				hint = diagnostics[0].Hints[0]
+			}
+			if hint != tt.hint {
+				t.Errorf("expected hint %q, got %q", tt.hint, hint)
+			}
+		})
+	}
+}
+
+func TestForLoops(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		for i := 0; i < 10; i++ {
+			let doubled := i * 2;
+		}
+		for let i: u8 = 0; i < 10; i += 2 {}
+		let j := 0;
+		for j = 1; j < 10; j = j * 2 {}
+		for j < 20 {
+			j++;
+		}
+		for {
+			break;
+		}
+	`))
+}
+
+func TestForLoopProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"condition", "for i := 0; i + 1; i++ {}", "for loop condition must be a boolean expression, got 'i32'"},
+		{"condition only", "let n := 1; for n {}", "for loop condition must be a boolean expression, got 'i32'"},
+		{"increment", "for i := 0; i < 10; i + 1 {}", "for loop increment must be an assignment or an increment"},
+		{"loop scope", "for k := 0; k < 10; k++ {}\nk = 1;", "cannot assign to 'k' was not declared in this scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
+
+func TestForEach(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		let names := ["a", "b"];
+		foreach i, name in names {
+			let index: i32 = i;
+			let n: str = name;
+		}
+		foreach name in names {
+			let n: str = name;
+		}
+
+		let prices := $map[str]f64 {
+			"tea" => 1.5,
+		};
+		foreach item, price in prices {
+			let k: str = item;
+			let p: f64 = price;
+		}
+
+		foreach i, b in "walrus" {
+			let index: i32 = i;
+			let c: u8 = b;
+		}
+
+		outer: foreach row in [[1, 2], [3]] {
+			foreach cell in row {
+				let c: i32 = cell;
+				continue outer;
+			}
+		}
+	`))
+}
+
+func TestForEachProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"not iterable", "let n := 10; foreach v in n {}", "cannot iterate over value of type 'i32'"},
+		{"element type", "foreach v in [1, 2] { let s: str = v; }", "error declaring variable 's'. cannot assign value of type 'i32' to type 'str'"},
+		{"same name", "foreach v, v in [1] {}", "variable 'v' is already declared in this scope"},
+		{"loop scope", "foreach w in [1] {}\nw = 2;", "cannot assign to 'w' was not declared in this scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
+
+func TestForEachBodyOfNonIterable(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"value", "let n := 10; foreach x in n { let y: str = 5; }"},
+		{"struct without next", "type IterBody struct { a: i32 }; let v := @IterBody{ a: 1 }; foreach x in v { let y: str = 5; }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 2 || !strings.HasPrefix(diagnostics[0].Message, "cannot iterate over value of type") || diagnostics[1].Message != "error declaring variable 'y'. cannot assign value of type 'untyped int' to type 'str'" {
+				t.Errorf("expected the iteration error and the error in the body, got %v", diagnostics)
+			}
+		})
+	}
+}
+
+func TestForEachVariablesOfNonIterable(t *testing.T) {
+	diagnostics := checkSource(t, `
+		let n := 10;
+		foreach i, x in n {
+			let y: str = x;
+			let z: i32 = x + i;
+			let s := "{x}";
+			let a := x.a;
+			x.run(1);
+			let e := x[0] as u8;
+			if x > 1 && -x < 0 {}
+		}
+	`)
+	if len(diagnostics) != 1 || diagnostics[0].Message != "cannot iterate over value of type 'i32'" {
+		t.Errorf("expected only the iteration error, got %v", diagnostics)
+	}
+}
+
+func TestForEachIterator(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type IterCounter struct {
+			n: i32,
+		};
+		impl IterCounter {
+			fn next() -> maybe{i32} {
+				if this.n > 10 {
+					ret null;
+				}
+				ret this.n;
+			}
+		}
+		let c := @IterCounter{ n: 0 };
+		foreach i, v in c {
+			let index: i32 = i;
+			let value: i32 = v;
+		}
+	`))
+}
+
+func TestForEachIteratorProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		reason string
+	}{
+		{"missing next", "type IterNone struct { a: i32 }; let v := @IterNone{ a: 1 }; foreach x in v {}", "missing method 'next' on 'IterNone'"},
+		{"not maybe", "type IterPlain struct { a: i32 }; impl IterPlain { fn next() -> i32 { ret 1; } } let v := @IterPlain{ a: 1 }; foreach x in v {}", "method 'next' found, but return type mismatched"},
+		{"parameters", "type IterArgs struct { a: i32 }; impl IterArgs { fn next(a: i32) -> maybe{i32} { ret a; } } let v := @IterArgs{ a: 1 }; foreach x in v {}", "method 'next' expects 0 parameters, but 1 found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Message, "cannot iterate over value of type") || !strings.Contains(diagnostics[0].Message, tt.reason) {
+				t.Errorf("expected an iteration error because of %q, got %v", tt.reason, diagnostics)
+			}
+		})
+	}
+}
+
+func TestRanges(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		foreach i in 0..10 {
+			let v: i32 = i;
+		}
+		let n: u8 = 100;
+		foreach i, v in 0..=n step 5 {
+			let index: i32 = i;
+			let value: u8 = v;
+		}
+		let s: i64 = -1;
+		foreach v in 10..0 step s {}
+
+		fn total(r: range{i64}) -> i64 {
+			let sum: i64 = 0;
+			foreach v in r {
+				sum += v;
+			}
+			ret sum;
+		}
+		let r: range{i64} = 1..1000;
+		let sum := total(r);
+		total(0..10);
+	`))
+}
+
+func TestRangeProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"mixed widths", "let a: i32 = 0; let b: i64 = 10; let r := a..b;", "mismatched range bounds 'i32' and 'i64'"},
+		{"float bounds", "let r := 0.5..10;", "range bounds must be integers, got 'f32' and 'f32'"},
+		{"float step", "let r := 0..10 step 0.5;", "range step must be an integer, got 'untyped float'"},
+		{"typed float step", "let a: i32 = 0; let r := a..10 step 0.5;", "constant 0.5 truncated to integer type 'i32'"},
+		{"step width", "let a: i32 = 0; let s: u8 = 1; let r := a..10 step s;", "mismatched types 'i32' and 'u8' for range step"},
+		{"constant context", "let r: range{u8} = 0..256;", "error declaring variable 'r'. constant 256 overflows 'u8'"},
+		{"zero step", "let r := 0..10 step 0;", "range step cannot be zero"},
+		{"element type", "fn f(r: range{str}) {}", "range element type must be an integer, got 'str'"},
+		{"bound overflow", "let n: u8 = 1; let r := n..300;", "constant 300 overflows 'u8'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
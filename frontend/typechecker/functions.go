@@ -12,8 +12,18 @@ func checkFunctionExpr(funcNode ast.FunctionLiteral, env *TypeEnvironment) ExprT
 }
 
 func CheckAndDeclareFunction(funcNode ast.FunctionLiteral, name string, env *TypeEnvironment) Fn {
+	fn, fnEnv := declareFunction(funcNode, name, env)
+	checkFunctionBody(funcNode, fn, fnEnv)
+	return fn
+}
+
+// declareFunction declares the signature of a function in env, and returns it with the scope of its body
+func declareFunction(funcNode ast.FunctionLiteral, name string, env *TypeEnvironment) (Fn, *TypeEnvironment) {
 
 	fnEnv := NewTypeENV(env, FUNCTION_SCOPE, name, env.filePath)
+	fnEnv.fnAssigned = functionAssignedVariables(funcNode.Body)
+
+	typeParams := declareTypeParams(funcNode.TypeParams, fnEnv)
 
 	parameters := checkandDeclareParamaters(funcNode.Params, fnEnv)
 	//check return type
@@ -21,6 +31,7 @@ func CheckAndDeclareFunction(funcNode ast.FunctionLiteral, name string, env *Typ
 
 	fn := Fn{
 		DataType:      FUNCTION_TYPE,
+		TypeParams:    typeParams,
 		Params:        parameters,
 		Returns:       returnType,
 		FunctionScope: *fnEnv,
@@ -31,34 +42,42 @@ func CheckAndDeclareFunction(funcNode ast.FunctionLiteral, name string, env *Typ
 	if err != nil {
 		errgen.Add(env.filePath, funcNode.Start.Line, funcNode.End.Line, funcNode.Start.Column, funcNode.End.Column, "error declaring function. "+err.Error()).Level(errgen.CRITICAL_ERROR)
 	}
+
+	return fn, fnEnv
+}
+
+// checkFunctionBody checks the default values of a declared function's optional parameters and its body.
+// The defaults are values like any other, so they are checked with the body rather than with the signature.
+func checkFunctionBody(funcNode ast.FunctionLiteral, fn Fn, fnEnv *TypeEnvironment) {
+
+	for i, param := range funcNode.Params {
+		if param.IsOptional {
+			checkOptionalParameter(param, i, funcNode.Params, fnEnv, fn.Params[i].Type)
+		}
+	}
+
 	//check the function body
 	for _, stmt := range funcNode.Body.Contents {
 		CheckAST(stmt, fnEnv)
 	}
-
-	return fn
 }
 
 func checkandDeclareParamaters(params []ast.FunctionParam, fnEnv *TypeEnvironment) []FnParam {
 	var parameters []FnParam
 
-	for i, param := range params {
-		checkAndDeclareSingleParameter(param, i, params, fnEnv, &parameters)
+	for _, param := range params {
+		checkAndDeclareSingleParameter(param, fnEnv, &parameters)
 	}
 	return parameters
 }
 
-func checkAndDeclareSingleParameter(param ast.FunctionParam, i int, params []ast.FunctionParam, fnEnv *TypeEnvironment, parameters *[]FnParam) {
+func checkAndDeclareSingleParameter(param ast.FunctionParam, fnEnv *TypeEnvironment, parameters *[]FnParam) {
 	if fnEnv.isDeclared(param.Identifier.Name) {
 		errgen.Add(fnEnv.filePath, param.Identifier.Start.Line, param.Identifier.End.Line, param.Identifier.Start.Column, param.Identifier.End.Column, fmt.Sprintf("parameter '%s' is already defined", param.Identifier.Name)).Level(errgen.NORMAL_ERROR)
 	}
 
 	paramType := evaluateTypeName(param.Type, fnEnv)
 
-	if param.IsOptional {
-		checkOptionalParameter(param, i, params, fnEnv, paramType)
-	}
-
 	err := fnEnv.declareVar(param.Identifier.Name, paramType, false, param.IsOptional)
 	if err != nil {
 		errgen.Add(fnEnv.filePath, param.Identifier.Start.Line, param.Identifier.End.Line, param.Identifier.Start.Column, param.Identifier.End.Column, fmt.Sprintf("error defining parameter. %s", err.Error())).Level(errgen.CRITICAL_ERROR)
@@ -78,7 +97,7 @@ func checkOptionalParameter(param ast.FunctionParam, i int, params []ast.Functio
 		}
 	}
 
-	defaultValue := parseNodeValue(param.DefaultValue, fnEnv)
+	defaultValue := checkValueFor(param.DefaultValue, paramType, fnEnv)
 
 	err := matchTypes(paramType, defaultValue)
 	if err != nil {
@@ -89,6 +108,22 @@ func checkOptionalParameter(param ast.FunctionParam, i int, params []ast.Functio
 func checkFunctionCall(callNode ast.FunctionCallExpr, env *TypeEnvironment) ExprType {
 	//check if the function is declared
 	caller := parseNodeValue(callNode.Caller, env)
+
+	// user?.getName() calls the method only when user is not null, so the call gives a maybe
+	isOptionalCall := false
+	if maybe, ok := caller.(Maybe); ok {
+		if _, ok := callNode.Caller.(ast.OptionalPropertyAccessExpr); ok {
+			caller, isOptionalCall = maybe.MaybeType, true
+		}
+	}
+
+	if isUnknown(caller) {
+		for _, argument := range callNode.Arguments {
+			parseNodeValue(argument, env)
+		}
+		return caller
+	}
+
 	fn, err := userDefinedToFn(caller)
 
 	if err != nil {
@@ -112,18 +147,63 @@ func checkFunctionCall(callNode ast.FunctionCallExpr, env *TypeEnvironment) Expr
 		}
 	}
 
+	args := make([]ExprType, len(callNode.Arguments))
+	for i, argument := range callNode.Arguments {
+		var paramType ExprType
+		if i < len(fnParams) {
+			paramType = fnParams[i].Type
+		}
+		args[i] = checkValueFor(argument, paramType, env)
+	}
+
+	if len(fn.TypeParams) > 0 {
+		fn = instantiateCall(fn, args, callNode, env)
+		fnParams = fn.Params
+	}
+
 	//check if the arguments match the parameters
-	for i := 0; i < len(callNode.Arguments); i++ {
-		arg := parseNodeValue(callNode.Arguments[i], env)
+	for i := 0; i < len(args) && i < len(fnParams); i++ {
+		arg := args[i]
 		err := matchTypes(fnParams[i].Type, arg)
 		if err != nil {
 			errgen.Add(env.filePath, callNode.Arguments[i].StartPos().Line, callNode.Arguments[i].EndPos().Line, callNode.Arguments[i].StartPos().Column, callNode.Arguments[i].EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
 		}
 	}
 
+	if isOptionalCall {
+		return optional(fn.Returns)
+	}
+
 	return fn.Returns
 }
 
+// instantiateCall infers the type arguments of a call to a generic function from its arguments,
+// and returns the function with them substituted for its type parameters.
+func instantiateCall(fn Fn, args []ExprType, callNode ast.FunctionCallExpr, env *TypeEnvironment) Fn {
+
+	expected := make([]ExprType, len(fn.Params))
+	for i, param := range fn.Params {
+		expected[i] = param.Type
+	}
+
+	typeArgs := inferTypeArgs(fn.TypeParams, expected, args)
+
+	for i, arg := range typeArgs {
+		if arg == nil {
+			errgen.Add(env.filePath, callNode.Start.Line, callNode.End.Line, callNode.Start.Column, callNode.End.Column, fmt.Sprintf("cannot infer type parameter '%s'", fn.TypeParams[i].Name)).Hint("type parameters are inferred from the arguments, so each one must be used by a parameter").Level(errgen.NORMAL_ERROR)
+		}
+	}
+
+	for _, err := range checkConstraints(fn.TypeParams, typeArgs) {
+		errgen.Add(env.filePath, callNode.Start.Line, callNode.End.Line, callNode.Start.Column, callNode.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	}
+
+	bindings := typeBindings(fn.TypeParams, typeArgs)
+	fn.TypeParams = nil
+
+	return substitute(fn, bindings).(Fn)
+}
+
 func userDefinedToFn(ud ExprType) (Fn, error) {
 	// if UserDefined then chain until Fn or error
 	switch t := ud.(type) {
@@ -139,6 +219,13 @@ func userDefinedToFn(ud ExprType) (Fn, error) {
 }
 
 func checkFunctionDeclStmt(funcNode ast.FunctionDeclStmt, env *TypeEnvironment) ExprType {
+	fn, fnEnv := declareFunctionDecl(funcNode, env)
+	checkFunctionBody(funcNode.FunctionLiteral, fn, fnEnv)
+	return fn
+}
+
+// declareFunctionDecl declares the signature of a named function, and returns it with the scope of its body
+func declareFunctionDecl(funcNode ast.FunctionDeclStmt, env *TypeEnvironment) (Fn, *TypeEnvironment) {
 
 	// check if function is already declared
 	funcName := funcNode.Identifier.Name
@@ -147,7 +234,13 @@ func checkFunctionDeclStmt(funcNode ast.FunctionDeclStmt, env *TypeEnvironment)
 		errgen.Add(env.filePath, funcNode.Identifier.Start.Line, funcNode.Identifier.End.Line, funcNode.Identifier.Start.Column, funcNode.Identifier.End.Column, fmt.Sprintf("function '%s' is already defined in this scope", funcName)).Level(errgen.NORMAL_ERROR)
 	}
 
-	return CheckAndDeclareFunction(funcNode.FunctionLiteral, funcName, env)
+	fn, fnEnv := declareFunction(funcNode.FunctionLiteral, funcName, env)
+
+	if funcNode.IsPublic {
+		declarePublic(funcName, false, funcNode.Identifier, env)
+	}
+
+	return fn, fnEnv
 }
 
 func getFunctionReturnValue(env *TypeEnvironment, returnNode ast.Node) ExprType {
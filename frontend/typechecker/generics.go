@@ -0,0 +1,387 @@
+package typechecker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+)
+
+// declareTypeParams declares the type parameters of a generic function or type in its scope, so that
+// evaluateTypeName resolves their names there. A constraint must be an interface.
+func declareTypeParams(params []ast.TypeParam, env *TypeEnvironment) []TypeParam {
+
+	typeParams := make([]TypeParam, 0, len(params))
+
+	for _, param := range params {
+		name := param.Identifier
+
+		if _, ok := env.typeParams[name.Name]; ok {
+			errgen.Add(env.filePath, name.Start.Line, name.End.Line, name.Start.Column, name.End.Column, fmt.Sprintf("type parameter '%s' is already declared", name.Name)).Level(errgen.NORMAL_ERROR)
+			continue
+		}
+
+		var constraint ExprType
+		if param.Constraint != nil {
+			constraint = evaluateTypeName(param.Constraint, env)
+			if _, ok := unwrapType(constraint).(Interface); !ok {
+				errgen.Add(env.filePath, param.Constraint.StartPos().Line, param.Constraint.EndPos().Line, param.Constraint.StartPos().Column, param.Constraint.EndPos().Column, fmt.Sprintf("constraint of '%s' must be an interface, got '%s'", name.Name, tcValueToString(constraint))).Level(errgen.NORMAL_ERROR)
+				constraint = nil
+			} else {
+				constraint = unwrapType(constraint)
+			}
+		}
+
+		typeParam := NewTypeParam(name.Name, constraint)
+		env.typeParams[name.Name] = typeParam
+		typeParams = append(typeParams, typeParam)
+	}
+
+	return typeParams
+}
+
+// genericParams returns the type parameters of a generic struct or interface that has not been instantiated yet
+func genericParams(value ExprType) []TypeParam {
+	switch t := value.(type) {
+	case Struct:
+		if t.TypeArgs == nil {
+			return t.TypeParams
+		}
+	case Interface:
+		if t.TypeArgs == nil {
+			return t.TypeParams
+		}
+	}
+	return nil
+}
+
+// instantiateType binds the type arguments written after the name of a generic type, like Pair<i32, str>.
+// The number of arguments must match the type parameters, and each argument must satisfy its constraint.
+func instantiateType(generic ExprType, args []ExprType, node ast.UserDefinedType, env *TypeEnvironment) ExprType {
+
+	params := genericParams(generic)
+
+	if len(params) != len(args) {
+		var errMsg string
+		if len(params) == 0 {
+			errMsg = fmt.Sprintf("type '%s' does not take type arguments", node.AliasName)
+		} else {
+			errMsg = fmt.Sprintf("generic type '%s' expects %d type arguments, got %d", node.AliasName, len(params), len(args))
+		}
+		problem := errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg)
+		if len(args) == 0 {
+			problem.Hint(fmt.Sprintf("write the type arguments after the name, like %s<%s>", node.AliasName, typeParamNames(params)))
+		}
+		problem.Level(errgen.NORMAL_ERROR)
+		return generic
+	}
+
+	for _, err := range checkConstraints(params, args) {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	}
+
+	return instance(generic, args)
+}
+
+// instance returns the instance of a generic struct or interface for the given type arguments
+func instance(generic ExprType, args []ExprType) ExprType {
+	switch t := generic.(type) {
+	case Struct:
+		t.TypeArgs = args
+		return t
+	case Interface:
+		bindings := typeBindings(t.TypeParams, args)
+		methods := make([]InterfaceMethodType, len(t.Methods))
+		for i, method := range t.Methods {
+			methods[i] = InterfaceMethodType{
+				Name:   method.Name,
+				Method: substitute(method.Method, bindings).(Fn),
+			}
+		}
+		t.Methods = methods
+		t.TypeArgs = args
+		return t
+	default:
+		return generic
+	}
+}
+
+// instanceScope returns the members of a struct. For an instance of a generic struct the type arguments are
+// substituted for the type parameters in a copy of the shared scope, so methods implemented later are seen too.
+func instanceScope(structType Struct) TypeEnvironment {
+
+	if len(structType.TypeArgs) == 0 {
+		return structType.StructScope
+	}
+
+	bindings := typeBindings(structType.TypeParams, structType.TypeArgs)
+
+	scope := structType.StructScope
+	scope.variables = make(map[string]ExprType, len(structType.StructScope.variables))
+	for name, member := range structType.StructScope.variables {
+		scope.variables[name] = substitute(member, bindings)
+	}
+
+	return scope
+}
+
+// checkConstraints checks that each type argument satisfies the constraint of its type parameter
+func checkConstraints(params []TypeParam, args []ExprType) []error {
+
+	errs := []error{}
+	bindings := typeBindings(params, args)
+
+	for i, param := range params {
+		if param.Constraint == nil || i >= len(args) || args[i] == nil {
+			continue
+		}
+		constraint := substitute(param.Constraint, bindings)
+		if err := matchTypes(constraint, args[i]); err != nil {
+			errMsg := fmt.Sprintf("type '%s' does not satisfy '%s' for type parameter '%s'\n", tcValueToString(args[i]), tcValueToString(constraint), param.Name)
+			errs = append(errs, errors.New(errMsg+errgen.TreeFormatError(err).Error()))
+		}
+	}
+
+	return errs
+}
+
+// inferTypeArgs infers the type arguments of a generic function or struct literal by unifying the expected
+// types with the provided ones. Typed values are unified first, so an untyped constant only decides a type
+// parameter, with its default type, when no typed value does. Parameters that cannot be inferred are nil.
+func inferTypeArgs(params []TypeParam, expected []ExprType, provided []ExprType) []ExprType {
+
+	bindings := make(map[string]ExprType)
+
+	for _, untyped := range []bool{false, true} {
+		for i := 0; i < len(expected) && i < len(provided); i++ {
+			if provided[i] == nil || hasUntypedConstant(provided[i]) != untyped {
+				continue
+			}
+			unify(expected[i], provided[i], bindings)
+		}
+	}
+
+	args := make([]ExprType, len(params))
+	for i, param := range params {
+		args[i] = bindings[param.Name]
+	}
+
+	return args
+}
+
+func hasUntypedConstant(value ExprType) bool {
+	value = unwrapType(value)
+	switch t := value.(type) {
+	case Range:
+		return t.Bounds != nil
+	case Tuple:
+		for _, element := range t.ElementTypes {
+			if hasUntypedConstant(element) {
+				return true
+			}
+		}
+		return false
+	}
+	return isUntyped(value)
+}
+
+// unify walks the expected type and the provided type side by side, and binds each type parameter found
+// in the expected type to the type at the same place in the provided one. A parameter keeps its first binding.
+func unify(expected ExprType, provided ExprType, bindings map[string]ExprType) {
+
+	provided = unwrapType(provided)
+
+	switch e := unwrapType(expected).(type) {
+	case TypeParam:
+		if _, ok := bindings[e.Name]; !ok {
+			if _, isNull := provided.(Null); !isNull {
+				bindings[e.Name] = defaultType(provided)
+			}
+		}
+	case Array:
+		if p, ok := provided.(Array); ok {
+			unify(e.ArrayType, p.ArrayType, bindings)
+		}
+	case Map:
+		if p, ok := provided.(Map); ok {
+			unify(e.KeyType, p.KeyType, bindings)
+			unify(e.ValueType, p.ValueType, bindings)
+		}
+	case Maybe:
+		if p, ok := provided.(Maybe); ok {
+			unify(e.MaybeType, p.MaybeType, bindings)
+		} else {
+			unify(e.MaybeType, provided, bindings)
+		}
+	case Range:
+		if p, ok := provided.(Range); ok {
+			unify(e.ElementType, p.ElementType, bindings)
+		}
+	case Result:
+		if p, ok := provided.(Result); ok {
+			unify(e.OkType, p.OkType, bindings)
+			unify(e.ErrType, p.ErrType, bindings)
+		}
+	case Tuple:
+		if p, ok := provided.(Tuple); ok {
+			unifyTypeArgs(e.ElementTypes, p.ElementTypes, bindings)
+		}
+	case Fn:
+		if p, ok := provided.(Fn); ok && len(p.Params) == len(e.Params) {
+			for i := range e.Params {
+				unify(e.Params[i].Type, p.Params[i].Type, bindings)
+			}
+			unify(e.Returns, p.Returns, bindings)
+		}
+	case Struct:
+		if p, ok := provided.(Struct); ok && p.StructName == e.StructName {
+			unifyTypeArgs(e.TypeArgs, p.TypeArgs, bindings)
+		}
+	case Interface:
+		if p, ok := provided.(Interface); ok && p.InterfaceName == e.InterfaceName {
+			unifyTypeArgs(e.TypeArgs, p.TypeArgs, bindings)
+		}
+	}
+}
+
+func unifyTypeArgs(expected []ExprType, provided []ExprType, bindings map[string]ExprType) {
+	if len(expected) != len(provided) {
+		return
+	}
+	for i := range expected {
+		unify(expected[i], provided[i], bindings)
+	}
+}
+
+// typeBindings maps each type parameter name to its type argument
+func typeBindings(params []TypeParam, args []ExprType) map[string]ExprType {
+	bindings := make(map[string]ExprType, len(params))
+	for i, param := range params {
+		if i < len(args) && args[i] != nil {
+			bindings[param.Name] = args[i]
+		}
+	}
+	return bindings
+}
+
+// substitute replaces the type parameters in a type with the types they are bound to.
+// Parameters without a binding are left in place.
+func substitute(value ExprType, bindings map[string]ExprType) ExprType {
+
+	if len(bindings) == 0 {
+		return value
+	}
+
+	switch t := value.(type) {
+	case TypeParam:
+		if bound, ok := bindings[t.Name]; ok {
+			return bound
+		}
+		return t
+	case Array:
+		t.ArrayType = substitute(t.ArrayType, bindings)
+		return t
+	case Map:
+		t.KeyType = substitute(t.KeyType, bindings)
+		t.ValueType = substitute(t.ValueType, bindings)
+		return t
+	case Maybe:
+		t.MaybeType = substitute(t.MaybeType, bindings)
+		return t
+	case Range:
+		t.ElementType = substitute(t.ElementType, bindings)
+		return t
+	case Result:
+		t.OkType = substitute(t.OkType, bindings)
+		t.ErrType = substitute(t.ErrType, bindings)
+		return t
+	case Tuple:
+		t.ElementTypes = substituteAll(t.ElementTypes, bindings)
+		return t
+	case Fn:
+		// the function's own type parameters hide the outer ones with the same name
+		inner := bindings
+		if len(t.TypeParams) > 0 {
+			inner = make(map[string]ExprType, len(bindings))
+			for name, bound := range bindings {
+				inner[name] = bound
+			}
+			for _, param := range t.TypeParams {
+				delete(inner, param.Name)
+			}
+		}
+		params := make([]FnParam, len(t.Params))
+		for i, param := range t.Params {
+			param.Type = substitute(param.Type, inner)
+			params[i] = param
+		}
+		t.Params = params
+		t.Returns = substitute(t.Returns, inner)
+		return t
+	case StructMethod:
+		t.Fn = substitute(t.Fn, bindings).(Fn)
+		return t
+	case StructProperty:
+		t.Type = substitute(t.Type, bindings)
+		return t
+	case Struct:
+		t.TypeArgs = substituteAll(t.TypeArgs, bindings)
+		return t
+	case Interface:
+		if len(t.TypeArgs) == 0 {
+			return t
+		}
+		methods := make([]InterfaceMethodType, len(t.Methods))
+		for i, method := range t.Methods {
+			methods[i] = InterfaceMethodType{
+				Name:   method.Name,
+				Method: substitute(method.Method, bindings).(Fn),
+			}
+		}
+		t.Methods = methods
+		t.TypeArgs = substituteAll(t.TypeArgs, bindings)
+		return t
+	case UserDefined:
+		return substitute(unwrapType(t), bindings)
+	default:
+		return value
+	}
+}
+
+func substituteAll(values []ExprType, bindings map[string]ExprType) []ExprType {
+	if values == nil {
+		return nil
+	}
+	substituted := make([]ExprType, len(values))
+	for i, value := range values {
+		substituted[i] = substitute(value, bindings)
+	}
+	return substituted
+}
+
+// isOrdered reports whether values of the type can be compared with < <= > >=
+func isOrdered(value ExprType) bool {
+	if param, ok := unwrapType(value).(TypeParam); ok {
+		constraint, ok := param.Constraint.(Interface)
+		return ok && constraint.InterfaceName == COMPARABLE_INTERFACE
+	}
+	return isNumberType(value)
+}
+
+// typeArgsString formats type arguments, or the elements of a tuple, the way they are written, like "i32, str"
+func typeArgsString(args []ExprType) string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = tcValueToString(arg)
+	}
+	return strings.Join(names, ", ")
+}
+
+func typeParamNames(params []TypeParam) string {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+	}
+	return strings.Join(names, ", ")
+}
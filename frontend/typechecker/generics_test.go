@@ -0,0 +1,95 @@
+package typechecker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenericFunctions(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		fn genFirst<T>(xs: []T) -> maybe{T} {
+			ret xs[0];
+		}
+		fn genMax<T: Comparable>(a: T, b: T) -> T {
+			if a > b {
+				ret a;
+			}
+			ret b;
+		}
+		fn genApply<A, B>(x: A, f: fn(v: A) -> B) -> B {
+			ret f(x);
+		}
+		let a: maybe{i32} = genFirst([1, 2, 3]);
+		let s: maybe{str} = genFirst(["a", "b"]);
+		let m: i64 = genMax(1 as i64, 2);
+		let f: f32 = genMax(1.5, 2);
+		let r: str = genApply(3, fn(v: i32) -> str { ret "three"; });
+	`))
+}
+
+func TestGenericTypes(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type GenPair<K, V> struct {
+			first: K,
+			second: V,
+		};
+		impl GenPair {
+			fn swap() -> GenPair<V, K> {
+				ret @GenPair<V, K>{first: this.second, second: this.first};
+			}
+		}
+		type GenContainer<T> interface {
+			fn get() -> T;
+		};
+		type GenBox<T> struct {
+			value: T,
+		};
+		impl GenBox {
+			fn get() -> T {
+				ret this.value;
+			}
+			fn convert<U>(f: fn(v: T) -> U) -> GenBox<U> {
+				ret @GenBox{value: f(this.value)};
+			}
+		}
+		fn genUnwrap<T>(c: GenContainer<T>) -> T {
+			ret c.get();
+		}
+		let p := @GenPair{first: 1, second: "one"};
+		let q: GenPair<str, i32> = p.swap();
+		let b := @GenBox<GenBox<i64>>{value: @GenBox<i64>{value: 3}};
+		let inner: GenBox<i64> = b.get();
+		let c: GenContainer<i32> = @GenBox{value: 3};
+		let u: i32 = genUnwrap(c);
+		let s: GenBox<str> = inner.convert(fn(v: i64) -> str { ret "v"; });
+	`))
+}
+
+func TestGenericProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"not comparable", "fn genP1<T: Comparable>(a: T) -> T { ret a; } let a := genP1(\"x\");", "type 'str' does not satisfy 'Comparable' for type parameter 'T'"},
+		{"unconstrained compare", "fn genP2<T>(a: T, b: T) -> bool { ret a < b; }", "invalid compare operation between 'T' and 'T'"},
+		{"not inferred", "fn genP3<T>() -> []T { let xs: []T; ret xs; } let a := genP3();", "cannot infer type parameter 'T'"},
+		{"argument mismatch", "fn genP4<T>(a: T, b: T) {} genP4(1 as i64, \"x\");", "cannot assign value of type 'str' to type 'i64'"},
+		{"duplicate parameter", "fn genP5<T, T>(a: T) {}", "type parameter 'T' is already declared"},
+		{"constraint", "fn genP6<T: i32>(a: T) {}", "constraint of 'T' must be an interface, got 'i32'"},
+		{"opaque parameter", "fn genP7<T>(a: T) -> T { let x: T = 1; ret a; }", "error declaring variable 'x'. cannot assign value of type 'untyped int' to type 'T'"},
+		{"enum", "type GenP8<T> enum { One };", "only structs and interfaces can have type parameters"},
+		{"type argument count", "type GenP9<T> struct { v: T }; fn genP9(p: GenP9<i32, str>) {}", "generic type 'GenP9' expects 1 type arguments, got 2"},
+		{"type arguments on plain type", "type GenP10 struct { v: i32 }; fn genP10(p: GenP10<i32>) {}", "type 'GenP10' does not take type arguments"},
+		{"instance mismatch", "type GenP11<T> struct { v: T }; let a: GenP11<str> = @GenP11{v: 1};", "error declaring variable 'a'. cannot assign value of type 'GenP11<i32>' to type 'GenP11<str>'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Message, tt.message) {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
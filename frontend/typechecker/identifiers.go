@@ -1,6 +1,7 @@
 package typechecker
 
 import (
+	"fmt"
 	"walrus/errgen"
 	"walrus/frontend/ast"
 )
@@ -10,7 +11,7 @@ func checkIdentifier(node ast.IdentifierExpr, env *TypeEnvironment) ExprType {
 	name := node.Name
 
 	//identifier cannot be types or builtins
-	if isTypeDefined(name) && (name != "null" && name != "void") {
+	if env.isTypeDefined(name) && (name != "null" && name != "void") {
 		errgen.Add(env.filePath, node.StartPos().Line, node.EndPos().Line, node.StartPos().Column, node.EndPos().Column, "cannot use type as value").Level(errgen.CRITICAL_ERROR)
 	}
 
@@ -23,5 +24,14 @@ func checkIdentifier(node ast.IdentifierExpr, env *TypeEnvironment) ExprType {
 	// if we found value on that scope, return the value. Else make error (though there is no change to reach the error)
 	variable := declaredEnv.variables[name]
 
+	if _, ok := variable.(Package); ok {
+		errgen.Add(env.filePath, node.StartPos().Line, node.EndPos().Line, node.StartPos().Column, node.EndPos().Column, fmt.Sprintf("cannot use package '%s' as a value", name)).Hint(fmt.Sprintf("use one of its members, like %s.name", name)).Level(errgen.CRITICAL_ERROR)
+	}
+
+	// a maybe variable that is known not to be null here has the type of its value
+	if valueType, ok := env.narrowedType(name); ok {
+		return unwrapType(valueType)
+	}
+
 	return unwrapType(variable)
 }
@@ -15,26 +15,29 @@ func checkImplStmt(implStmt ast.ImplStmt, env *TypeEnvironment) ExprType {
 	}
 
 	// check if the type to implement exists
-	structValue, err := getTypeDefinition(implStmt.ImplFor.Name)
+	structValue, err := env.getTypeDefinition(implStmt.ImplFor.Name)
 	if err != nil {
 		errgen.Add(env.filePath, implStmt.Start.Line, implStmt.End.Line, implStmt.Start.Column, implStmt.End.Column, err.Error()).Level(errgen.CRITICAL_ERROR)
 	}
 
-	// type must be a struct
-	implForType, ok := structValue.(Struct)
+	// type must be a struct or an enum
+	typeScope, typeName, ok := methodScope(structValue)
 	if !ok {
-		errgen.Add(env.filePath, implStmt.Start.Line, implStmt.End.Line, implStmt.Start.Column, implStmt.End.Column, "only structs can be implemented").Level(errgen.CRITICAL_ERROR)
+		errgen.Add(env.filePath, implStmt.Start.Line, implStmt.End.Line, implStmt.Start.Column, implStmt.End.Column, "only structs and enums can be implemented").Level(errgen.CRITICAL_ERROR)
 	}
 
 	//add the methods to the struct's environment
 	for _, method := range implStmt.Methods {
 		name := method.Identifier.Name
 		// if the method name is in the struct's elements, throw an error
-		if _, ok := implForType.StructScope.variables[name]; ok {
-			errgen.Add(env.filePath, method.Start.Line, method.End.Line, method.Start.Column, method.End.Column, fmt.Sprintf("'%s' is already defined in struct '%s'", name, implForType.StructName)).Level(errgen.CRITICAL_ERROR)
+		if _, ok := typeScope.variables[name]; ok {
+			errgen.Add(env.filePath, method.Start.Line, method.End.Line, method.Start.Column, method.End.Column, fmt.Sprintf("'%s' is already defined in '%s'", name, typeName)).Level(errgen.CRITICAL_ERROR)
 		}
 
-		fnEnv := NewTypeENV(&implForType.StructScope, FUNCTION_SCOPE, name, implForType.StructScope.filePath)
+		fnEnv := NewTypeENV(&typeScope, FUNCTION_SCOPE, name, env.filePath)
+		fnEnv.fnAssigned = functionAssignedVariables(method.Body)
+
+		typeParams := declareTypeParams(method.TypeParams, fnEnv)
 
 		//check the parameters and declare them
 		params := checkandDeclareParamaters(method.Params, fnEnv)
@@ -44,8 +47,11 @@ func checkImplStmt(implStmt ast.ImplStmt, env *TypeEnvironment) ExprType {
 
 		methodToDeclare := StructMethod{
 			IsPrivate: method.IsPrivate,
+			IsPublic:  method.IsPublic,
+			Declared:  Declaration{FilePath: env.filePath, Position: method.Identifier.Start},
 			Fn: Fn{
 				DataType:      FUNCTION_TYPE,
+				TypeParams:    typeParams,
 				Params:        params,
 				Returns:       returnType,
 				FunctionScope: *fnEnv,
@@ -53,17 +59,26 @@ func checkImplStmt(implStmt ast.ImplStmt, env *TypeEnvironment) ExprType {
 		}
 
 		//declare the method on the struct's environment and then check the body
-		err := implForType.StructScope.declareVar(name, methodToDeclare, false, false)
+		err := typeScope.declareVar(name, methodToDeclare, false, false)
 		if err != nil {
 			errgen.Add(env.filePath, method.Start.Line, method.End.Line, method.Start.Column, method.End.Column, fmt.Sprintf("cannot declare method '%s'\n└── %s", method.Identifier.Name, err.Error())).Level(errgen.CRITICAL_ERROR)
 		}
 
-		//check the function body
-		for _, stmt := range method.Body.Contents {
-			CheckAST(stmt, fnEnv)
-		}
+		checkFunctionBody(method.FunctionLiteral, methodToDeclare.Fn, fnEnv)
 
 	}
 
 	return NewVoid()
 }
+
+// methodScope returns the scope that holds the methods of a struct or an enum, and the name of the type.
+func methodScope(value ExprType) (TypeEnvironment, string, bool) {
+	switch t := value.(type) {
+	case Struct:
+		return instanceScope(t), tcValueToString(t), true
+	case Enum:
+		return t.EnumScope, t.EnumName, true
+	default:
+		return TypeEnvironment{}, "", false
+	}
+}
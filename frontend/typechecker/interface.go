@@ -7,7 +7,7 @@ import (
 	"walrus/utils"
 )
 
-func checkInterfaceTypeDecl(interfaceName string, interfaceNode ast.InterfaceType, env *TypeEnvironment) Interface {
+func checkInterfaceTypeDecl(interfaceName string, interfaceNode ast.InterfaceType, typeParams []TypeParam, env *TypeEnvironment) Interface {
 
 	methods := make([]InterfaceMethodType, 0)
 
@@ -60,5 +60,6 @@ func checkInterfaceTypeDecl(interfaceName string, interfaceNode ast.InterfaceTyp
 		DataType:      INTERFACE_TYPE,
 		InterfaceName: interfaceName,
 		Methods:       methods,
+		TypeParams:    typeParams,
 	}
 }
@@ -0,0 +1,361 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+)
+
+// patternInfo describes what a checked pattern matches
+type patternInfo struct {
+	irrefutable bool   // the pattern matches every value of its type
+	variant     string // the enum variant or bool value that the pattern matches in full
+	constant    string // the literal the pattern matches, so repeated arms can be found
+}
+
+// matchCoverage records what the unguarded arms of a match have matched so far.
+// For a maybe{T} subject, all is about the values of T and null is tracked on its own.
+type matchCoverage struct {
+	all       bool
+	null      bool
+	variants  map[string]bool
+	constants map[string]bool
+}
+
+// checkMatchExpr checks a match expression. Each arm has its own scope, where the names bound by its pattern live.
+// The value of the match has the type the arms agree on; arms that give null make it a maybe.
+// Matches that leave values unhandled are reported with the missing cases, and arms that can never be reached are warned about.
+func checkMatchExpr(node ast.MatchExpr, env *TypeEnvironment) ExprType {
+
+	subject := defaultTypeAt(node.Subject, parseNodeValue(node.Subject, env), env)
+
+	valueType := unwrapType(subject)
+	maybe, isMaybe := valueType.(Maybe)
+	if isMaybe {
+		valueType = unwrapType(maybe.MaybeType)
+	}
+
+	coverage := matchCoverage{
+		variants:  map[string]bool{},
+		constants: map[string]bool{},
+	}
+
+	armTypes := make([]ExprType, 0, len(node.Cases))
+
+	for _, matchCase := range node.Cases {
+		armEnv := NewTypeENV(env, CONDITIONAL_SCOPE, "match case", env.filePath)
+
+		pattern := matchCase.Pattern
+		isNull := isNamedPattern(pattern, "null")
+		isWildcard := isNamedPattern(pattern, "_")
+
+		var info patternInfo
+		switch {
+		case isNull:
+			if !isMaybe {
+				errgen.Add(env.filePath, pattern.StartPos().Line, pattern.EndPos().Line, pattern.StartPos().Column, pattern.EndPos().Column, fmt.Sprintf("cannot match null against '%s'", tcValueToString(subject))).Level(errgen.NORMAL_ERROR)
+			}
+		case isWildcard:
+			info.irrefutable = true
+		default:
+			info = checkPattern(pattern, valueType, armEnv)
+		}
+
+		if coverage.reached(info, isNull, isWildcard, isMaybe) {
+			errgen.Add(env.filePath, matchCase.Start.Line, matchCase.End.Line, matchCase.Start.Column, matchCase.End.Column, "unreachable match arm").Hint("earlier arms already match every value this one can").Level(errgen.WARNING)
+		}
+
+		if matchCase.Guard != nil {
+			guard := parseNodeValue(matchCase.Guard, armEnv)
+			if _, ok := unwrapType(guard).(Bool); !ok && !isUnknown(guard) {
+				errMsg := fmt.Sprintf("match guard must be a boolean expression, got '%s'", tcValueToString(guard))
+				errgen.Add(env.filePath, matchCase.Guard.StartPos().Line, matchCase.Guard.EndPos().Line, matchCase.Guard.StartPos().Column, matchCase.Guard.EndPos().Column, errMsg).Level(errgen.NORMAL_ERROR)
+			}
+		} else {
+			coverage.record(info, isNull, isWildcard, valueType)
+		}
+
+		armTypes = append(armTypes, parseNodeValue(matchCase.Value, armEnv))
+	}
+
+	if missing := coverage.missing(valueType, isMaybe); len(missing) > 0 {
+		errMsg := fmt.Sprintf("non-exhaustive match on '%s'\n", tcValueToString(subject)) + errgen.TreeFormatString(missing...)
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, errMsg).Hint("add the missing cases, or a '_' arm for everything else").Level(errgen.NORMAL_ERROR)
+	}
+
+	return matchResultType(node, armTypes, env)
+}
+
+func isNamedPattern(pattern ast.Node, name string) bool {
+	identifier, ok := pattern.(ast.IdentifierExpr)
+	return ok && identifier.Name == name
+}
+
+// reached reports whether the earlier unguarded arms already match everything the pattern does
+func (c *matchCoverage) reached(info patternInfo, isNull, isWildcard, isMaybe bool) bool {
+	switch {
+	case isNull:
+		return c.null
+	case isWildcard:
+		return c.all && (c.null || !isMaybe)
+	}
+	return c.all || (info.variant != "" && c.variants[info.variant]) || (info.constant != "" && c.constants[info.constant])
+}
+
+func (c *matchCoverage) record(info patternInfo, isNull, isWildcard bool, valueType ExprType) {
+	switch {
+	case isNull:
+		c.null = true
+		return
+	case isWildcard:
+		c.all, c.null = true, true
+		return
+	case info.irrefutable:
+		c.all = true
+		return
+	}
+
+	if info.constant != "" {
+		c.constants[info.constant] = true
+	}
+
+	if info.variant == "" {
+		return
+	}
+	c.variants[info.variant] = true
+
+	// every variant, or both true and false, covers the whole type
+	switch t := valueType.(type) {
+	case Enum:
+		for _, variant := range t.Variants {
+			if !c.variants[variant.Name] {
+				return
+			}
+		}
+		c.all = true
+	case Bool:
+		c.all = c.variants["true"] && c.variants["false"]
+	}
+}
+
+// missing lists the cases the arms leave unhandled, or nothing when the match is exhaustive
+func (c *matchCoverage) missing(valueType ExprType, isMaybe bool) []string {
+	cases := []string{}
+
+	if isMaybe && !c.null {
+		cases = append(cases, "missing case null")
+	}
+
+	if c.all {
+		return cases
+	}
+
+	switch t := valueType.(type) {
+	case Enum:
+		for _, variant := range t.Variants {
+			if !c.variants[variant.Name] {
+				cases = append(cases, fmt.Sprintf("missing case %s.%s", t.EnumName, variant.Name))
+			}
+		}
+	case Bool:
+		for _, value := range []string{"true", "false"} {
+			if !c.variants[value] {
+				cases = append(cases, fmt.Sprintf("missing case %s", value))
+			}
+		}
+	default:
+		cases = append(cases, "missing case _")
+	}
+
+	return cases
+}
+
+// checkPattern checks a pattern against the type of the value it matches, and declares the names it binds in env.
+//
+// A pattern can be:
+//   - _, which matches anything, or a name, which matches anything and binds it
+//   - a literal, true, false or null
+//   - a range of integers, like 1..10
+//   - an enum variant, like Shape.Empty, or one with patterns for its fields, like Shape.Circle(r)
+//   - a struct shape, like @Point{ x: 0, y: y }, where the properties left out match anything
+func checkPattern(pattern ast.Node, expected ExprType, env *TypeEnvironment) patternInfo {
+
+	switch t := pattern.(type) {
+	case ast.IdentifierExpr:
+		switch t.Name {
+		case "_":
+			return patternInfo{irrefutable: true}
+		case "true", "false":
+			checkPatternType(pattern, expected, NewBool(), env)
+			return patternInfo{variant: t.Name, constant: t.Name}
+		case "null":
+			if _, ok := unwrapType(expected).(Maybe); !ok {
+				errgen.Add(env.filePath, t.Start.Line, t.End.Line, t.Start.Column, t.End.Column, fmt.Sprintf("cannot match null against '%s'", tcValueToString(expected))).Level(errgen.NORMAL_ERROR)
+			}
+			return patternInfo{constant: t.Name}
+		}
+		if err := env.declareVar(t.Name, expected, false, false); err != nil {
+			errgen.Add(env.filePath, t.Start.Line, t.End.Line, t.Start.Column, t.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+		}
+		return patternInfo{irrefutable: true}
+
+	case ast.IntegerLiteralExpr, ast.FloatLiteralExpr, ast.StringLiteralExpr, ast.ByteLiteralExpr, ast.UnaryExpr:
+		valueType := parseNodeValue(pattern, env)
+		key, ok := caseConstant(pattern, valueType)
+		if !ok {
+			errgen.Add(env.filePath, pattern.StartPos().Line, pattern.EndPos().Line, pattern.StartPos().Column, pattern.EndPos().Column, "pattern must be a constant").Level(errgen.NORMAL_ERROR)
+			return patternInfo{}
+		}
+		checkPatternType(pattern, expected, valueType, env)
+		return patternInfo{constant: key}
+
+	case ast.RangeExpr:
+		if t.Step != nil {
+			errgen.Add(env.filePath, t.Step.StartPos().Line, t.Step.EndPos().Line, t.Step.StartPos().Column, t.Step.EndPos().Column, "a range pattern cannot have a step").Level(errgen.NORMAL_ERROR)
+		}
+		rangeType := checkRangeExpr(t, env)
+		if !isIntType(expected) {
+			errgen.Add(env.filePath, t.Start.StartPos().Line, t.End.EndPos().Line, t.Start.StartPos().Column, t.End.EndPos().Column, fmt.Sprintf("cannot match a range against '%s'", tcValueToString(expected))).Level(errgen.NORMAL_ERROR)
+			return patternInfo{}
+		}
+		checkPatternType(pattern, NewRange(expected), rangeType, env)
+		return patternInfo{}
+
+	case ast.StructPropertyAccessExpr:
+		variant, ok := patternVariant(t, expected, env)
+		if !ok {
+			return patternInfo{}
+		}
+		if len(variant.Fields) > 0 {
+			errgen.Add(env.filePath, t.Start.Line, t.End.Line, t.Start.Column, t.End.Column, fmt.Sprintf("variant '%s' has %d fields", variant.Name, len(variant.Fields))).Hint(fmt.Sprintf("match them like %s(...)", variant.Name)).Level(errgen.NORMAL_ERROR)
+			return patternInfo{}
+		}
+		return patternInfo{variant: variant.Name}
+
+	case ast.FunctionCallExpr:
+		access, ok := t.Caller.(ast.StructPropertyAccessExpr)
+		if !ok {
+			break
+		}
+		variant, ok := patternVariant(access, expected, env)
+		if !ok {
+			return patternInfo{}
+		}
+		if len(t.Arguments) != len(variant.Fields) {
+			errgen.Add(env.filePath, t.Start.Line, t.End.Line, t.Start.Column, t.End.Column, fmt.Sprintf("variant '%s' has %d fields, got %d patterns", variant.Name, len(variant.Fields), len(t.Arguments))).Level(errgen.NORMAL_ERROR)
+			return patternInfo{}
+		}
+		complete := true
+		for i, field := range t.Arguments {
+			if !checkPattern(field, variant.Fields[i].Type, env).irrefutable {
+				complete = false
+			}
+		}
+		if !complete {
+			return patternInfo{}
+		}
+		return patternInfo{variant: variant.Name}
+
+	case ast.StructLiteral:
+		structType, ok := unwrapType(expected).(Struct)
+		if !ok || structType.StructName != t.Identifier.Name {
+			errgen.Add(env.filePath, t.Start.Line, t.End.Line, t.Start.Column, t.End.Column, fmt.Sprintf("cannot match struct '%s' against '%s'", t.Identifier.Name, tcValueToString(expected))).Level(errgen.NORMAL_ERROR)
+			return patternInfo{}
+		}
+		complete := true
+		for _, prop := range t.Properties {
+			property, ok := instanceScope(structType).variables[prop.Prop.Name].(StructProperty)
+			if !ok {
+				errgen.Add(env.filePath, prop.Prop.Start.Line, prop.Prop.End.Line, prop.Prop.Start.Column, prop.Prop.End.Column, fmt.Sprintf("property '%s' is not defined on struct '%s'", prop.Prop.Name, t.Identifier.Name)).Level(errgen.NORMAL_ERROR)
+				complete = false
+				continue
+			}
+			if !checkPattern(prop.Value, property.Type, env).irrefutable {
+				complete = false
+			}
+		}
+		return patternInfo{irrefutable: complete}
+	}
+
+	errgen.Add(env.filePath, pattern.StartPos().Line, pattern.EndPos().Line, pattern.StartPos().Column, pattern.EndPos().Column, "invalid pattern").Hint("a pattern can be a literal, a range, a name, _, an enum variant or a struct shape").Level(errgen.NORMAL_ERROR)
+	return patternInfo{}
+}
+
+func checkPatternType(pattern ast.Node, expected, provided ExprType, env *TypeEnvironment) {
+	if err := matchTypes(expected, provided); err != nil {
+		errgen.Add(env.filePath, pattern.StartPos().Line, pattern.EndPos().Line, pattern.StartPos().Column, pattern.EndPos().Column, fmt.Sprintf("invalid pattern. %s", err.Error())).Level(errgen.NORMAL_ERROR)
+	}
+}
+
+// patternVariant finds the variant named by a pattern like Shape.Circle, which must belong to the enum being matched
+func patternVariant(access ast.StructPropertyAccessExpr, expected ExprType, env *TypeEnvironment) (EnumVariant, bool) {
+
+	// the enum is named by itself, like Shape, or through the package it is imported from, like geo.Shape
+	var typeName string
+	switch object := access.Object.(type) {
+	case ast.IdentifierExpr:
+		typeName = object.Name
+	case ast.StructPropertyAccessExpr:
+		if _, ok := packageOf(object.Object, env); ok {
+			typeName = object.Property.Name
+		}
+	}
+	if typeName == "" {
+		errgen.Add(env.filePath, access.Start.Line, access.End.Line, access.Start.Column, access.End.Column, "invalid pattern").Hint("a pattern can be a literal, a range, a name, _, an enum variant or a struct shape").Level(errgen.NORMAL_ERROR)
+		return EnumVariant{}, false
+	}
+
+	enum, ok := unwrapType(expected).(Enum)
+	if !ok || enum.EnumName != typeName {
+		errgen.Add(env.filePath, access.Start.Line, access.End.Line, access.Start.Column, access.End.Column, fmt.Sprintf("cannot match '%s.%s' against '%s'", typeName, access.Property.Name, tcValueToString(expected))).Level(errgen.NORMAL_ERROR)
+		return EnumVariant{}, false
+	}
+
+	variant, ok := enum.variant(access.Property.Name)
+	if !ok {
+		prop := access.Property
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("'%s' is not a variant of enum '%s'", prop.Name, enum.EnumName)).Level(errgen.NORMAL_ERROR)
+		return EnumVariant{}, false
+	}
+
+	return variant, true
+}
+
+// matchResultType unifies the types of the arms of a match the way an array literal unifies its elements.
+// Arms that give null make the result a maybe of the other arms' type.
+func matchResultType(node ast.MatchExpr, armTypes []ExprType, env *TypeEnvironment) ExprType {
+
+	values := make([]ExprType, 0, len(armTypes))
+	hasNull := false
+	for _, armType := range armTypes {
+		if armType.DType() == NULL_TYPE {
+			hasNull = true
+			continue
+		}
+		values = append(values, armType)
+	}
+
+	result := arrayElementType(values)
+	if result == nil {
+		if hasNull {
+			return NewNull()
+		}
+		return NewVoid()
+	}
+
+	for i, armType := range armTypes {
+		if armType.DType() == NULL_TYPE {
+			continue
+		}
+		if err := matchTypes(result, armType); err != nil {
+			value := node.Cases[i].Value
+			errgen.Add(env.filePath, value.StartPos().Line, value.EndPos().Line, value.StartPos().Column, value.EndPos().Column, fmt.Sprintf("match arms must have the same type. %s", err.Error())).Level(errgen.NORMAL_ERROR)
+		}
+	}
+
+	if _, ok := unwrapType(result).(Maybe); hasNull && !ok {
+		return NewMaybe(result)
+	}
+
+	return result
+}
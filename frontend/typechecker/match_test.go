@@ -0,0 +1,110 @@
+package typechecker
+
+import (
+	"strings"
+	"testing"
+
+	"walrus/errgen"
+)
+
+func TestMatch(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type MatchShape enum {
+			Circle(radius: f32),
+			Rect(w: f32, h: f32),
+			Empty,
+		};
+		type MatchPoint struct {
+			x: i32,
+			y: i32,
+		};
+
+		let s := MatchShape.Circle(2.0);
+		let area: f32 = match s {
+			case MatchShape.Circle(r) => r * r,
+			case MatchShape.Rect(w, h) => w * h,
+			case MatchShape.Empty => 0,
+		};
+
+		let n: i32 = 5;
+		let label: str = match n {
+			case 0 => "zero",
+			case x if x < 0 => "negative",
+			case 1..10 => "small",
+			case _ => "big",
+		};
+
+		let m: maybe{i32} = null;
+		let v: i32 = match m {
+			case null => 0,
+			case x => x,
+		};
+
+		let p := @MatchPoint{ x: 1, y: 2 };
+		let onAxis: bool = match p {
+			case @MatchPoint{ x: 0 } => true,
+			case @MatchPoint{ y: 0 } => true,
+			case @MatchPoint{ x: px, y: py } => px == py,
+		};
+
+		let found: maybe{i32} = match n {
+			case 0 => null,
+			case _ => n,
+		};
+	`))
+}
+
+func TestMatchExhaustiveness(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		missing []string
+	}{
+		{"enum", "type MatchA enum { One, Two(n: i32), Three }; let a := MatchA.One; let r := match a { case MatchA.One => 1 };", []string{"MatchA.Two", "MatchA.Three"}},
+		{"refutable field", "type MatchB enum { One(n: i32) }; let b := MatchB.One(1); let r := match b { case MatchB.One(0) => 1 };", []string{"MatchB.One"}},
+		{"guarded", "let n := 1; let r := match n { case x if x > 0 => 1 };", []string{"_"}},
+		{"bool", "let b := true; let r := match b { case true => 1 };", []string{"false"}},
+		{"maybe", "let m: maybe{i32} = 1; let r := match m { case x => x };", []string{"null"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Message, "non-exhaustive match") {
+				t.Fatalf("expected a non-exhaustive match, got %v", diagnostics)
+			}
+			for _, missing := range tt.missing {
+				if !strings.Contains(diagnostics[0].Message, "missing case "+missing) {
+					t.Errorf("expected %q to be listed as missing, got %q", missing, diagnostics[0].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+		level   errgen.PROBLEM_TYPE
+	}{
+		{"unreachable", "let n := 1; let r := match n { case _ => 1, case 2 => 2 };", "unreachable match arm", errgen.WARNING},
+		{"repeated literal", "let n := 1; let r := match n { case 2 => 1, case 2 => 2, case _ => 3 };", "unreachable match arm", errgen.WARNING},
+		{"arm types", `let n := 1; let r := match n { case 1 => "one", case _ => 2 };`, "match arms must have the same type. cannot assign value of type 'untyped int' to type 'str'", errgen.NORMAL_ERROR},
+		{"guard", "let n := 1; let r := match n { case x if x => 1, case _ => 2 };", "match guard must be a boolean expression, got 'i32'", errgen.NORMAL_ERROR},
+		{"pattern type", `let n := 1; let r := match n { case "a" => 1, case _ => 2 };`, "invalid pattern. cannot assign value of type 'str' to type 'i32'", errgen.NORMAL_ERROR},
+		{"null", "let n := 1; let r := match n { case null => 1, case _ => 2 };", "cannot match null against 'i32'", errgen.NORMAL_ERROR},
+		{"fields", "type MatchC enum { One(n: i32) }; let c := MatchC.One(1); let r := match c { case MatchC.One(a, b) => 1, case _ => 2 };", "variant 'One' has 1 fields, got 2 patterns", errgen.NORMAL_ERROR},
+		{"arm scope", "let n := 1; let r := match n { case x => x, };\nx = 2;", "cannot assign to 'x' was not declared in this scope", errgen.CRITICAL_ERROR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message || diagnostics[0].Level != tt.level {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
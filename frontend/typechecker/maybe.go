@@ -0,0 +1,100 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+)
+
+// RuntimeCheck is a place in the program that the typechecker cannot prove safe, so it is checked when the program
+// runs. The unwrap operator a! is one: it stops the program if a is null.
+type RuntimeCheck struct {
+	FilePath string
+	Location ast.Location
+	Reason   string
+}
+
+// RuntimeChecks returns the sites of the package the scope is in that are checked at runtime, in the order they were checked.
+// They are kept with the package, so every check of a program has its own.
+func (t *TypeEnvironment) RuntimeChecks() []RuntimeCheck {
+	return t.packageScope().runtimeChecks
+}
+
+// checkNullCoalescingExpr checks a ?? b. a must be a maybe{T}, and b the value to use instead of null.
+// The value is T, or maybe{T} when b can be null too.
+func checkNullCoalescingExpr(node ast.NullCoalescingExpr, env *TypeEnvironment) ExprType {
+
+	left := parseNodeValue(node.Left, env)
+
+	maybe, ok := unwrapType(left).(Maybe)
+	if !ok {
+		errgen.Add(env.filePath, node.Left.StartPos().Line, node.Left.EndPos().Line, node.Left.StartPos().Column, node.Left.EndPos().Column, fmt.Sprintf("'??' needs a maybe value on its left, got '%s'", tcValueToString(left))).Hint("the value is never null, so it needs no default").Level(errgen.NORMAL_ERROR)
+		return left
+	}
+
+	right := parseNodeValue(node.Right, env)
+
+	if err := matchTypes(maybe, right); err != nil {
+		errgen.Add(env.filePath, node.Right.StartPos().Line, node.Right.EndPos().Line, node.Right.StartPos().Column, node.Right.EndPos().Column, fmt.Sprintf("cannot use '%s' as the default of '%s'", tcValueToString(right), tcValueToString(left))).Level(errgen.NORMAL_ERROR)
+	}
+
+	if _, ok := unwrapType(right).(Maybe); ok || right.DType() == NULL_TYPE {
+		return maybe
+	}
+
+	return maybe.MaybeType
+}
+
+// checkOptionalPropertyAccess checks obj?.property on a maybe{T} obj. The value is a maybe of the property of T,
+// which is null when obj is null. For a method, the call gives a maybe of what the method returns.
+// On a result, obj?.property is the try operator followed by the property, like (obj?).property.
+func checkOptionalPropertyAccess(node ast.OptionalPropertyAccessExpr, env *TypeEnvironment) ExprType {
+
+	object := parseNodeValue(node.Object, env)
+
+	switch t := unwrapType(object).(type) {
+	case Maybe:
+		return optional(checkMemberOf(unwrapType(t.MaybeType), node.Property, env))
+	case Result:
+		return checkMemberOf(unwrapType(propagateError(t, node.Object, env)), node.Property, env)
+	default:
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, fmt.Sprintf("'?.' is used on maybe values, '%s' is never null", tcValueToString(object))).Hint("use '.' instead").Level(errgen.NORMAL_ERROR)
+		return checkMemberOf(object, node.Property, env)
+	}
+}
+
+// optional returns the maybe of a type. A maybe stays as it is, so a?.b is maybe{T} when b is maybe{T} too.
+func optional(value ExprType) ExprType {
+	switch t := unwrapType(value).(type) {
+	case Maybe:
+		return t
+	case Void, Null:
+		return t
+	}
+	if _, ok := value.(StructMethod); ok {
+		return NewMaybe(value)
+	}
+	return NewMaybe(unwrapType(value))
+}
+
+// checkUnwrapExpr checks a! on a maybe{T}. The value is T. The typechecker cannot know that a is not null,
+// so the site is recorded as a runtime check.
+func checkUnwrapExpr(node ast.UnwrapExpr, env *TypeEnvironment) ExprType {
+
+	value := parseNodeValue(node.Argument, env)
+
+	maybe, ok := unwrapType(value).(Maybe)
+	if !ok {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, fmt.Sprintf("cannot unwrap '%s', it is not a maybe value", tcValueToString(value))).Hint("remove the '!'").Level(errgen.NORMAL_ERROR)
+		return value
+	}
+
+	scope := env.packageScope()
+	scope.runtimeChecks = append(scope.runtimeChecks, RuntimeCheck{
+		FilePath: env.filePath,
+		Location: node.Location,
+		Reason:   fmt.Sprintf("unwrap of '%s' stops the program when it is null", tcValueToString(value)),
+	})
+
+	return maybe.MaybeType
+}
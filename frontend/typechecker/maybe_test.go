@@ -0,0 +1,265 @@
+package typechecker
+
+import (
+	"testing"
+)
+
+const maybeUser = `
+	type MayUser struct {
+		name: str,
+		nick: maybe{str},
+	};
+	impl MayUser {
+		fn getName() -> str {
+			ret this.name;
+		}
+		fn greet() {}
+	}
+	let u: maybe{MayUser} = null;
+`
+
+func TestMaybeOperators(t *testing.T) {
+	expectNoProblems(t, checkSource(t, maybeUser+`
+		let name: str = u?.name ?? "anon";
+		let nick: maybe{str} = u?.nick;
+		let first: str = u?.nick ?? u?.name ?? "x";
+		let called: maybe{str} = u?.getName();
+		u?.greet();
+		let user: MayUser = u!;
+		let forced: str = u!.name;
+		let same: bool = u?.name ?? "a" == "a";
+		let a: maybe{i32} = 3;
+		let b: i32 = a ?? 0;
+		let c: maybe{i32} = a ?? null;
+	`))
+}
+
+func TestMaybeOperatorProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"coalescing a value", "let a := 1 ?? 2;", "'??' needs a maybe value on its left, got 'untyped int'"},
+		{"default type", "let a: maybe{i32} = 1; let b := a ?? \"x\";", "cannot use 'str' as the default of 'maybe{i32}'"},
+		{"chaining a value", "let v: MayUser = u!; let n := v?.name;", "'?.' is used on maybe values, 'MayUser' is never null"},
+		{"chain is a maybe", "let n: str = u?.name;", "error declaring variable 'n'. cannot assign value of type 'maybe{str}' to type 'str'"},
+		{"chain needs each step", "let n := u?.nick.length;", "'length' does not exist on type 'maybe{str}'"},
+		{"unwrapping a value", "let a := 1; let b := a!;", "cannot unwrap 'i32', it is not a maybe value"},
+		{"safe on a value", "let a := 1; safe a {} otherwise {}", "safe-otherwise can only be used with 'maybe' types, got 'i32'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, maybeUser+tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
+
+func TestUnwrapIsRuntimeChecked(t *testing.T) {
+	diagnostics, env := checkSourceEnv(t, maybeUser+`let v := u!; let n := u?.name ?? "x";`)
+	expectNoProblems(t, diagnostics)
+
+	checks := env.RuntimeChecks()
+	if len(checks) != 1 || checks[0].Location.Start.Column != 10 {
+		t.Errorf("expected the unwrap to be checked at runtime, got %v", checks)
+	}
+}
+
+func TestSafeMaybeAlias(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type MayInt maybe{i32};
+		let a: MayInt = 3;
+		safe a {
+			let b: i32 = a + 1;
+		} otherwise {}
+	`))
+}
+
+func TestSafeBindings(t *testing.T) {
+	expectNoProblems(t, checkSource(t, maybeUser+`
+		let ages := $map[str]i32{"ana" => 30};
+		fn safeFind(id: i32) -> maybe{MayUser} {
+			ret null;
+		}
+		safe age := ages["ana"] {
+			let a: i32 = age;
+		}
+		safe found := safeFind(1), nick := u?.nick {
+			let n: str = found.name + nick;
+		} otherwise {
+			let f: maybe{str} = u?.nick;
+		}
+		let a: maybe{i32} = 1;
+		let b: maybe{str} = "b";
+		safe a, b {
+			let c: i32 = a;
+			let d: str = b;
+			a = null;
+		}
+		fn safeAge(name: str) -> i32 {
+			safe age := ages[name] {} otherwise {
+				ret 0;
+			}
+			ret age + 1;
+		}
+		fn safeLength(s: maybe{str}) -> i32 {
+			safe s {} otherwise {
+				if true {
+					ret 0;
+				} else {
+					ret 1;
+				}
+			}
+			let t: str = s;
+			ret 0;
+		}
+		fn safeSum(a: maybe{i32}) -> i32 {
+			let sum: i32 = 0;
+			safe a {
+				for let i := 0; i < 3; i++ {
+					sum += a + i;
+				}
+			} otherwise {}
+			ret sum;
+		}
+	`))
+}
+
+func TestSafeBindingProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"binding a value", "safe v := 1 {}", "safe-otherwise can only be used with 'maybe' types, got 'untyped int'"},
+		{"indexing an array", "let xs := [1, 2]; safe v := xs[0] {}", "safe-otherwise can only be used with 'maybe' types, got 'i32'"},
+		{"name outside its block", "safe v := u?.nick {} otherwise {} let s: str = v;", "'v' was not declared in this scope"},
+		{"otherwise that goes on", "let a: maybe{i32} = 1; safe a {} otherwise {} let b: i32 = a;", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"one of many is null", "let a: maybe{i32} = 1; let b: maybe{i32} = 1; safe a, b {} otherwise { let c: maybe{i32} = a; let d: i32 = b; }", "error declaring variable 'd'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned after", "fn f(a: maybe{i32}) { safe a {} otherwise { ret; } a = null; let b: i32 = a; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"used in a closure", "fn f(a: maybe{i32}) { safe a {} otherwise { ret; } let g := fn() { let b: i32 = a; }; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned in a closure", "fn f(a: maybe{i32}) { safe a {} otherwise { ret; } let g := fn() { let b: i32 = a; a = null; }; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned in an earlier closure", "fn f(a: maybe{i32}) { let clear := fn() { a = null; }; safe a {} otherwise { ret; } clear(); let b: i32 = a; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned in a function", "let a: maybe{i32} = 1; fn clearA() { a = null; } safe a { clearA(); let b: i32 = a; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned in a loop", "let a: maybe{i32} = 1; safe a { for let i := 0; i < 3; i++ { let b: i32 = a; a = null; } }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned by a closure in a loop", "fn f(a: maybe{i32}) { safe a { for let i := 0; i < 3; i++ { let b: i32 = a; let clear := fn() { a = null; }; clear(); } } }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"pub variable", "pub let a: maybe{i32} = 1; safe a { let b: i32 = a; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"redeclared in the block", "let a: maybe{i32} = 1; safe a { let a: maybe{i32} = null; let c: i32 = a; }", "error declaring variable 'c'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, maybeUser+tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
+
+func TestNullNarrowing(t *testing.T) {
+	expectNoProblems(t, checkSource(t, maybeUser+`
+		let a: maybe{i32} = 1;
+		let b: maybe{i32} = null;
+		if a != null {
+			let c: i32 = a;
+		} else {
+			let c: maybe{i32} = a;
+		}
+		if null == a {} else {
+			let c: i32 = a;
+		}
+		if a != null && a > 0 {}
+		if a == null || a > 0 {}
+		if !(a == null) && b != null {
+			let c: i32 = a + b;
+		}
+		if a != null {
+			let c := 1;
+		}
+		if a != null {
+			for let i := 0; i < 3; i++ {
+				let d: i32 = a + i;
+			}
+		}
+		let c := 2;
+		fn narrowLength(s: maybe{str}, t: maybe{str}) -> i32 {
+			if s == null || t == null {
+				ret 0;
+			}
+			let both: str = s + t;
+			ret 1;
+		}
+		fn narrowFirst(s: maybe{str}) -> str {
+			if s == null {
+				ret "";
+			} else if s == "" {
+				ret "empty";
+			}
+			ret s;
+		}
+		fn narrowLoop(xs: []maybe{i32}) -> i32 {
+			let total := 0;
+			foreach x in xs {
+				if x == null {
+					continue;
+				}
+				total += x;
+			}
+			ret total;
+		}
+		fn narrowNext() -> maybe{i32} {
+			ret 1;
+		}
+		fn narrowWait() -> i32 {
+			let v := narrowNext();
+			for v == null {
+				v = narrowNext();
+			}
+			ret v;
+		}
+		fn narrowDrain() -> i32 {
+			let v := narrowNext();
+			let sum: i32 = 0;
+			for v != null {
+				sum += v;
+				v = narrowNext();
+			}
+			ret sum;
+		}
+	`))
+}
+
+func TestNullNarrowingProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"outside the branch", "let a: maybe{i32} = 1; if a != null {} let b: i32 = a;", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned in the branch", "let a: maybe{i32} = 1; if a != null { a = null; let b: i32 = a; }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned in a loop", "let a: maybe{i32} = 1; if a != null { foreach x in [1, 2] { let b: i32 = a; a = x; } }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"assigned by a closure in a loop", "fn f(a: maybe{i32}) { if a == null { ret; } foreach x in [1, 2] { let b: i32 = a; let clear := fn() { a = null; }; clear(); } }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"redeclared in the branch", "let a: maybe{i32} = 1; if a != null { let a: maybe{i32} = null; let c: i32 = a; }", "error declaring variable 'c'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"either side of or", "let a: maybe{i32} = 1; let b: maybe{i32} = 1; if a != null || b != null { let c: i32 = a; }", "error declaring variable 'c'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"branch that goes on", "fn f(a: maybe{i32}) -> i32 { if a == null { let b := 1; } ret a; }", "cannot return 'maybe{i32}' from this scope. function 'f' expects return type 'i32'"},
+		{"assigned after the return", "fn f(a: maybe{i32}) -> i32 { if a == null { ret 0; } a = null; ret a; }", "cannot return 'maybe{i32}' from this scope. function 'f' expects return type 'i32'"},
+		{"closure called after an assignment", "fn f(a: maybe{i32}) -> i32 { if a == null { ret 0; } let g := fn() -> i32 { let b: i32 = a; ret b; }; a = null; ret g(); }", "error declaring variable 'b'. cannot assign value of type 'maybe{i32}' to type 'i32'"},
+		{"closure that assigns, called after the return", "fn f(a: maybe{i32}) -> i32 { let clear := fn() { a = null; }; if a == null { ret 0; } clear(); ret a; }", "cannot return 'maybe{i32}' from this scope. function 'f' expects return type 'i32'"},
+		{"loop with a break", "fn f(a: maybe{i32}) -> i32 { for a == null { break; } ret a; }", "cannot return 'maybe{i32}' from this scope. function 'f' expects return type 'i32'"},
+		{"comparing a value with null", "let a := 1; let b := a == null;", "invalid compare operation between 'i32' and 'null'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, maybeUser+tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
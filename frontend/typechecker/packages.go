@@ -0,0 +1,247 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+	"walrus/frontend/loader"
+)
+
+// CheckProgram checks the packages of a program, each after the packages it imports. Every package is checked
+// in an environment of its own inside env, which the files of the package share. The imports and type declarations
+// of all the files are checked first, then the signatures of their top-level functions, so that each file can use
+// the types and call the functions of the others. The other statements and the function bodies follow file by file,
+// in the order of the file names.
+func CheckProgram(program *loader.Program, env *TypeEnvironment) {
+
+	scopes := make(map[*loader.Package]*TypeEnvironment, len(program.Packages))
+
+	for _, pkg := range program.Packages {
+		pkgEnv := NewTypeENV(env, GLOBAL_SCOPE, pkg.Name, env.filePath)
+		pkgEnv.packages = make(map[string]Package, len(pkg.Imports))
+		for _, imported := range pkg.Imports {
+			pkgEnv.packages[imported.Path] = NewPackage(imported.Name, imported.Path, scopes[imported])
+		}
+
+		for _, file := range pkg.Files {
+			pkgEnv.declareFunctionAssigned(file.Tree.Contents...)
+		}
+
+		for _, file := range pkg.Files {
+			pkgEnv.filePath = file.Path
+			for _, node := range file.Tree.Contents {
+				if isPackageDeclaration(node) {
+					CheckAST(node, pkgEnv)
+				}
+			}
+		}
+
+		functions := make(map[declaredFunction]hoistedFunction)
+		for _, file := range pkg.Files {
+			pkgEnv.filePath = file.Path
+			for _, node := range file.Tree.Contents {
+				if funcNode, ok := node.(ast.FunctionDeclStmt); ok {
+					fn, fnEnv := declareFunctionDecl(funcNode, pkgEnv)
+					functions[declaredFunction{file.Path, funcNode.Start}] = hoistedFunction{fn, fnEnv}
+				}
+			}
+		}
+
+		for _, file := range pkg.Files {
+			pkgEnv.filePath = file.Path
+			for _, node := range file.Tree.Contents {
+				if isPackageDeclaration(node) {
+					continue
+				}
+				if funcNode, ok := node.(ast.FunctionDeclStmt); ok {
+					hoisted := functions[declaredFunction{file.Path, funcNode.Start}]
+					checkFunctionBody(funcNode.FunctionLiteral, hoisted.fn, hoisted.env)
+					continue
+				}
+				CheckAST(node, pkgEnv)
+			}
+		}
+
+		scopes[pkg] = pkgEnv
+	}
+}
+
+// declaredFunction identifies a top-level function declaration by where it is written
+type declaredFunction struct {
+	filePath string
+	start    lexer.Position
+}
+
+// hoistedFunction is a top-level function whose signature is declared, and the scope its body is checked in
+type hoistedFunction struct {
+	fn  Fn
+	env *TypeEnvironment
+}
+
+// isPackageDeclaration reports whether a top-level statement is checked before the rest of the files of a package
+func isPackageDeclaration(node ast.Node) bool {
+	switch node.(type) {
+	case ast.PackageStmt, ast.ImportStmt, ast.TypeDeclStmt:
+		return true
+	}
+	return false
+}
+
+// checkImportStmt binds each imported package to the name it is imported as. The files of a package share
+// their top-level scope, so the same package may be imported by several of them.
+func checkImportStmt(node ast.ImportStmt, env *TypeEnvironment) ExprType {
+
+	if env.scopeType != GLOBAL_SCOPE {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, "packages can only be imported at the top level of a file").Level(errgen.NORMAL_ERROR)
+		return NewVoid()
+	}
+
+	for _, spec := range node.Imports {
+		path := spec.Path
+
+		pkg, ok := env.resolveImport(path.Value)
+		if !ok {
+			errgen.Add(env.filePath, path.Start.Line, path.End.Line, path.Start.Column, path.End.Column, fmt.Sprintf("cannot find package '%s'", path.Value)).Level(errgen.CRITICAL_ERROR)
+		}
+
+		pkg.Name = spec.Name()
+
+		if imported, ok := env.variables[pkg.Name].(Package); ok && imported.Path == pkg.Path {
+			continue
+		}
+
+		if err := env.declareVar(pkg.Name, pkg, true, false); err != nil {
+			errgen.Add(env.filePath, spec.Start.Line, spec.End.Line, spec.Start.Column, spec.End.Column, fmt.Sprintf("cannot import '%s' as '%s'. %s", path.Value, pkg.Name, err.Error())).Level(errgen.NORMAL_ERROR)
+		}
+	}
+
+	return NewVoid()
+}
+
+// checkPackageMember checks the use of a public symbol of an imported package, like sys.print
+func checkPackageMember(pkg Package, prop ast.IdentifierExpr, env *TypeEnvironment) ExprType {
+
+	if _, ok := pkg.Scope.types[prop.Name]; ok {
+		if !pkg.Scope.publicType[prop.Name] {
+			errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("type '%s' is not public in package '%s'", prop.Name, pkg.Name)).Hint("mark it pub to use it from other packages").Level(errgen.CRITICAL_ERROR)
+		}
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, "cannot use type as value").Level(errgen.CRITICAL_ERROR)
+	}
+
+	member, ok := pkg.Scope.variables[prop.Name]
+	if _, isPackage := member.(Package); !ok || isPackage {
+		// the packages it imports itself are not part of a package
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("'%s' is not declared in package '%s'", prop.Name, pkg.Name)).Level(errgen.CRITICAL_ERROR)
+	}
+
+	if !pkg.Scope.public[prop.Name] {
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("'%s' is not public in package '%s'", prop.Name, pkg.Name)).Hint("mark it pub to use it from other packages").Level(errgen.NORMAL_ERROR)
+	}
+
+	return unwrapType(member)
+}
+
+// typeMember returns the public type with the given name declared at the top level of the package's files
+func (p Package) typeMember(name string) (ExprType, bool) {
+	typ, ok := p.Scope.types[name]
+	return typ, ok && p.Scope.publicType[name]
+}
+
+// declarePublic marks a top-level declaration as pub, so that the packages importing this one can use it
+func declarePublic(name string, isType bool, node ast.IdentifierExpr, env *TypeEnvironment) {
+
+	if env.scopeType != GLOBAL_SCOPE {
+		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, "only top-level declarations can be pub").Level(errgen.NORMAL_ERROR)
+		return
+	}
+
+	if isType {
+		env.publicType[name] = true
+	} else {
+		env.public[name] = true
+	}
+}
+
+// checkMemberAccess reports the use of a struct or enum member where it cannot be used: a priv member outside the
+// methods of its owner, or a member without pub outside the owner's package. Owner is the scope of the owner's members.
+func checkMemberAccess(prop ast.IdentifierExpr, member ExprType, owner TypeEnvironment, ownerName string, env *TypeEnvironment) {
+
+	var kind string
+	var isPrivate, isPublic bool
+	var declared Declaration
+
+	switch t := member.(type) {
+	case StructProperty:
+		kind, isPrivate, isPublic, declared = "property", t.IsPrivate, t.IsPublic, t.Declared
+	case StructMethod:
+		kind, isPrivate, isPublic, declared = "method", t.IsPrivate, t.IsPublic, t.Declared
+	default:
+		return
+	}
+
+	if isPrivate && !env.isInMethodOf(owner) {
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("cannot access private %s '%s' of '%s' from outside of its methods", kind, prop.Name, ownerName)).Hint(fmt.Sprintf("'%s' is declared private at %s", prop.Name, declared)).Level(errgen.NORMAL_ERROR)
+		return
+	}
+
+	if !isPublic && !isPrivate && owner.packageScope() != env.packageScope() {
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("cannot access %s '%s' of '%s' from outside of its package", kind, prop.Name, ownerName)).Hint(fmt.Sprintf("'%s' is declared at %s. mark it pub to use it from other packages", prop.Name, declared)).Level(errgen.NORMAL_ERROR)
+	}
+}
+
+// packageOf returns the imported package a node names, like sys in sys.print
+func packageOf(node ast.Node, env *TypeEnvironment) (Package, bool) {
+	identifier, ok := node.(ast.IdentifierExpr)
+	if !ok {
+		return Package{}, false
+	}
+	scope, err := env.resolveVar(identifier.Name)
+	if err != nil {
+		return Package{}, false
+	}
+	pkg, ok := scope.variables[identifier.Name].(Package)
+	return pkg, ok
+}
+
+// getPackageType finds a type declared by an imported package, like Stack in ds.Stack
+func (t *TypeEnvironment) getPackageType(pkgName string, name string) (ExprType, error) {
+
+	scope, err := t.resolveVar(pkgName)
+	if err != nil {
+		return nil, fmt.Errorf("package '%s' is not imported", pkgName)
+	}
+
+	pkg, ok := scope.variables[pkgName].(Package)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a package", pkgName)
+	}
+
+	typ, ok := pkg.Scope.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type '%s' is not declared in package '%s'", name, pkgName)
+	}
+	if !pkg.Scope.publicType[name] {
+		return nil, fmt.Errorf("type '%s' is not public in package '%s'", name, pkgName)
+	}
+
+	return unwrapType(typ), nil
+}
+
+// namedType returns the type a node names where a value is expected, like Shape in Shape.Circle
+// or ds.Shape in ds.Shape.Circle
+func namedType(node ast.Node, env *TypeEnvironment) (ExprType, bool) {
+	switch t := node.(type) {
+	case ast.IdentifierExpr:
+		if typ, err := env.getTypeDefinition(t.Name); err == nil {
+			return typ, true
+		}
+	case ast.StructPropertyAccessExpr:
+		if pkg, ok := packageOf(t.Object, env); ok {
+			if typ, ok := pkg.typeMember(t.Property.Name); ok {
+				return unwrapType(typ), true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,206 @@
+package typechecker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"walrus/errgen"
+	"walrus/frontend/loader"
+)
+
+// checkProgram writes the files of a program to a temporary folder, then loads and checks it from main.wal
+func checkProgram(t *testing.T, files map[string]string) []errgen.Diagnostic {
+	t.Helper()
+
+	root := t.TempDir()
+	for path, src := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return errgen.Collect(func() {
+		program, err := loader.Load(filepath.Join(root, "main.wal"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		CheckProgram(program, builtinEnv)
+	})
+}
+
+// pkgLib is a package of two files, imported by the programs below as "lib.shapes"
+var pkgLib = map[string]string{
+	"lib/shapes/shape.wal": `
+		package shapes;
+		import "sys";
+		pub type Shape enum {
+			Circle(r: f32),
+			Square,
+		};
+		pub type Box<T> struct {
+			pub value: T,
+		};
+		pub const SIDES := 4;
+		pub let count: i32 = 0;
+		pub type Counter struct {
+			pub n: i32,
+			by: i32,
+			priv secret: i32,
+		};
+		impl Counter {
+			fn bump() -> i32 {
+				ret this.n + this.by + this.secret;
+			}
+			pub fn get() -> i32 {
+				ret this.n;
+			}
+		}
+		pub fn counter() -> Counter {
+			let c := @Counter{n: 0, by: 1, secret: 2};
+			c.bump();
+			ret c;
+		}
+		pub type Pair struct {
+			pub a: i32,
+			b: i32,
+		};
+		type Hidden struct {
+			x: i32,
+		};
+		fn helper() {}
+	`,
+	"lib/shapes/area.wal": `
+		package shapes;
+		import "sys";
+		pub fn area(s: Shape) -> f32 {
+			sys.print("area");
+			ret match s {
+				case Shape.Circle(r) => r * r,
+				case Shape.Square => 1,
+			};
+		}
+	`,
+	"sys/print.wal": `
+		package sys;
+		pub fn print(s: str) {}
+	`,
+}
+
+func withMain(main string) map[string]string {
+	files := map[string]string{"main.wal": main}
+	for path, src := range pkgLib {
+		files[path] = src
+	}
+	return files
+}
+
+func TestPackages(t *testing.T) {
+	expectNoProblems(t, checkProgram(t, withMain(`
+		package main;
+		import { "sys", "lib.shapes" as geo };
+		// the names of the imported package do not clash with the names declared here
+		type Box struct {
+			label: str,
+		};
+		fn main() {
+			sys.print("hi");
+			let c := geo.Shape.Circle(1.5);
+			let a: f32 = geo.area(c);
+			let sides: i32 = geo.SIDES;
+			geo.count = 2;
+			let b: geo.Box<str> = @geo.Box{value: "v"};
+			let mine := @Box{label: "mine"};
+			let counter := geo.counter();
+			let total: i32 = counter.n + counter.get();
+			let n := match c {
+				case geo.Shape.Circle(r) => 1,
+				case geo.Shape.Square => 2,
+			};
+		}
+	`)))
+}
+
+func TestFunctionsAcrossFiles(t *testing.T) {
+	// a.wal is checked before b.wal, so the signature of helper must be known before any file is checked
+	expectNoProblems(t, checkProgram(t, map[string]string{
+		"main.wal": `
+			package main;
+			import "lib.order";
+			fn main() {
+				let x: i32 = order.X + order.twice(2);
+			}
+		`,
+		"lib/order/a.wal": `
+			package order;
+			pub let X: i32 = helper();
+			pub fn twice(n: i32) -> i32 {
+				ret helper() * n;
+			}
+		`,
+		"lib/order/b.wal": `
+			package order;
+			fn helper() -> i32 {
+				ret twice(0) + 1;
+			}
+		`,
+	}))
+}
+
+func TestPackageProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		main    string
+		message string
+	}{
+		{"unknown member", `import "sys"; sys.println("x");`, "'println' is not declared in package 'sys'"},
+		{"package as value", `import "sys"; let s := sys;`, "cannot use package 'sys' as a value"},
+		{"type as value", `import "lib.shapes"; let s := shapes.Shape;`, "cannot use type as value"},
+		{"unknown type", `import "lib.shapes"; let s: shapes.Square;`, "type 'Square' is not declared in package 'shapes'"},
+		{"not imported", `let s: shapes.Shape;`, "package 'shapes' is not imported"},
+		{"constant", `import "lib.shapes"; shapes.SIDES = 3;`, "cannot assign to constant"},
+		{"imports are not members", `import "lib.shapes"; shapes.sys.print("x");`, "'sys' is not declared in package 'shapes'"},
+		{"nested import", `fn f() { import "sys"; }`, "packages can only be imported at the top level of a file"},
+		{"not pub function", `import "lib.shapes"; shapes.helper();`, "'helper' is not public in package 'shapes'"},
+		{"not pub type", `import "lib.shapes"; let h: shapes.Hidden;`, "type 'Hidden' is not public in package 'shapes'"},
+		{"not pub property", `import "lib.shapes"; let c := shapes.counter(); let s := c.by;`, "cannot access property 'by' of 'Counter' from outside of its package"},
+		{"not pub method", `import "lib.shapes"; let c := shapes.counter(); c.bump();`, "cannot access method 'bump' of 'Counter' from outside of its package"},
+		{"private property", `import "lib.shapes"; let c := shapes.counter(); let s := c.secret;`, "cannot access private property 'secret' of 'Counter' from outside of its methods"},
+		{"not pub literal property", `import "lib.shapes"; let p := @shapes.Pair{a: 1, b: 2};`, "cannot set property 'b' of 'Pair' from outside of its package"},
+		{"alias clash", `import { "sys", "lib.shapes" as sys };`, "cannot import 'lib.shapes' as 'sys'. variable 'sys' is already declared in this scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkProgram(t, withMain(tt.main))
+			if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Message, tt.message) {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
+
+func TestVisibilityProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"nested pub", "fn visF() { pub let a := 1; }", "only top-level declarations can be pub"},
+		{"private of another type", "type Vis1 struct { priv a: i32 }; type Vis2 struct { b: i32 }; impl Vis2 { fn get(v: Vis1) -> i32 { ret v.a; } }", "cannot access private property 'a' of 'Vis1' from outside of its methods"},
+		{"private method", "type Vis3 struct { a: i32 }; impl Vis3 { priv fn get() -> i32 { ret this.a; } } let v := @Vis3{a: 1}; v.get();", "cannot access private method 'get' of 'Vis3' from outside of its methods"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Message, tt.message) {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+)
+
+// checkTryExpr checks value? on a result{T, E}. The value of the expression is T. When the result holds an error,
+// it is returned from the enclosing function right away, so that function must return a result that can carry E.
+func checkTryExpr(node ast.TryExpr, env *TypeEnvironment) ExprType {
+	return propagateError(parseNodeValue(node.Argument, env), node, env)
+}
+
+// propagateError gives the success type of a result whose error is returned early from the enclosing function
+func propagateError(value ExprType, node ast.Node, env *TypeEnvironment) ExprType {
+
+	start, end := node.StartPos(), node.EndPos()
+
+	result, ok := unwrapType(value).(Result)
+	if !ok {
+		errgen.Add(env.filePath, start.Line, end.Line, start.Column, end.Column, fmt.Sprintf("cannot use '?' on a value of type '%s'", tcValueToString(value))).Hint("'?' unwraps a result{T, E}, like parse(s)?").Level(errgen.CRITICAL_ERROR)
+	}
+
+	if !env.isInFunctionScope() {
+		errgen.Add(env.filePath, start.Line, end.Line, start.Column, end.Column, "'?' can only be used inside a function").Hint("it returns the error from the enclosing function").Level(errgen.CRITICAL_ERROR)
+	}
+
+	fnReturns := getFunctionReturnValue(env, node)
+
+	expected, ok := unwrapType(fnReturns).(Result)
+	if !ok {
+		errgen.Add(env.filePath, start.Line, end.Line, start.Column, end.Column, fmt.Sprintf("cannot propagate the error of '%s'. the enclosing function returns '%s'", tcValueToString(result), tcValueToString(fnReturns))).Hint(fmt.Sprintf("make the function return a result that can carry it, like T!%s", tcValueToString(result.ErrType))).Level(errgen.NORMAL_ERROR)
+		return result.OkType
+	}
+
+	if err := matchTypes(expected.ErrType, result.ErrType); err != nil {
+		errgen.Add(env.filePath, start.Line, end.Line, start.Column, end.Column, fmt.Sprintf("cannot propagate error of type '%s'. the enclosing function returns '%s'", tcValueToString(result.ErrType), tcValueToString(fnReturns))).Hint(fmt.Sprintf("return the error type '%s' or the interface '%s' from the function", tcValueToString(result.ErrType), ERROR_INTERFACE)).Level(errgen.NORMAL_ERROR)
+	}
+
+	return result.OkType
+}
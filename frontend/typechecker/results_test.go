@@ -0,0 +1,87 @@
+package typechecker
+
+import (
+	"testing"
+)
+
+// resErrors declares the error types the result tests below use
+const resErrors = `
+	type ResParseError struct {
+		msg: str,
+	};
+	impl ResParseError {
+		fn message() -> str {
+			ret this.msg;
+		}
+	}
+	type ResIOError struct {
+		code: i32,
+	};
+	impl ResIOError {
+		fn message() -> str {
+			ret "io";
+		}
+	}
+	fn resParse(s: str) -> i32!ResParseError {
+		if s == "" {
+			ret @ResParseError{msg: "empty"};
+		}
+		ret 42;
+	}
+`
+
+func TestResults(t *testing.T) {
+	expectNoProblems(t, checkSource(t, resErrors+`
+		fn resDouble(s: str) -> result{i32, ResParseError} {
+			let n := resParse(s)?;
+			ret n * 2;
+		}
+		// any error can be propagated from a function that returns the error interface
+		fn resAny(s: str) -> str!error {
+			let n: i32 = resDouble(s)? + resParse(s)?;
+			ret "{n}";
+		}
+		type ResBox struct {
+			v: i32,
+		};
+		fn resBox() -> ResBox!error {
+			ret @ResBox{v: 1};
+		}
+		// on a result, ?. is the try operator followed by the property
+		fn resValue() -> i32!error {
+			ret resBox()?.v;
+		}
+		let r: i32!ResParseError = resParse("1");
+		let e: result{str, error} = @ResIOError{code: 1};
+		let ok: result{f32, error} = 1.5;
+		// result is only a keyword where a type is expected
+		fn resNamed(result: i32) -> result{i32, error} {
+			ret result;
+		}
+		let result := resNamed(1);
+	`))
+}
+
+func TestResultProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"not a result", "fn resP1() -> i32!error { let a := 1; ret a?; }", "cannot use '?' on a value of type 'i32'"},
+		{"outside a function", "let a := resParse(\"1\")?;", "'?' can only be used inside a function"},
+		{"function without result", "fn resP2() -> i32 { ret resParse(\"1\")?; }", "cannot propagate the error of 'result{i32, ResParseError}'. the enclosing function returns 'i32'"},
+		{"other error type", "fn resP3() -> i32!ResIOError { ret resParse(\"1\")?; }", "cannot propagate error of type 'ResParseError'. the enclosing function returns 'result{i32, ResIOError}'"},
+		{"error type", "type ResP4 struct { a: i32 }; let r: i32!ResP4 = 1;", "the error type of a result must implement 'error', got 'ResP4'"},
+		{"value type", "let r: i32!error = \"x\";", "error declaring variable 'r'. cannot assign value of type 'str' to type 'result{i32, error}'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, resErrors+tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
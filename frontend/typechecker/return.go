@@ -12,14 +12,26 @@ func checkReturnStmt(returnNode ast.ReturnStmt, env *TypeEnvironment) ExprType {
 		errgen.Add(env.filePath, returnNode.StartPos().Line, returnNode.EndPos().Line, returnNode.StartPos().Column, returnNode.EndPos().Column, "return statement outside function").Level(errgen.NORMAL_ERROR)
 	}
 
-	//check if the return type matches the function return type
-	returnType := parseNodeValue(returnNode.Value, env)
-
 	fnReturns := getFunctionReturnValue(env, returnNode)
 
+	//check if the return type matches the function return type
+	// ret; without a value leaves a function that returns nothing
+	var returnType ExprType = NewVoid()
+	if returnNode.Value != nil {
+		returnType = checkValueFor(returnNode.Value, fnReturns, env)
+	}
+
 	err := matchTypes(fnReturns, returnType)
-	if err != nil {
-		errgen.Add(env.filePath, returnNode.StartPos().Line, returnNode.EndPos().Line, returnNode.StartPos().Column, returnNode.EndPos().Column, fmt.Sprintf("cannot return '%s' from this scope. function '%s' expects return type '%s'", tcValueToString(returnType), env.scopeName, tcValueToString(fnReturns))).Level(errgen.NORMAL_ERROR)
+	if err != nil && isUntyped(returnType) && isNumberType(fnReturns) {
+		// a constant that does not fit the return type is reported like one that does not fit a variable
+		errgen.Add(env.filePath, returnNode.Value.StartPos().Line, returnNode.Value.EndPos().Line, returnNode.Value.StartPos().Column, returnNode.Value.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
+	} else if err != nil {
+		// the return may be in a block of the function, like an if, so the name is taken from the function's own scope
+		fnName := env.scopeName
+		if fnEnv, err := env.resolveFunctionEnv(); err == nil {
+			fnName = fnEnv.scopeName
+		}
+		errgen.Add(env.filePath, returnNode.StartPos().Line, returnNode.EndPos().Line, returnNode.StartPos().Column, returnNode.EndPos().Column, fmt.Sprintf("cannot return '%s' from this scope. function '%s' expects return type '%s'", tcValueToString(returnType), fnName, tcValueToString(fnReturns))).Level(errgen.NORMAL_ERROR)
 	}
 
 	return ReturnType{
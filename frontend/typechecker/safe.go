@@ -7,7 +7,9 @@ import (
 )
 
 // checkSafeStmt checks the safety of a given SafeStmt node within the provided type environment.
-// It ensures that the value of the node is of 'maybe' type and validates the safe and unsafe blocks.
+// It ensures that the value of every binding is of 'maybe' type and validates the safe and unsafe blocks.
+// In the safe block each binding has the type of its value. A declared variable is narrowed to it, and a new name is declared with it.
+// When the unsafe block never reaches its end, like when it returns early, the bindings keep that type in the rest of the enclosing block.
 //
 // Parameters:
 // - node: The SafeStmt node to be checked.
@@ -17,38 +19,92 @@ import (
 // - TcValue: A type-checked value indicating the result of the safety check.
 //
 // Errors:
-// - Adds a critical error if the node's value is not of 'maybe' type.
+// - Adds a critical error if the value of a binding is not of 'maybe' type.
 // - Adds normal errors if there are issues within the safe or unsafe blocks.
 func checkSafeStmt(node ast.SafeStmt, env *TypeEnvironment) ExprType {
 
-	maybeVar := parseNodeValue(node.Value, env)
-
-	if maybeVar.DType() != MAYBE_TYPE {
-		errgen.Add(env.filePath, node.Value.Start.Line, node.Value.End.Line, node.Value.Start.Column, node.Value.End.Column, "safe-otherwise can only be used with 'maybe' types").Level(errgen.CRITICAL_ERROR)
+	values := make([]ExprType, len(node.Bindings))
+	for i, binding := range node.Bindings {
+		values[i] = checkSafeBinding(binding, env)
 	}
 
 	// check the safe block where the maybe type is the type of the defined type
-	err := checkSafeBlock(env, node.Value.Name, node.SafeBlock, maybeVar.(Maybe))
+	err := checkSafeBlock(env, node.Bindings, values, node.SafeBlock)
 	if err != nil {
 		errgen.Add(env.filePath, node.SafeBlock.StartPos().Line, node.SafeBlock.EndPos().Line, node.SafeBlock.StartPos().Column, node.SafeBlock.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
 	}
-	err = checkOtherwiseBlock(env, node.Value.Name, node.UnsafeBlock)
-	if err != nil {
-		errgen.Add(env.filePath, node.UnsafeBlock.StartPos().Line, node.UnsafeBlock.EndPos().Line, node.UnsafeBlock.StartPos().Column, node.UnsafeBlock.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
+
+	if node.UnsafeBlock == nil {
+		return NewVoid()
+	}
+
+	checkOtherwiseBlock(env, node.Bindings, *node.UnsafeBlock)
+
+	// the rest of the enclosing block is only reached when every value is not null
+	if blockExits(*node.UnsafeBlock) {
+		err := bindSafeValues(env, node.Bindings, values, "after safe block")
+		if err != nil {
+			errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+		}
 	}
 
 	return NewVoid()
 }
 
-func checkSafeBlock(env *TypeEnvironment, name string, block ast.BlockStmt, value Maybe) error {
+// checkSafeBinding returns the type of the value a binding has when it is not null. An index into a map
+// is a maybe here, which is null when the map has no such key.
+func checkSafeBinding(binding ast.SafeBinding, env *TypeEnvironment) ExprType {
+
+	var value ast.Node = binding.Identifier
+	if binding.Value != nil {
+		value = binding.Value
+	}
+
+	var maybeVar ExprType
+	if indexable, ok := value.(ast.Indexable); ok {
+		indexedValue, isMap := evaluateIndexedValue(indexable, env)
+		maybeVar = indexedValue
+		if isMap {
+			maybeVar = optional(indexedValue)
+		}
+	} else {
+		maybeVar = parseNodeValue(value, env)
+	}
+
+	maybe, ok := unwrapType(maybeVar).(Maybe)
+	if !ok {
+		errgen.Add(env.filePath, value.StartPos().Line, value.EndPos().Line, value.StartPos().Column, value.EndPos().Column, fmt.Sprintf("safe-otherwise can only be used with 'maybe' types, got '%s'", tcValueToString(maybeVar))).Level(errgen.CRITICAL_ERROR)
+	}
+
+	return maybe.MaybeType
+}
+
+// bindSafeValues gives each binding the type of its value in the scope: a declared variable is narrowed, and a new name is declared.
+// where tells which part of the safe statement the scope is, for the error.
+func bindSafeValues(scope *TypeEnvironment, bindings []ast.SafeBinding, values []ExprType, where string) error {
+	for i, binding := range bindings {
+		name := binding.Identifier.Name
+		if binding.Value == nil {
+			scope.narrow(name, values[i])
+			continue
+		}
+		err := scope.declareVar(name, values[i], false, false)
+		if err != nil {
+			return fmt.Errorf("error declaring variable '%s' %s. "+err.Error(), name, where)
+		}
+	}
+	return nil
+}
+
+func checkSafeBlock(env *TypeEnvironment, bindings []ast.SafeBinding, values []ExprType, block ast.BlockStmt) error {
 
 	//new scope for the safe block
 	safeScope := NewTypeENV(env, SAFE_SCOPE, "safe block", env.filePath)
 
-	//declare the variable in the safe block
-	err := safeScope.declareVar(name, value.MaybeType, false, false)
+	//declare the variables in the safe block
+	err := bindSafeValues(safeScope, bindings, values, "in safe block")
 	if err != nil {
-		return fmt.Errorf("error declaring variable '%s' in safe block. "+err.Error(), name)
+		return err
 	}
 
 	//check the block
@@ -59,21 +115,51 @@ func checkSafeBlock(env *TypeEnvironment, name string, block ast.BlockStmt, valu
 	return nil
 }
 
-func checkOtherwiseBlock(env *TypeEnvironment, name string, block ast.BlockStmt) error {
+func checkOtherwiseBlock(env *TypeEnvironment, bindings []ast.SafeBinding, block ast.BlockStmt) {
 
 	//new scope for the unsafe block
 	unsafeScope := NewTypeENV(env, OTHERWISE_SCOPE, "unsafe block", env.filePath)
 
-	//declare the variable in the unsafe block
-	err := unsafeScope.declareVar(name, NewNull(), false, false)
-	if err != nil {
-		return fmt.Errorf("error declaring variable '%s' in unsafe block. "+err.Error(), name)
+	// a single declared variable is known to be null here. With more, any of them may be the null one
+	if len(bindings) == 1 && bindings[0].Value == nil {
+		unsafeScope.narrow(bindings[0].Identifier.Name, NewNull())
 	}
 
 	//check the block
 	for _, stmt := range block.Contents {
 		CheckAST(stmt, unsafeScope)
 	}
+}
+
+// blockExits reports whether a block never reaches its end: on every path it returns, or leaves or continues a loop
+func blockExits(block ast.BlockStmt) bool {
+	for _, stmt := range block.Contents {
+		switch t := stmt.(type) {
+		case ast.ReturnStmt, ast.BreakStmt, ast.ContinueStmt:
+			return true
+		case ast.BlockStmt:
+			if blockExits(t) {
+				return true
+			}
+		case ast.IfStmt:
+			if ifExits(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	return nil
+// ifExits reports whether every branch of an if statement exits, which needs an else branch
+func ifExits(node ast.IfStmt) bool {
+	if !blockExits(node.Block) {
+		return false
+	}
+	switch t := node.AlternateBlock.(type) {
+	case ast.IfStmt:
+		return ifExits(t)
+	case ast.BlockStmt:
+		return blockExits(t)
+	}
+	return false
 }
@@ -11,7 +11,13 @@ func checkStructLiteral(structLit ast.StructLiteral, env *TypeEnvironment) ExprT
 
 	sName := structLit.Identifier
 
-	Type, err := getTypeDefinition(sName.Name) // need to get the most deep type
+	var Type ExprType
+	var err error
+	if structLit.Package != nil {
+		Type, err = env.getPackageType(structLit.Package.Name, sName.Name)
+	} else {
+		Type, err = env.getTypeDefinition(sName.Name) // need to get the most deep type
+	}
 	if err != nil {
 		errgen.Add(env.filePath, sName.StartPos().Line, sName.EndPos().Line, sName.StartPos().Column, sName.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
 	}
@@ -22,24 +28,72 @@ func checkStructLiteral(structLit ast.StructLiteral, env *TypeEnvironment) ExprT
 		errgen.Add(env.filePath, sName.StartPos().Line, sName.EndPos().Line, sName.StartPos().Column, sName.EndPos().Column, fmt.Sprintf("'%s' is not a struct", sName.Name)).Level(errgen.CRITICAL_ERROR)
 	}
 
+	values := make([]ExprType, len(structLit.Properties))
+	for i, structProp := range structLit.Properties {
+		var propType ExprType
+		if property, ok := structType.StructScope.variables[structProp.Prop.Name].(StructProperty); ok {
+			propType = property.Type
+		}
+		values[i] = checkValueFor(structProp.Value, propType, env)
+	}
+
+	if len(structType.TypeParams) > 0 || len(structLit.TypeArgs) > 0 {
+		structType = instantiateStructLiteral(structType, structLit, values, env)
+	}
+
 	// now we match the defined props with the provided props
-	checkPropsType(structType, structLit, env)
+	checkPropsType(structType, structLit, values, env)
 	// check if any required property is missing
 	missingProps := checkMissingProps(structType, structLit)
 	// if there are missing properties, we compose the error
 	composeErrors(structLit, missingProps, env)
 
-	structValue := Struct{
-		DataType:    STRUCT_TYPE,
-		StructName:  tcValueToString(Type),
-		StructScope: structType.StructScope,
-	}
-
 	return UserDefined{
 		DataType: USER_DEFINED_TYPE,
 		TypeName: sName.Name,
-		TypeDef:  structValue,
+		TypeDef:  structType,
+	}
+}
+
+// instantiateStructLiteral returns the instance of a generic struct a literal builds. The type arguments are
+// the ones written after the name, or else they are inferred from the values given to the properties.
+func instantiateStructLiteral(structType Struct, structLit ast.StructLiteral, values []ExprType, env *TypeEnvironment) Struct {
+
+	sName := structLit.Identifier
+
+	if len(structLit.TypeArgs) > 0 {
+		args := make([]ExprType, len(structLit.TypeArgs))
+		for i, arg := range structLit.TypeArgs {
+			args[i] = evaluateTypeName(arg, env)
+		}
+		node := ast.UserDefinedType{AliasName: sName.Name, TypeArgs: structLit.TypeArgs, Location: sName.Location}
+		if instance, ok := instantiateType(structType, args, node, env).(Struct); ok {
+			return instance
+		}
+		return structType
+	}
+
+	expected := make([]ExprType, len(structLit.Properties))
+	for i, structProp := range structLit.Properties {
+		if property, ok := structType.StructScope.variables[structProp.Prop.Name].(StructProperty); ok {
+			expected[i] = property.Type
+		}
+	}
+
+	args := inferTypeArgs(structType.TypeParams, expected, values)
+
+	for i, arg := range args {
+		if arg == nil {
+			errgen.Add(env.filePath, sName.Start.Line, sName.End.Line, sName.Start.Column, sName.End.Column, fmt.Sprintf("cannot infer type parameter '%s' of '%s'", structType.TypeParams[i].Name, sName.Name)).Hint(fmt.Sprintf("write the type arguments, like @%s<%s>{...}", sName.Name, typeParamNames(structType.TypeParams))).Level(errgen.NORMAL_ERROR)
+			return structType
+		}
+	}
+
+	for _, err := range checkConstraints(structType.TypeParams, args) {
+		errgen.Add(env.filePath, sName.Start.Line, sName.End.Line, sName.Start.Column, sName.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
 	}
+
+	return instance(structType, args).(Struct)
 }
 
 func composeErrors(structLit ast.StructLiteral, missingProps []string, env *TypeEnvironment) {
@@ -76,17 +130,24 @@ func checkMissingProps(structType Struct, structLit ast.StructLiteral) []string
 	return missingProps
 }
 
-func checkPropsType(structType Struct, structLit ast.StructLiteral, env *TypeEnvironment) {
-	for _, structProp := range structLit.Properties {
+func checkPropsType(structType Struct, structLit ast.StructLiteral, values []ExprType, env *TypeEnvironment) {
+	scope := instanceScope(structType)
+	for i, structProp := range structLit.Properties {
 		//check if the property is defined
-		if _, ok := structType.StructScope.variables[structProp.Prop.Name]; !ok {
+		if _, ok := scope.variables[structProp.Prop.Name]; !ok {
 			errgen.Add(env.filePath, structProp.Prop.Start.Line, structProp.Prop.End.Line, structProp.Prop.Start.Column, structProp.Prop.End.Column, fmt.Sprintf("property '%s' is not defined on struct '%s'", structProp.Prop.Name, structLit.Identifier.Name)).Level(errgen.CRITICAL_ERROR)
 		}
 
 		//check if the property type matches the defined type
-		providedType := parseNodeValue(structProp.Value, env)
+		providedType := values[i]
 
-		expectedType := structType.StructScope.variables[structProp.Prop.Name].(StructProperty).Type
+		property := scope.variables[structProp.Prop.Name].(StructProperty)
+		expectedType := property.Type
+
+		// only the struct's own package can set the properties that are not pub
+		if !property.IsPublic && scope.packageScope() != env.packageScope() {
+			errgen.Add(env.filePath, structProp.Prop.Start.Line, structProp.Prop.End.Line, structProp.Prop.Start.Column, structProp.Prop.End.Column, fmt.Sprintf("cannot set property '%s' of '%s' from outside of its package", structProp.Prop.Name, structLit.Identifier.Name)).Hint(fmt.Sprintf("'%s' is declared at %s. mark it pub to set it from other packages", structProp.Prop.Name, property.Declared)).Level(errgen.NORMAL_ERROR)
+		}
 
 		err := matchTypes(expectedType, providedType)
 		if err != nil {
@@ -99,9 +160,23 @@ func checkPropertyAccess(expr ast.StructPropertyAccessExpr, env *TypeEnvironment
 
 	fmt.Printf("Property Access: %s\n", expr.Property.Name)
 
-	object := parseNodeValue(expr.Object, env)
+	// Shape.Circle names a variant of the enum Shape, not a property of a value
+	if typ, ok := namedType(expr.Object, env); ok {
+		if enum, ok := typ.(Enum); ok {
+			return checkEnumVariantAccess(enum, expr.Property, env)
+		}
+	}
+
+	// sys.print names a member of the imported package sys
+	if pkg, ok := packageOf(expr.Object, env); ok {
+		return checkPackageMember(pkg, expr.Property, env)
+	}
+
+	return checkMemberOf(parseNodeValue(expr.Object, env), expr.Property, env)
+}
 
-	prop := expr.Property
+// checkMemberOf checks the use of a property or a method of a value of the given type
+func checkMemberOf(object ExprType, prop ast.IdentifierExpr, env *TypeEnvironment) ExprType {
 
 	objName := tcValueToString(object)
 
@@ -109,8 +184,12 @@ func checkPropertyAccess(expr ast.StructPropertyAccessExpr, env *TypeEnvironment
 
 	//get the struct's environment
 	switch t := object.(type) {
+	case Unknown:
+		return t
 	case Struct:
-		structEnv = t.StructScope
+		structEnv = instanceScope(t)
+	case Enum:
+		structEnv = t.EnumScope
 	case Interface:
 		//prop must be a method
 		for _, method := range t.Methods {
@@ -119,30 +198,28 @@ func checkPropertyAccess(expr ast.StructPropertyAccessExpr, env *TypeEnvironment
 			}
 		}
 		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("interface '%s' does not have a method '%s'", t.InterfaceName, prop.Name)).Level(errgen.CRITICAL_ERROR)
+	case TypeParam:
+		// a value of a type parameter only has the methods of its constraint
+		if constraint, ok := t.Constraint.(Interface); ok {
+			for _, method := range constraint.Methods {
+				if method.Name == prop.Name {
+					return method.Method
+				}
+			}
+		}
+		errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("'%s' does not exist on type parameter '%s'", prop.Name, t.Name)).Hint("constrain the type parameter with an interface that has it, like <T: Interface>").Level(errgen.CRITICAL_ERROR)
 	}
 
-	propType := ""
-
 	// Check if the property exists on the struct
 	if property, ok := structEnv.variables[prop.Name]; ok {
-		isPrivate := false
-		switch t := property.(type) {
-		case StructMethod:
-			propType = "method"
-			isPrivate = t.IsPrivate
-		case StructProperty:
-			propType = "property"
-			isPrivate = t.IsPrivate
+		switch property.(type) {
+		case StructMethod, StructProperty:
 		default:
-			errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("'%s' is not a %s", prop.Name, propType)).Level(errgen.CRITICAL_ERROR)
+			errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("'%s' is not a property or a method of '%s'", prop.Name, objName)).Level(errgen.CRITICAL_ERROR)
 		}
 
-		if isPrivate {
-			//check the scope we are in
-			if !env.isInStructScope() {
-				errgen.Add(env.filePath, prop.Start.Line, prop.End.Line, prop.Start.Column, prop.End.Column, fmt.Sprintf("cannot access private property '%s' from outside of the struct's scope", prop.Name)).Level(errgen.NORMAL_ERROR)
-			}
-		}
+		checkMemberAccess(prop, property, structEnv, objName, env)
+
 		return property
 	}
 
@@ -151,7 +228,7 @@ func checkPropertyAccess(expr ast.StructPropertyAccessExpr, env *TypeEnvironment
 	return NewVoid()
 }
 
-func checkStructTypeDecl(name string, structType ast.StructType, env *TypeEnvironment) Struct {
+func checkStructTypeDecl(name string, structType ast.StructType, typeParams []TypeParam, env *TypeEnvironment) Struct {
 
 	structEnv := NewTypeENV(env, STRUCT_SCOPE, name, env.filePath)
 
@@ -159,7 +236,9 @@ func checkStructTypeDecl(name string, structType ast.StructType, env *TypeEnviro
 		propType := evaluateTypeName(propval.PropType, env)
 		property := StructProperty{
 			IsPrivate: propval.IsPrivate,
+			IsPublic:  propval.IsPublic,
 			Type:      propType,
+			Declared:  Declaration{FilePath: env.filePath, Position: propval.Prop.Start},
 		}
 		//declare the property on the struct environment
 		err := structEnv.declareVar(propval.Prop.Name, property, false, false)
@@ -172,10 +251,20 @@ func checkStructTypeDecl(name string, structType ast.StructType, env *TypeEnviro
 		DataType:    STRUCT_TYPE,
 		StructName:  name,
 		StructScope: *structEnv,
+		TypeParams:  typeParams,
+	}
+
+	// in the methods of a generic struct, 'this' is the instance for the struct's own type parameters
+	this := structTypeValue
+	if len(typeParams) > 0 {
+		this.TypeArgs = make([]ExprType, len(typeParams))
+		for i, param := range typeParams {
+			this.TypeArgs[i] = param
+		}
 	}
 
 	//declare 'this' variable to be used in the struct's methods
-	err := structEnv.declareVar("this", structTypeValue, true, false)
+	err := structEnv.declareVar("this", this, true, false)
 	if err != nil {
 		errgen.Add(env.filePath, structType.StartPos().Line, structType.EndPos().Line, structType.StartPos().Column, structType.EndPos().Column, err.Error()).Level(errgen.CRITICAL_ERROR)
 	}
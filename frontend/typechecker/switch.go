@@ -0,0 +1,70 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/lexer"
+)
+
+// checkSwitchStmt checks every case value of a switch statement against the type of the discriminant.
+// Constant values that appear in more than one case are reported, and each arm is checked in its own scope.
+func checkSwitchStmt(node ast.SwitchStmt, env *TypeEnvironment) ExprType {
+
+	discriminant := defaultTypeAt(node.Discriminant, parseNodeValue(node.Discriminant, env), env)
+
+	// constant case values seen so far, with where they first appeared
+	seen := map[string]lexer.Position{}
+
+	for _, switchCase := range node.Cases {
+		for _, value := range switchCase.Values {
+			valueType := parseNodeValue(value, env)
+
+			if err := matchTypes(discriminant, valueType); err != nil {
+				errgen.Add(env.filePath, value.StartPos().Line, value.EndPos().Line, value.StartPos().Column, value.EndPos().Column, fmt.Sprintf("invalid case value. %s", err.Error())).Level(errgen.NORMAL_ERROR)
+				continue
+			}
+
+			key, ok := caseConstant(value, valueType)
+			if !ok {
+				continue
+			}
+
+			if previous, exists := seen[key]; exists {
+				errgen.Add(env.filePath, value.StartPos().Line, value.EndPos().Line, value.StartPos().Column, value.EndPos().Column, fmt.Sprintf("duplicate case %s in switch", key)).Hint(fmt.Sprintf("previous case is at line %d", previous.Line)).Level(errgen.NORMAL_ERROR)
+				continue
+			}
+			seen[key] = value.StartPos()
+		}
+
+		checkSwitchArm(switchCase.Block, "switch case", env)
+	}
+
+	if node.Default != nil {
+		checkSwitchArm(*node.Default, "switch default", env)
+	}
+
+	return NewVoid()
+}
+
+func checkSwitchArm(block ast.BlockStmt, scopeName string, env *TypeEnvironment) {
+	armScope := NewTypeENV(env, CONDITIONAL_SCOPE, scopeName, env.filePath)
+	for _, stmt := range block.Contents {
+		CheckAST(stmt, armScope)
+	}
+}
+
+// caseConstant returns the value of a case that is known while checking, so duplicates can be found.
+// Number constants are compared by value, so 0x10 and 16 are the same case.
+func caseConstant(value ast.Node, valueType ExprType) (string, bool) {
+	if isUntyped(valueType) {
+		return constantString(valueType), true
+	}
+	switch t := value.(type) {
+	case ast.StringLiteralExpr:
+		return fmt.Sprintf("%q", t.Value), true
+	case ast.ByteLiteralExpr:
+		return fmt.Sprintf("%q", t.Value), true
+	}
+	return "", false
+}
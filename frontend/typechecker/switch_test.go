@@ -0,0 +1,51 @@
+package typechecker
+
+import (
+	"testing"
+)
+
+func TestSwitch(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		let a: u8 = 10;
+		switch a {
+			case 10, 20: {
+				let x := 1;
+			}
+			case 0xFF: {
+				let x := "arms have their own scope";
+			}
+			default: {
+				let x := true;
+			}
+		}
+
+		let name := "walrus";
+		switch name {
+			case "walrus", "seal": {}
+		}
+	`))
+}
+
+func TestSwitchProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+		col     int
+	}{
+		{"wrong type", `let a := 1; switch a { case "one": {} }`, "invalid case value. cannot assign value of type 'str' to type 'i32'", 29},
+		{"overflow", `let a: u8 = 1; switch a { case 256: {} }`, "invalid case value. constant 256 overflows 'u8'", 32},
+		{"duplicate", `let a := 1; switch a { case 16: {} case 2, 0x10: {} }`, "duplicate case 16 in switch", 44},
+		{"duplicate string", `let a := "x"; switch a { case "x", "x": {} }`, `duplicate case "x" in switch`, 36},
+		{"arm scope", `let a := 1; switch a { case 1: { let y := 1; } } y = 2;`, "cannot assign to 'y' was not declared in this scope", 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message || diagnostics[0].ColStart != tt.col {
+				t.Errorf("expected %q at column %d, got %v", tt.message, tt.col, diagnostics)
+			}
+		})
+	}
+}
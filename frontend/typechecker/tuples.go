@@ -0,0 +1,107 @@
+package typechecker
+
+import (
+	"fmt"
+	"walrus/errgen"
+	"walrus/frontend/ast"
+	"walrus/frontend/builtins"
+	"walrus/utils"
+)
+
+func checkTupleLiteral(tuple ast.TupleLiteral, env *TypeEnvironment) ExprType {
+	elements := make([]ExprType, len(tuple.Values))
+	for i, value := range tuple.Values {
+		elements[i] = parseNodeValue(value, env)
+	}
+	return NewTuple(elements)
+}
+
+// checkDestructuringDeclaration declares the variables of a destructuring pattern, like let (q, r) := divmod(7, 2);
+// Each name takes the type of the tuple element, struct property or array element it is bound to. '_' skips a tuple or array element.
+func checkDestructuringDeclaration(varToDecl ast.VarDeclStmtVar, isConst bool, env *TypeEnvironment) {
+
+	pattern := varToDecl.Pattern
+
+	var value ExprType
+
+	if varToDecl.ExplicitType != nil {
+		value = evaluateTypeName(varToDecl.ExplicitType, env)
+		providedValue := parseNodeValue(varToDecl.Value, env)
+		if err := matchTypes(value, providedValue); err != nil {
+			errgen.Add(env.filePath, varToDecl.Value.StartPos().Line, varToDecl.Value.EndPos().Line, varToDecl.Value.StartPos().Column, varToDecl.Value.EndPos().Column, fmt.Sprintf("error destructuring value. %s", err.Error())).Level(errgen.NORMAL_ERROR)
+		}
+	} else {
+		value = defaultTypeAt(varToDecl.Value, parseNodeValue(varToDecl.Value, env), env)
+	}
+
+	types, err := destructuredTypes(pattern, varToDecl.Value, value, env)
+	if err != nil {
+		errgen.Add(env.filePath, pattern.Start.Line, pattern.End.Line, pattern.Start.Column, pattern.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
+		return
+	}
+
+	for i, name := range pattern.Names {
+		if name.Name == "_" && pattern.Kind != builtins.STRUCT {
+			continue
+		}
+
+		err := env.declareVar(name.Name, types[i], isConst, false)
+		if err != nil {
+			errgen.Add(env.filePath, name.Start.Line, name.End.Line, name.Start.Column, name.End.Column, err.Error()).Level(errgen.CRITICAL_ERROR)
+		}
+
+		utils.GREEN.Print("Declared variable ")
+		utils.RED.Print(name.Name)
+		fmt.Print(" of type ")
+		utils.PURPLE.Println(tcValueToString(types[i]))
+	}
+}
+
+// destructuredTypes returns the type of each name in a destructuring pattern, or an error when the value cannot be taken apart that way.
+func destructuredTypes(pattern *ast.DestructuringPattern, valueNode ast.Node, value ExprType, env *TypeEnvironment) ([]ExprType, error) {
+
+	names := pattern.Names
+	types := make([]ExprType, len(names))
+
+	switch pattern.Kind {
+	case builtins.TUPLE:
+		tuple, ok := unwrapType(value).(Tuple)
+		if !ok {
+			return nil, fmt.Errorf("cannot destructure value of type '%s' as a tuple", tcValueToString(value))
+		}
+		if len(tuple.ElementTypes) != len(names) {
+			return nil, fmt.Errorf("cannot destructure a tuple of %d elements into %d variables", len(tuple.ElementTypes), len(names))
+		}
+		copy(types, tuple.ElementTypes)
+
+	case builtins.STRUCT:
+		structType, ok := unwrapType(value).(Struct)
+		if !ok {
+			return nil, fmt.Errorf("cannot destructure value of type '%s' as a struct", tcValueToString(value))
+		}
+		scope := instanceScope(structType)
+		for i, name := range names {
+			property, ok := scope.variables[name.Name].(StructProperty)
+			if !ok || name.Name == "this" {
+				return nil, fmt.Errorf("'%s' is not a property of '%s'", name.Name, tcValueToString(value))
+			}
+			checkMemberAccess(name, property, scope, tcValueToString(value), env)
+			types[i] = property.Type
+		}
+
+	default:
+		array, ok := unwrapType(value).(Array)
+		if !ok {
+			return nil, fmt.Errorf("cannot destructure value of type '%s' as an array", tcValueToString(value))
+		}
+		// the length of an array is only known when the value is a literal
+		if literal, ok := valueNode.(ast.ArrayLiteral); ok && len(literal.Values) != len(names) {
+			return nil, fmt.Errorf("cannot destructure an array of %d elements into %d variables", len(literal.Values), len(names))
+		}
+		for i := range names {
+			types[i] = array.ArrayType
+		}
+	}
+
+	return types, nil
+}
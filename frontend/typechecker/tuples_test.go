@@ -0,0 +1,54 @@
+package typechecker
+
+import (
+	"testing"
+)
+
+func TestTuples(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type TupPerson struct {
+			name: str,
+			age: i32,
+		};
+		fn tupDivmod(a: i32, b: i32) -> (i32, i32) {
+			ret a / b, a % b;
+		}
+		let (q, r) := tupDivmod(7, 2);
+		let sum: i32 = q + r;
+		let t: (i64, str) = (1, "one");
+		let (n, _) := t;
+		let wide: i64 = n;
+		let (a, b, c) := (1, 2.5, "z");
+		let f: f32 = b;
+		let person := @TupPerson{name: "Ana", age: 30};
+		let {name, age} := person;
+		let s: str = name;
+		let [first, second] := [1, 2];
+		let total: i32 = first + second;
+	`))
+}
+
+func TestTupleProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"arity", "let (a, b, c) := (1, 2);", "cannot destructure a tuple of 2 elements into 3 variables"},
+		{"not a tuple", "let (a, b) := 1;", "cannot destructure value of type 'i32' as a tuple"},
+		{"element types", "let t: (i32, str) = (1, 2);", "error declaring variable 't'. cannot assign value of type '(untyped int, untyped int)' to type '(i32, str)'"},
+		{"return arity", "fn tupP1() -> (i32, i32) { ret 1, 2, 3; }", "cannot return '(untyped int, untyped int, untyped int)' from this scope. function 'tupP1' expects return type '(i32, i32)'"},
+		{"unknown property", "type TupP2 struct { a: i32 }; let p := @TupP2{a: 1}; let {b} := p;", "'b' is not a property of 'TupP2'"},
+		{"private property", "type TupP3 struct { priv a: i32 }; let p := @TupP3{a: 1}; let {a} := p;", "cannot access private property 'a' of 'TupP3' from outside of its methods"},
+		{"array literal length", "let [a, b] := [1, 2, 3];", "cannot destructure an array of 3 elements into 2 variables"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkSource(t, tt.src)
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.message {
+				t.Errorf("expected %q, got %v", tt.message, diagnostics)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 func EvaluateProgram(program ast.ProgramStmt, env *TypeEnvironment) ExprType {
 	utils.PURPLE.Println("### Running type checker ###")
+	env.declareFunctionAssigned(program.Contents...)
 	for _, item := range program.Contents {
 		CheckAST(item, env)
 	}
@@ -35,8 +36,21 @@ func CheckAST(node ast.Node, env *TypeEnvironment) ExprType {
 		return checkIfStmt(t, env)
 	case ast.ForStmt:
 		return checkForStmt(t, env)
+	case ast.ForEachStmt:
+		return checkForEachStmt(t, env)
 	case ast.SafeStmt:
 		return checkSafeStmt(t, env)
+	case ast.SwitchStmt:
+		return checkSwitchStmt(t, env)
+	case ast.BreakStmt:
+		return checkLoopJump("break", t.Label, t, env)
+	case ast.ContinueStmt:
+		return checkLoopJump("continue", t.Label, t, env)
+	case ast.PackageStmt:
+		// the loader puts the files of a package together and checks that they agree on its name
+		return NewVoid()
+	case ast.ImportStmt:
+		return checkImportStmt(t, env)
 	default:
 		return parseNodeValue(node, env)
 	}
@@ -51,21 +65,39 @@ func parseNodeValue(node ast.Node, env *TypeEnvironment) ExprType {
 	case ast.IdentifierExpr:
 		return checkIdentifier(t, env) // value
 	case ast.IntegerLiteralExpr:
-		return NewInt(t.BitSize, t.IsSigned) // value
+		return checkIntegerLiteral(t, env) // value
 	case ast.FloatLiteralExpr:
-		return NewFloat(t.BitSize) // value
+		return checkFloatLiteral(t, env) // value
 	case ast.StringLiteralExpr:
 		return NewStr() // value
+	case ast.InterpolatedStringExpr:
+		return checkInterpolatedString(t, env) // value
 	case ast.ByteLiteralExpr:
 		return NewInt(8, false) // value
 	case ast.BinaryExpr:
 		return checkBinaryExpr(t, env) // value
+	case ast.LogicalExpr:
+		return checkLogicalExpr(t, env) // value
+	case ast.RangeExpr:
+		return checkRangeExpr(t, env) // value
+	case ast.MatchExpr:
+		return checkMatchExpr(t, env) // value
+	case ast.TryExpr:
+		return checkTryExpr(t, env) // value
+	case ast.NullCoalescingExpr:
+		return checkNullCoalescingExpr(t, env) // value
+	case ast.OptionalPropertyAccessExpr:
+		return checkOptionalPropertyAccess(t, env) // value
+	case ast.UnwrapExpr:
+		return checkUnwrapExpr(t, env) // value
 	case ast.UnaryExpr:
 		return checkUnaryExpr(t, env) // value
 	case ast.IncrementalInterface:
 		return checkIncrementalExpr(t, env) // value
 	case ast.ArrayLiteral:
-		return evaluateArrayExpr(t, env) // value
+		return evaluateArrayExpr(t, nil, env) // value
+	case ast.TupleLiteral:
+		return checkTupleLiteral(t, env) // value
 	case ast.Indexable:
 		return evaluateIndexableAccess(t, env) // value
 	case ast.StructLiteral:
@@ -0,0 +1,99 @@
+package typechecker
+
+import (
+	"testing"
+	"walrus/errgen"
+	"walrus/frontend/parser"
+)
+
+// builtinEnv holds the builtin values. ProgramEnv can only run once, so every test checks its
+// program in a child scope of the same builtin environment.
+var builtinEnv = ProgramEnv("builtins")
+
+// checkSource parses and typechecks src and returns the problems found.
+// Each source is checked in a scope of its own, so tests can declare the same variables and types.
+func checkSource(t *testing.T, src string) []errgen.Diagnostic {
+	t.Helper()
+	diagnostics, _ := checkSourceEnv(t, src)
+	return diagnostics
+}
+
+// checkSourceEnv is checkSource that also returns the scope src was checked in
+func checkSourceEnv(t *testing.T, src string) ([]errgen.Diagnostic, *TypeEnvironment) {
+	t.Helper()
+
+	filePath := t.Name() + ".wal"
+
+	_, tree, diagnostics := parser.ParseSource(filePath, []byte(src))
+	if len(diagnostics) > 0 {
+		t.Fatalf("unexpected syntax errors: %v", diagnostics)
+	}
+
+	env := NewTypeENV(builtinEnv, GLOBAL_SCOPE, "global", filePath)
+
+	return errgen.Collect(func() {
+		CheckAST(tree, env)
+	}), env
+}
+
+func expectNoProblems(t *testing.T, diagnostics []errgen.Diagnostic) {
+	t.Helper()
+	if len(diagnostics) > 0 {
+		t.Errorf("expected no problems, got %v", diagnostics)
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type InterpUser struct {
+			name: str,
+			age: i32,
+		};
+		let user := @InterpUser{ name: "John", age: 10 };
+		let ok := true;
+		let msg: str = "Hello {user.name}, you are {user.age} years old {ok} {1.5} {"nested {user.age}"}";
+	`))
+}
+
+func TestInterpolatedStringer(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		type InterpPoint struct {
+			x: i32,
+			y: i32,
+		};
+		impl InterpPoint {
+			fn toString() -> str {
+				ret "({this.x}, {this.y})";
+			}
+		}
+		let p := @InterpPoint{ x: 1, y: 2 };
+		let msg := "point {p}";
+	`))
+}
+
+func TestInterpolatedStringInvalidValue(t *testing.T) {
+	diagnostics := checkSource(t, `
+		type InterpBox struct {
+			size: i32,
+		};
+		let box := @InterpBox{ size: 1 };
+		let msg := "box {box}";
+	`)
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 problem, got %v", diagnostics)
+	}
+
+	if diagnostics[0].Message != "cannot embed value of type 'InterpBox' in a string" || diagnostics[0].LineStart != 6 {
+		t.Errorf("unexpected problem %v", diagnostics[0])
+	}
+}
+
+func TestSuffixedLiterals(t *testing.T) {
+	expectNoProblems(t, checkSource(t, `
+		let a: u8 = 255u8;
+		let b: f64 = 1.5e3f64;
+		let c: i64 = 0xFF_FFi64;
+		let d: u8 = 'd';
+	`))
+}
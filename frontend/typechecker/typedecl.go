@@ -20,11 +20,26 @@ func checkTypeDeclaration(node ast.TypeDeclStmt, env *TypeEnvironment) ExprType
 
 	var val ExprType
 
+	// the type parameters of a generic type are declared in a scope of their own, around the members
+	declEnv := env
+	var typeParams []TypeParam
+	if len(node.TypeParams) > 0 {
+		declEnv = NewTypeENV(env, env.scopeType, node.UDTypeName.Name, env.filePath)
+		typeParams = declareTypeParams(node.TypeParams, declEnv)
+		switch typeName.(type) {
+		case ast.StructType, ast.InterfaceType:
+		default:
+			errgen.Add(env.filePath, node.UDTypeName.Start.Line, node.UDTypeName.End.Line, node.UDTypeName.Start.Column, node.UDTypeName.End.Column, "only structs and interfaces can have type parameters").Level(errgen.NORMAL_ERROR)
+		}
+	}
+
 	switch t := typeName.(type) {
 	case ast.StructType:
-		val = checkStructTypeDecl(node.UDTypeName.Name, t, env)
+		val = checkStructTypeDecl(node.UDTypeName.Name, t, typeParams, declEnv)
 	case ast.InterfaceType:
-		val = checkInterfaceTypeDecl(node.UDTypeName.Name, t, env)
+		val = checkInterfaceTypeDecl(node.UDTypeName.Name, t, typeParams, declEnv)
+	case ast.EnumType:
+		val = checkEnumTypeDecl(node.UDTypeName.Name, t, env)
 	default:
 		val = evaluateTypeName(typeName, env)
 	}
@@ -35,11 +50,15 @@ func checkTypeDeclaration(node ast.TypeDeclStmt, env *TypeEnvironment) ExprType
 		TypeDef:  val,
 	}
 
-	err := declareType(node.UDTypeName.Name, typeVal)
+	err := env.declareType(node.UDTypeName.Name, typeVal)
 	if err != nil {
 		errgen.Add(env.filePath, node.Start.Line, node.End.Line, node.Start.Column, node.End.Column, err.Error()).Level(errgen.NORMAL_ERROR)
 	}
 
+	if node.IsPublic {
+		declarePublic(node.UDTypeName.Name, true, node.UDTypeName, env)
+	}
+
 	utils.GREEN.Print("Declared Type ")
 	utils.PURPLE.Println(node.UDTypeName.Name)
 
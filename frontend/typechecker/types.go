@@ -1,7 +1,10 @@
 package typechecker
 
 import (
+	"fmt"
+	"math/big"
 	"walrus/frontend/builtins"
+	"walrus/frontend/lexer"
 )
 
 const (
@@ -23,12 +26,25 @@ const (
 	FUNCTION_TYPE     builtins.TC_TYPE = builtins.FUNCTION
 	STRUCT_TYPE       builtins.TC_TYPE = builtins.STRUCT
 	INTERFACE_TYPE    builtins.TC_TYPE = builtins.INTERFACE
+	ENUM_TYPE         builtins.TC_TYPE = builtins.ENUM
 	ARRAY_TYPE        builtins.TC_TYPE = builtins.ARRAY
 	MAP_TYPE          builtins.TC_TYPE = builtins.MAP
 	MAYBE_TYPE        builtins.TC_TYPE = builtins.MAYBE
+	RANGE_TYPE        builtins.TC_TYPE = builtins.RANGE
+	RESULT_TYPE       builtins.TC_TYPE = builtins.RESULT
+	TUPLE_TYPE        builtins.TC_TYPE = builtins.TUPLE
 	USER_DEFINED_TYPE builtins.TC_TYPE = builtins.USER_DEFINED
+	TYPE_PARAM_TYPE   builtins.TC_TYPE = "type parameter"
+	PACKAGE_TYPE      builtins.TC_TYPE = "package"
 	BLOCK_TYPE        builtins.TC_TYPE = "block"
 	RETURN_TYPE       builtins.TC_TYPE = "return"
+
+	// types of number constants that have not been given a type yet
+	UNTYPED_INT_TYPE   builtins.TC_TYPE = "untyped int"
+	UNTYPED_FLOAT_TYPE builtins.TC_TYPE = "untyped float"
+
+	// type of a value whose type is lost to a problem that is already reported
+	UNKNOWN_TYPE builtins.TC_TYPE = "unknown"
 )
 
 type ExprType interface {
@@ -54,6 +70,27 @@ func (t Float) DType() builtins.TC_TYPE {
 	return t.DataType
 }
 
+// UntypedInt is the type of an integer constant that has not been given a type yet, like the literal 10.
+// It takes the type its context expects, as long as Value fits in it.
+type UntypedInt struct {
+	DataType builtins.TC_TYPE
+	Value    *big.Int
+}
+
+func (t UntypedInt) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
+// UntypedFloat is the type of a float constant that has not been given a type yet, like the literal 1.5.
+type UntypedFloat struct {
+	DataType builtins.TC_TYPE
+	Value    *big.Float
+}
+
+func (t UntypedFloat) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
 type Str struct {
 	DataType builtins.TC_TYPE
 }
@@ -78,6 +115,16 @@ func (t Null) DType() builtins.TC_TYPE {
 	return t.DataType
 }
 
+// Unknown is the type of a value whose type is lost to a problem that is already reported, like the element of a value
+// that cannot be iterated. The checks accept it where a type is expected, so the problem is not reported again at every use.
+type Unknown struct {
+	DataType builtins.TC_TYPE
+}
+
+func (t Unknown) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
 type Void struct {
 	DataType builtins.TC_TYPE
 }
@@ -105,6 +152,7 @@ type FnParam struct {
 
 type Fn struct {
 	DataType      builtins.TC_TYPE
+	TypeParams    []TypeParam // the type parameters of a generic function, inferred at each call
 	Params        []FnParam
 	Returns       ExprType
 	FunctionScope TypeEnvironment
@@ -130,8 +178,10 @@ func (t ConditionStmt) DType() builtins.TC_TYPE {
 }
 
 type StructProperty struct {
-	IsPrivate bool
+	IsPrivate bool // only the methods of the owning struct can use it
+	IsPublic  bool // other packages can use it
 	Type      ExprType
+	Declared  Declaration
 }
 
 func (t StructProperty) DType() builtins.TC_TYPE {
@@ -139,7 +189,9 @@ func (t StructProperty) DType() builtins.TC_TYPE {
 }
 
 type StructMethod struct {
-	IsPrivate bool
+	IsPrivate bool // only the methods of the owning type can use it
+	IsPublic  bool // other packages can use it
+	Declared  Declaration
 	Fn
 }
 
@@ -147,16 +199,60 @@ func (t StructMethod) DType() builtins.TC_TYPE {
 	return t.DataType
 }
 
+// Declaration is where a struct property or a method is declared, for the errors about using it
+type Declaration struct {
+	FilePath string
+	Position lexer.Position
+}
+
+func (d Declaration) String() string {
+	return fmt.Sprintf("%s:%d:%d", d.FilePath, d.Position.Line, d.Position.Column)
+}
+
+// Struct is a struct type. A generic struct has TypeParams, and each of its instances, like Pair<i32, str>,
+// has the TypeArgs they are bound to. StructScope is shared by all instances and holds the members
+// in terms of the type parameters, so they are substituted when they are read through instanceScope.
 type Struct struct {
 	DataType    builtins.TC_TYPE
 	StructName  string
 	StructScope TypeEnvironment
+	TypeParams  []TypeParam
+	TypeArgs    []ExprType
 }
 
 func (t Struct) DType() builtins.TC_TYPE {
 	return t.DataType
 }
 
+// EnumVariant is one variant of an enum. Fields holds its payload, in declaration order.
+type EnumVariant struct {
+	Name   string
+	Fields []FnParam
+}
+
+// Enum is a tagged union: a value is exactly one of its variants. Methods from impl blocks live in EnumScope,
+// the same way they live in the scope of a struct.
+type Enum struct {
+	DataType  builtins.TC_TYPE
+	EnumName  string
+	Variants  []EnumVariant
+	EnumScope TypeEnvironment
+}
+
+func (t Enum) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
+// variant returns the variant with the given name
+func (t Enum) variant(name string) (EnumVariant, bool) {
+	for _, v := range t.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return EnumVariant{}, false
+}
+
 type Array struct {
 	DataType  builtins.TC_TYPE
 	ArrayType ExprType
@@ -190,10 +286,14 @@ type InterfaceMethodType struct {
 	Method Fn
 }
 
+// Interface is an interface type. The methods of an instance of a generic interface, like Container<i32>,
+// already have the TypeArgs substituted for the TypeParams.
 type Interface struct {
 	DataType      builtins.TC_TYPE
 	InterfaceName string
 	Methods       []InterfaceMethodType
+	TypeParams    []TypeParam
+	TypeArgs      []ExprType
 }
 
 func (t Interface) DType() builtins.TC_TYPE {
@@ -208,3 +308,62 @@ type Maybe struct {
 func (t Maybe) DType() builtins.TC_TYPE {
 	return t.DataType
 }
+
+// Range is the type of start..end. ElementType is the integer type of the bounds and of each value the range yields.
+// When both bounds are untyped constants, ElementType is untyped and Bounds holds them until the context gives the range a type.
+type Range struct {
+	DataType    builtins.TC_TYPE
+	ElementType ExprType
+	Bounds      []UntypedInt
+}
+
+func (t Range) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
+// Result is result{T, E}, the value of a computation that can fail: either a value of OkType or an error of ErrType.
+// ErrType implements the builtin error interface.
+type Result struct {
+	DataType builtins.TC_TYPE
+	OkType   ExprType
+	ErrType  ExprType
+}
+
+func (t Result) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
+// Tuple is a fixed list of values of any types, like (i32, str). A function returns several values as one tuple.
+type Tuple struct {
+	DataType     builtins.TC_TYPE
+	ElementTypes []ExprType
+}
+
+func (t Tuple) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
+// Package is an imported package. Name is the name it is imported as and Path its import path. Scope is the environment
+// the package's files were checked in. Importers only reach the package's public symbols, through member and typeMember.
+type Package struct {
+	DataType builtins.TC_TYPE
+	Name     string
+	Path     string
+	Scope    *TypeEnvironment
+}
+
+func (t Package) DType() builtins.TC_TYPE {
+	return t.DataType
+}
+
+// TypeParam is a type parameter of a generic function or type, like T in fn first<T>(xs: []T).
+// Inside the generic code it is an opaque type that only supports what its Constraint interface offers.
+type TypeParam struct {
+	DataType   builtins.TC_TYPE
+	Name       string
+	Constraint ExprType
+}
+
+func (t TypeParam) DType() builtins.TC_TYPE {
+	return t.DataType
+}
@@ -2,6 +2,7 @@ package typechecker
 
 import (
 	"fmt"
+	"math/big"
 	"walrus/frontend/builtins"
 )
 
@@ -31,6 +32,14 @@ func NewFloat(bitSize uint8) Float {
 	return Float{DataType: makeNumericType(false, bitSize, false), BitSize: bitSize}
 }
 
+func NewUntypedInt(value *big.Int) UntypedInt {
+	return UntypedInt{DataType: UNTYPED_INT_TYPE, Value: value}
+}
+
+func NewUntypedFloat(value *big.Float) UntypedFloat {
+	return UntypedFloat{DataType: UNTYPED_FLOAT_TYPE, Value: value}
+}
+
 func NewStr() Str {
 	return Str{DataType: STRING_TYPE}
 }
@@ -47,6 +56,10 @@ func NewVoid() Void {
 	return Void{DataType: VOID_TYPE}
 }
 
+func NewUnknown() Unknown {
+	return Unknown{DataType: UNKNOWN_TYPE}
+}
+
 func NewMap(keyType ExprType, valueType ExprType) Map {
 	return Map{DataType: MAP_TYPE, KeyType: keyType, ValueType: valueType}
 }
@@ -54,3 +67,23 @@ func NewMap(keyType ExprType, valueType ExprType) Map {
 func NewMaybe(valueType ExprType) Maybe {
 	return Maybe{DataType: MAYBE_TYPE, MaybeType: valueType}
 }
+
+func NewRange(elementType ExprType) Range {
+	return Range{DataType: RANGE_TYPE, ElementType: elementType}
+}
+
+func NewResult(okType ExprType, errType ExprType) Result {
+	return Result{DataType: RESULT_TYPE, OkType: okType, ErrType: errType}
+}
+
+func NewTuple(elementTypes []ExprType) Tuple {
+	return Tuple{DataType: TUPLE_TYPE, ElementTypes: elementTypes}
+}
+
+func NewTypeParam(name string, constraint ExprType) TypeParam {
+	return TypeParam{DataType: TYPE_PARAM_TYPE, Name: name, Constraint: constraint}
+}
+
+func NewPackage(name string, path string, scope *TypeEnvironment) Package {
+	return Package{DataType: PACKAGE_TYPE, Name: name, Path: path, Scope: scope}
+}
@@ -30,7 +30,7 @@ func checkLValue(node ast.Node, env *TypeEnvironment) error {
 	//if not constant and is IdentifierExpr
 	switch t := node.(type) {
 	case ast.IdentifierExpr:
-		if isTypeDefined(t.Name) {
+		if env.isTypeDefined(t.Name) {
 			return errors.New("type")
 		}
 		//find the declaredEnv where the variable was declared
@@ -46,6 +46,13 @@ func checkLValue(node ast.Node, env *TypeEnvironment) error {
 	case ast.Indexable:
 		return checkLValue(t.Container, env)
 	case ast.StructPropertyAccessExpr:
+		// a variable of an imported package can be assigned, unless it is a constant
+		if pkg, ok := packageOf(t.Object, env); ok {
+			if pkg.Scope.constants[t.Property.Name] {
+				return errors.New("constant")
+			}
+			return nil
+		}
 		return checkLValue(t.Object, env)
 	default:
 		return fmt.Errorf("invalid lvalue")
@@ -53,17 +60,22 @@ func checkLValue(node ast.Node, env *TypeEnvironment) error {
 }
 
 func isNumberType(operand ExprType) bool {
-	switch operand.(type) {
-	case Int, Float:
+	switch unwrapType(operand).(type) {
+	case Int, Float, UntypedInt, UntypedFloat:
 		return true
 	default:
 		return false
 	}
 }
 
+func isUnknown(value ExprType) bool {
+	_, ok := unwrapType(value).(Unknown)
+	return ok
+}
+
 func isIntType(operand ExprType) bool {
-	switch operand.(type) {
-	case Int:
+	switch unwrapType(operand).(type) {
+	case Int, UntypedInt:
 		return true
 	default:
 		return false
@@ -95,6 +107,16 @@ func evaluateTypeName(dtype ast.DataType, env *TypeEnvironment) ExprType {
 		return evalMap(t, env)
 	case ast.MaybeType:
 		return NewMaybe(evaluateTypeName(t.MaybeType, env))
+	case ast.RangeType:
+		return evalRange(t, env)
+	case ast.ResultType:
+		return evalResult(t, env)
+	case ast.TupleType:
+		elements := make([]ExprType, len(t.ElementTypes))
+		for i, element := range t.ElementTypes {
+			elements[i] = evaluateTypeName(element, env)
+		}
+		return NewTuple(elements)
 	case ast.UserDefinedType:
 		return evalUD(t, env)
 	case nil:
@@ -105,20 +127,63 @@ func evaluateTypeName(dtype ast.DataType, env *TypeEnvironment) ExprType {
 }
 
 func evalDefaultType(defaultType ast.DataType, env *TypeEnvironment) ExprType {
-	val, err := getTypeDefinition(string(defaultType.Type())) // need to get the most deep type
+	val, err := env.getTypeDefinition(string(defaultType.Type())) // need to get the most deep type
 	if err != nil || val == nil {
 		errgen.Add(env.filePath, defaultType.StartPos().Line, defaultType.EndPos().Line, defaultType.StartPos().Column, defaultType.EndPos().Column, err.Error()).Level(errgen.CRITICAL_ERROR)
 	}
 	return val
 }
 
+func evalRange(analyzedRange ast.RangeType, env *TypeEnvironment) ExprType {
+	elementType := evaluateTypeName(analyzedRange.ElementType, env)
+	if !isIntType(elementType) {
+		errgen.Add(env.filePath, analyzedRange.StartPos().Line, analyzedRange.EndPos().Line, analyzedRange.StartPos().Column, analyzedRange.EndPos().Column, fmt.Sprintf("range element type must be an integer, got '%s'", tcValueToString(elementType))).Level(errgen.NORMAL_ERROR)
+	}
+	return NewRange(elementType)
+}
+
+func evalResult(analyzedResult ast.ResultType, env *TypeEnvironment) ExprType {
+	okType := evaluateTypeName(analyzedResult.OkType, env)
+	errType := evaluateTypeName(analyzedResult.ErrType, env)
+	if err := matchTypes(errorInterface, errType); err != nil {
+		errNode := analyzedResult.ErrType
+		errgen.Add(env.filePath, errNode.StartPos().Line, errNode.EndPos().Line, errNode.StartPos().Column, errNode.EndPos().Column, fmt.Sprintf("the error type of a result must implement '%s', got '%s'", ERROR_INTERFACE, tcValueToString(errType))).Hint(fmt.Sprintf("an error type has a method fn %s() -> str", ERROR_METHOD)).Level(errgen.NORMAL_ERROR)
+	}
+	return NewResult(okType, errType)
+}
+
 func evalUD(analyzedUD ast.UserDefinedType, env *TypeEnvironment) ExprType {
 	typename := analyzedUD.AliasName
-	val, err := getTypeDefinition(typename) // need to get the most deep type
+
+	// type parameters of the enclosing generic function or type come before the declared types
+	if param, ok := env.resolveTypeParam(typename); ok && analyzedUD.Package == "" {
+		if len(analyzedUD.TypeArgs) > 0 {
+			errgen.Add(env.filePath, analyzedUD.StartPos().Line, analyzedUD.EndPos().Line, analyzedUD.StartPos().Column, analyzedUD.EndPos().Column, fmt.Sprintf("type parameter '%s' does not take type arguments", typename)).Level(errgen.NORMAL_ERROR)
+		}
+		return param
+	}
+
+	var val ExprType
+	var err error
+	if analyzedUD.Package != "" {
+		val, err = env.getPackageType(analyzedUD.Package, typename)
+	} else {
+		val, err = env.getTypeDefinition(typename) // need to get the most deep type
+	}
 	if err != nil || val == nil {
 		errgen.Add(env.filePath, analyzedUD.StartPos().Line, analyzedUD.EndPos().Line, analyzedUD.StartPos().Column, analyzedUD.EndPos().Column, err.Error()).Level(errgen.CRITICAL_ERROR)
 	}
-	return val
+
+	if len(analyzedUD.TypeArgs) == 0 && len(genericParams(val)) == 0 {
+		return val
+	}
+
+	args := make([]ExprType, len(analyzedUD.TypeArgs))
+	for i, arg := range analyzedUD.TypeArgs {
+		args[i] = evaluateTypeName(arg, env)
+	}
+
+	return instantiateType(val, args, analyzedUD, env)
 }
 
 func evalArray(analyzedArray ast.ArrayType, env *TypeEnvironment) ExprType {
@@ -168,7 +233,7 @@ func evalMap(analyzedMap ast.MapType, env *TypeEnvironment) ExprType {
 		return NewMap(keyType, valueType)
 	} else {
 		//find the name in the type definition
-		val, err := getTypeDefinition(analyzedMap.Map.Name) // need to get the most deep type
+		val, err := env.getTypeDefinition(analyzedMap.Map.Name) // need to get the most deep type
 		if err != nil {
 			errgen.Add(env.filePath, analyzedMap.StartPos().Line, analyzedMap.EndPos().Line, analyzedMap.StartPos().Column, analyzedMap.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
 		}
@@ -188,8 +253,53 @@ func matchTypes(expectedType, providedType ExprType) error {
 	unwrappedExpected := unwrapType(expectedType)
 	unwrappedProvided := unwrapType(providedType)
 
+	if isUnknown(unwrappedExpected) || isUnknown(unwrappedProvided) {
+		return nil
+	}
+
+	if isUntyped(unwrappedProvided) {
+		return representable(unwrappedProvided, unwrappedExpected)
+	}
+
+	if provided, ok := unwrappedProvided.(Range); ok && provided.Bounds != nil {
+		if expected, ok := unwrappedExpected.(Range); ok {
+			for _, bound := range provided.Bounds {
+				if err := representable(bound, expected.ElementType); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
 	switch t := unwrappedExpected.(type) {
+	case Tuple:
+		// each element is matched on its own, so untyped constants in a tuple literal take the element types
+		if provided, ok := unwrappedProvided.(Tuple); ok && len(provided.ElementTypes) == len(t.ElementTypes) {
+			for i, element := range t.ElementTypes {
+				if matchTypes(element, provided.ElementTypes[i]) != nil {
+					return fmt.Errorf("cannot assign value of type '%s' to type '%s'", tcValueToString(unwrappedProvided), tcValueToString(unwrappedExpected))
+				}
+			}
+			return nil
+		}
 	case Interface:
+		if t.InterfaceName == COMPARABLE_INTERFACE {
+			if !isOrdered(unwrappedProvided) {
+				return fmt.Errorf("type '%s' is not comparable", tcValueToString(providedType))
+			}
+			return nil
+		}
+		if provided, ok := unwrappedProvided.(Interface); ok && tcValueToString(provided) == tcValueToString(t) {
+			return nil
+		}
+		// inside generic code a value of type T can be used as the interface that constrains T
+		if param, ok := unwrappedProvided.(TypeParam); ok {
+			if param.Constraint == nil || tcValueToString(param.Constraint) != tcValueToString(t) {
+				return fmt.Errorf("cannot use type '%s' as interface '%s'", param.Name, tcValueToString(expectedType))
+			}
+			return nil
+		}
 		errs := checkMethodsImplementations(unwrappedExpected, unwrappedProvided)
 		if len(errs) > 0 {
 			msgs := fmt.Sprintf("cannot use type '%s' as interface '%s'\n", tcValueToString(providedType), tcValueToString(expectedType))
@@ -197,7 +307,12 @@ func matchTypes(expectedType, providedType ExprType) error {
 		}
 		return nil
 	case Maybe:
-		if unwrapType(t.MaybeType).DType() == unwrappedProvided.DType() || unwrappedProvided.DType() == builtins.NULL {
+		if tcValueToString(unwrapType(t.MaybeType)) == tcValueToString(unwrappedProvided) || unwrappedProvided.DType() == builtins.NULL {
+			return nil
+		}
+	case Result:
+		// a result is made from its success value or from its error
+		if _, ok := unwrappedProvided.(Result); !ok && (matchTypes(t.OkType, providedType) == nil || matchTypes(t.ErrType, providedType) == nil) {
 			return nil
 		}
 	}
@@ -217,15 +332,33 @@ func tcValueToString(val ExprType) string {
 	case Array:
 		return fmt.Sprintf("[]%s", tcValueToString(t.ArrayType))
 	case Struct:
+		if len(t.TypeArgs) > 0 {
+			return fmt.Sprintf("%s<%s>", t.StructName, typeArgsString(t.TypeArgs))
+		}
 		return t.StructName
 	case Interface:
+		if len(t.TypeArgs) > 0 {
+			return fmt.Sprintf("%s<%s>", t.InterfaceName, typeArgsString(t.TypeArgs))
+		}
 		return t.InterfaceName
+	case TypeParam:
+		return t.Name
+	case Enum:
+		return t.EnumName
 	case Fn:
 		return functionSignatureString(t)
 	case Map:
 		return fmt.Sprintf("map[%s]%s", tcValueToString(t.KeyType), tcValueToString(t.ValueType))
 	case Maybe:
 		return fmt.Sprintf("maybe{%s}", tcValueToString(t.MaybeType))
+	case Range:
+		return fmt.Sprintf("range{%s}", tcValueToString(t.ElementType))
+	case Result:
+		return fmt.Sprintf("result{%s, %s}", tcValueToString(t.OkType), tcValueToString(t.ErrType))
+	case Tuple:
+		return fmt.Sprintf("(%s)", typeArgsString(t.ElementTypes))
+	case Package:
+		return fmt.Sprintf("package %s", t.Name)
 	case UserDefined:
 		return tcValueToString(unwrapType(t.TypeDef))
 	default:
@@ -268,18 +401,17 @@ func checkMethodsImplementations(expected, provided ExprType) []error {
 		return []error{fmt.Errorf("type must be an interface")}
 	}
 
-	var structType Struct
-	structType, ok = provided.(Struct)
+	scope, typeName, ok := methodScope(provided)
 	if !ok {
-		return []error{fmt.Errorf("type must be a struct")}
+		return []error{fmt.Errorf("type must be a struct or an enum")}
 	}
 
 	// check if all methods are present
 	for _, interfaceMethod := range interfaceType.Methods {
 		// check if method is present in the struct's variables
-		methodVal, ok := structType.StructScope.variables[interfaceMethod.Name]
+		methodVal, ok := scope.variables[interfaceMethod.Name]
 		if !ok {
-			errs = append(errs, fmt.Errorf("missing method '%s' on '%s'", interfaceMethod.Name, structType.StructName))
+			errs = append(errs, fmt.Errorf("missing method '%s' on '%s'", interfaceMethod.Name, typeName))
 			continue
 		}
 
@@ -293,7 +425,13 @@ func checkMethodsImplementations(expected, provided ExprType) []error {
 		}
 
 		// check the return type and parameters
+		if len(interfaceMethod.Method.Params) != len(methodFn.Fn.Params) {
+			errs = append(errs, fmt.Errorf("method '%s' expects %d parameters, but %d found", interfaceMethod.Name, len(interfaceMethod.Method.Params), len(methodFn.Fn.Params)))
+		}
 		for i, param := range interfaceMethod.Method.Params {
+			if i >= len(methodFn.Fn.Params) {
+				break
+			}
 			expectedParam := tcValueToString(param.Type)
 			providedParam := tcValueToString(methodFn.Fn.Params[i].Type)
 			if expectedParam != providedParam {
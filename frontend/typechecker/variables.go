@@ -4,9 +4,26 @@ import (
 	"fmt"
 	"walrus/errgen"
 	"walrus/frontend/ast"
+	"walrus/frontend/builtins"
+	"walrus/frontend/lexer"
 	"walrus/utils"
 )
 
+// compoundOperators maps each compound assignment operator to the binary operator it applies, so a += b is checked like a = a + b
+var compoundOperators = map[builtins.TOKEN_KIND]builtins.TOKEN_KIND{
+	lexer.PLUS_EQUALS_TOKEN:        lexer.PLUS_TOKEN,
+	lexer.MINUS_EQUALS_TOKEN:       lexer.MINUS_TOKEN,
+	lexer.MUL_EQUALS_TOKEN:         lexer.MUL_TOKEN,
+	lexer.DIV_EQUALS_TOKEN:         lexer.DIV_TOKEN,
+	lexer.MOD_EQUALS_TOKEN:         lexer.MOD_TOKEN,
+	lexer.EXP_EQUALS_TOKEN:         lexer.EXP_TOKEN,
+	lexer.BIT_AND_EQUALS_TOKEN:     lexer.BIT_AND_TOKEN,
+	lexer.BIT_OR_EQUALS_TOKEN:      lexer.BIT_OR_TOKEN,
+	lexer.BIT_XOR_EQUALS_TOKEN:     lexer.BIT_XOR_TOKEN,
+	lexer.SHIFT_LEFT_EQUALS_TOKEN:  lexer.SHIFT_LEFT_TOKEN,
+	lexer.SHIFT_RIGHT_EQUALS_TOKEN: lexer.SHIFT_RIGHT_TOKEN,
+}
+
 // checkVariableAssignment checks the assignment of a value to a variable in the given type environment.
 // It verifies if the assignee is assignable and if the types of the assignee and the value to be assigned match.
 // If any errors are encountered during these checks, they are displayed using the error generation utility.
@@ -26,14 +43,46 @@ func checkVariableAssignment(node ast.VarAssignmentExpr, env *TypeEnvironment) E
 		errgen.Add(env.filePath, Assignee.StartPos().Line, Assignee.EndPos().Line, Assignee.StartPos().Column, Assignee.EndPos().Column, fmt.Sprintf("cannot assign to %s", err.Error())).Level(errgen.CRITICAL_ERROR)
 	}
 
-	expectedType := parseNodeValue(Assignee, env)
-	providedType := parseNodeValue(valueToAssign, env)
+	currentType := parseNodeValue(Assignee, env)
+
+	// a narrowed maybe variable can still be assigned any value of its declared type
+	expectedType := currentType
+	identifier, isIdentifier := Assignee.(ast.IdentifierExpr)
+	if isIdentifier {
+		if declaredEnv, err := env.resolveVar(identifier.Name); err == nil {
+			expectedType = unwrapType(declaredEnv.variables[identifier.Name])
+		}
+	}
+
+	providedType := checkValueFor(valueToAssign, expectedType, env)
+
+	if binaryOp, ok := compoundOperators[node.Operator.Kind]; ok {
+		operator := node.Operator
+		operator.Kind = binaryOp
+		operator.Value = string(binaryOp)
+		binaryNode := ast.BinaryExpr{
+			Operator: operator,
+			Left:     Assignee,
+			Right:    valueToAssign,
+			Location: node.Location,
+		}
+		providedType = checkBinaryOperands(binaryNode, currentType, providedType, env)
+	}
 
 	err := matchTypes(expectedType, providedType)
 	if err != nil {
 		errgen.Add(env.filePath, valueToAssign.StartPos().Line, valueToAssign.EndPos().Line, valueToAssign.StartPos().Column, valueToAssign.EndPos().Column, err.Error()).Level(errgen.NORMAL_ERROR)
 	}
 
+	// what was known about the value of the variable no longer holds after it is assigned
+	if isIdentifier {
+		env.widen(identifier.Name)
+	}
+
+	if isUntyped(providedType) {
+		return expectedType
+	}
+
 	return providedType
 }
 
@@ -63,6 +112,11 @@ func checkVariableDeclaration(node ast.VarDeclStmt, env *TypeEnvironment) ExprTy
 
 	for _, varToDecl := range varsToDecl {
 
+		if varToDecl.Pattern != nil {
+			checkDestructuringDeclaration(varToDecl, node.IsConst, env)
+			continue
+		}
+
 		utils.BLUE.Print("Declaring variable ")
 		utils.RED.Println(varToDecl.Identifier.Name)
 
@@ -75,13 +129,14 @@ func checkVariableDeclaration(node ast.VarDeclStmt, env *TypeEnvironment) ExprTy
 			fmt.Print("Explicit type: ")
 			utils.PURPLE.Println(tcValueToString(expectedTypeInterface))
 		} else {
-			expectedTypeInterface = parseNodeValue(varToDecl.Value, env)
+			// without an explicit type, untyped constants take their default type
+			expectedTypeInterface = defaultTypeAt(varToDecl.Value, parseNodeValue(varToDecl.Value, env), env)
 			utils.ORANGE.Print("Auto detected type: ")
 			utils.PURPLE.Println(tcValueToString(expectedTypeInterface))
 		}
 
 		if varToDecl.Value != nil && varToDecl.ExplicitType != nil {
-			providedValue := parseNodeValue(varToDecl.Value, env)
+			providedValue := checkValueFor(varToDecl.Value, expectedTypeInterface, env)
 			err := matchTypes(expectedTypeInterface, providedValue)
 			if err != nil {
 				errgen.Add(env.filePath, varToDecl.Value.StartPos().Line, varToDecl.Value.EndPos().Line, varToDecl.Value.StartPos().Column, varToDecl.Value.EndPos().Column, fmt.Sprintf("error declaring variable '%s'. %s", varToDecl.Identifier.Name, err.Error())).Level(errgen.NORMAL_ERROR)
@@ -105,5 +160,20 @@ func checkVariableDeclaration(node ast.VarDeclStmt, env *TypeEnvironment) ExprTy
 			utils.PURPLE.Println(tcValueToString(expectedTypeInterface))
 		}
 	}
+
+	if node.IsPublic {
+		for _, varToDecl := range varsToDecl {
+			if varToDecl.Pattern == nil {
+				declarePublic(varToDecl.Identifier.Name, false, varToDecl.Identifier, env)
+				continue
+			}
+			for _, name := range varToDecl.Pattern.Names {
+				if name.Name != "_" {
+					declarePublic(name.Name, false, name, env)
+				}
+			}
+		}
+	}
+
 	return NewVoid()
 }
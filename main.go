@@ -3,32 +3,45 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"walrus/errgen"
+	"walrus/frontend/ast"
 	"walrus/frontend/helpers"
-	"walrus/frontend/lexer"
-	"walrus/frontend/parser"
+	"walrus/frontend/loader"
 	"walrus/frontend/typechecker"
 )
 
 func main() {
 
-	fileName := "userTypes"
-	folder := "code"
-	filePath := fmt.Sprintf("%s/%s.wal", folder, fileName)
-	tokens := lexer.Tokenize(filePath, true)
-	tree := parser.NewParser(filePath, tokens).Parse(false)
+	// the entry file or package folder can be given as the first argument
+	entry := "code/userTypes.wal"
+	if len(os.Args) > 1 {
+		entry = os.Args[1]
+	}
 
-	//write the tree to a file named 'expressions.json' in 'code/ast' folder
-	err := helpers.Serialize(&tree, folder, fileName)
+	program, err := loader.Load(entry)
 	if err != nil {
-		fmt.Println(errgen.TreeFormatString("compilation halted", "Error serializing AST", err.Error()))
+		fmt.Println(errgen.TreeFormatString("compilation halted", "Error loading program", err.Error()))
 		os.Exit(-1)
 	}
 
-	typeCheckerEnv := typechecker.ProgramEnv(filePath)
+	//write the tree of each file of the entry package to a file in the 'ast' folder next to it
+	for _, file := range program.Entry.Files {
+		var tree ast.Node = file.Tree
+		folder := filepath.Dir(file.Path)
+		fileName := strings.TrimSuffix(filepath.Base(file.Path), filepath.Ext(file.Path))
+		err := helpers.Serialize(&tree, folder, fileName)
+		if err != nil {
+			fmt.Println(errgen.TreeFormatString("compilation halted", "Error serializing AST", err.Error()))
+			os.Exit(-1)
+		}
+	}
+
+	typeCheckerEnv := typechecker.ProgramEnv(entry)
 
-	typechecker.CheckAST(tree, typeCheckerEnv)
+	typechecker.CheckProgram(program, typeCheckerEnv)
 
 	errgen.DisplayAll()
 }